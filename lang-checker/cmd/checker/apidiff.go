@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+
+	"github.com/liujinliang/lang-checker/internal/apidiff"
+	"github.com/liujinliang/lang-checker/internal/source"
+)
+
+// runAPIDiff 执行 api-diff 子命令：对比两个版本（源码目录或JAR包）的Java公开API，
+// 存在破坏性变更时返回1，便于在CI中作为检查门禁
+func runAPIDiff(args []string) int {
+	fs := flag.NewFlagSet("api-diff", flag.ExitOnError)
+	format := fs.String("format", "text", "输出格式: text, json")
+	output := fs.String("output", "", "输出文件路径 (可选)")
+	maxSize := fs.Int64("max-size", source.DefaultMaxSize, "单个源文件或class文件的最大字节数，<=0表示不限制")
+	fs.Usage = func() {
+		fmt.Printf("使用方法:\n  %s api-diff [选项] <旧版本> <新版本>\n\n", os.Args[0])
+		fmt.Println("旧版本和新版本均为Java源码目录或JAR包，两者应为同一种形式")
+		fmt.Println("存在破坏性变更时退出码为1")
+		fmt.Println("\n选项:")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return 2
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	oldAPI, err := apidiff.Load(ctx, fs.Arg(0), *maxSize)
+	if err != nil {
+		fmt.Printf("❌ 读取旧版本失败: %v\n", err)
+		return 1
+	}
+	newAPI, err := apidiff.Load(ctx, fs.Arg(1), *maxSize)
+	if err != nil {
+		fmt.Printf("❌ 读取新版本失败: %v\n", err)
+		return 1
+	}
+	report := apidiff.Compare(oldAPI, newAPI)
+
+	var out io.Writer = os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			fmt.Printf("❌ 创建输出文件失败: %v\n", err)
+			return 1
+		}
+		defer f.Close()
+		out = f
+	}
+
+	switch *format {
+	case "text":
+		fmt.Fprint(out, apidiff.Text(report))
+	case "json":
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(report); err != nil {
+			fmt.Printf("❌ 输出失败: %v\n", err)
+			return 1
+		}
+	default:
+		fmt.Printf("❌ 不支持的输出格式: %s\n", *format)
+		return 1
+	}
+
+	if *output != "" {
+		fmt.Printf("破坏性变更%d项，非破坏性变更%d项，保存到: %s\n", report.Breaking, report.NonBreaking, *output)
+	}
+	if report.Breaking > 0 {
+		return 1
+	}
+	return 0
+}
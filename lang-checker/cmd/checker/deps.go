@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+
+	"github.com/liujinliang/lang-checker/internal/analyzer"
+	"github.com/liujinliang/lang-checker/internal/depgraph"
+	"github.com/liujinliang/lang-checker/pkg/reporter"
+)
+
+// runDeps 执行 deps 子命令：输出Go包依赖图和耦合度指标
+func runDeps(args []string) int {
+	fs := flag.NewFlagSet("deps", flag.ExitOnError)
+	path := fs.String("path", ".", "Go模块内的目录")
+	format := fs.String("format", "text", "输出格式: text, json, dot, mermaid")
+	output := fs.String("output", "", "输出文件路径 (可选)")
+	fs.Usage = func() {
+		fmt.Printf("使用方法:\n  %s deps [选项]\n\n选项:\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	report, err := analyzer.NewCodeAnalyzer().AnalyzeGoDependencies(ctx, *path)
+	if err != nil {
+		fmt.Printf("❌ 分析失败: %v\n", err)
+		return 1
+	}
+
+	var out io.Writer = os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			fmt.Printf("❌ 创建输出文件失败: %v\n", err)
+			return 1
+		}
+		defer f.Close()
+		out = f
+	}
+
+	switch *format {
+	case "dot":
+		fmt.Fprint(out, depgraph.DOT(report))
+	case "mermaid":
+		fmt.Fprint(out, depgraph.Mermaid(report))
+	case "json":
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			fmt.Printf("❌ 输出失败: %v\n", err)
+			return 1
+		}
+	case "text":
+		if *output != "" {
+			oldStdout := os.Stdout
+			os.Stdout = out.(*os.File)
+			reporter.GenerateDependencyReport(report)
+			os.Stdout = oldStdout
+		} else {
+			reporter.GenerateDependencyReport(report)
+		}
+	default:
+		fmt.Printf("❌ 不支持的输出格式: %s\n", *format)
+		return 1
+	}
+
+	if *output != "" {
+		fmt.Printf("报告已保存到: %s\n", *output)
+	}
+	return 0
+}
@@ -1,12 +1,19 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
 
 	"github.com/liujinliang/lang-checker/internal/analyzer"
+	"github.com/liujinliang/lang-checker/internal/decompiler"
 	"github.com/liujinliang/lang-checker/internal/models"
 	"github.com/liujinliang/lang-checker/pkg/reporter"
 )
@@ -15,6 +22,18 @@ var (
 	path        string
 	outputFile  string
 	showVersion bool
+	fileTimeout time.Duration
+	goPackages  bool
+	format      string
+	baseline    string
+	generated   string
+	maxFileSize int64
+	snippetCtx  int
+	genPatterns []*regexp.Regexp
+	decompile   string
+	decompLibs  string
+	decompTime  time.Duration
+	decompilers []decompiler.Decompiler
 	version     = "v1.0.0"
 )
 
@@ -22,16 +41,48 @@ func init() {
 	flag.StringVar(&path, "path", "", "要分析的文件或目录路径")
 	flag.StringVar(&outputFile, "output", "", "报告输出文件路径 (可选)")
 	flag.BoolVar(&showVersion, "version", false, "显示版本信息")
+	flag.DurationVar(&fileTimeout, "timeout", 30*time.Second, "单个文件的分析时限，0表示不限制")
+	flag.Int64Var(&maxFileSize, "max-file-size", 5<<20, "单个文件的最大字节数，超过时跳过，0表示不限制")
+	flag.IntVar(&snippetCtx, "context", 2, "问题代码片段中前后附带的行数，-1表示不显示代码片段")
+	flag.BoolVar(&goPackages, "packages", false, "按包加载Go代码并进行类型检查（需在Go模块内）")
+	flag.StringVar(&format, "format", "text", "输出格式: text, json")
+	flag.StringVar(&baseline, "baseline", "", "基线报告（-format json的输出），用于对比可维护性趋势，只用于text格式 (可选)")
+	flag.StringVar(&generated, "generated", analyzer.GeneratedSeparate, "生成代码的处理方式: separate(单独列出), exclude(跳过), include(正常分析)")
+	flag.Func("generated-pattern", "额外的生成代码识别正则，匹配文件内容，可重复指定", func(s string) error {
+		re, err := regexp.Compile(s)
+		if err != nil {
+			return err
+		}
+		genPatterns = append(genPatterns, re)
+		return nil
+	})
+	flag.StringVar(&decompile, "decompile", "", "分析JAR包时依次尝试的反编译器，逗号分隔，如 cfr,procyon,jd-cli；为空时只分析字节码")
+	flag.StringVar(&decompLibs, "decompiler-libs", "libs", "内置反编译器（CFR、Procyon、jd-cli）JAR包所在目录")
+	flag.DurationVar(&decompTime, "decompile-timeout", 20*time.Second, "每个反编译器处理单个类的时限，0表示不限制")
+	flag.Func("decompiler", "自定义反编译器 名称=命令模板，占位符{input}、{output}、{classpath}、{class}，可重复指定；未指定-decompile时按定义顺序使用", func(s string) error {
+		d, err := decompiler.Parse(s)
+		if err != nil {
+			return err
+		}
+		decompilers = append(decompilers, d)
+		return nil
+	})
 	flag.Usage = usage
 }
 
 func usage() {
 	fmt.Println("AI代码质量检测工具", version)
-	fmt.Println("支持语言: Go, Java")
+	fmt.Println("支持语言: Go, Java（含编译后的.class文件和.jar包）")
 	fmt.Println("功能: 代码质量分析 + AI生成检测")
 	fmt.Println()
 	fmt.Println("使用方法:")
 	fmt.Printf("  %s [选项] -path <文件路径或目录路径>\n", os.Args[0])
+	fmt.Printf("  %s <子命令> [选项]\n", os.Args[0])
+	fmt.Println()
+	fmt.Println("子命令:")
+	fmt.Println("  deps         Go包依赖图与耦合度指标 (text/json/dot/mermaid)")
+	fmt.Println("  sdk-extract  从JAR包提取公开API，输出SDK文档 (markdown/json/schema)")
+	fmt.Println("  api-diff     对比两个版本（源码目录或JAR包）的Java公开API，区分破坏性变更 (text/json)")
 	fmt.Println()
 	fmt.Println("选项:")
 	flag.PrintDefaults()
@@ -40,9 +91,24 @@ func usage() {
 	fmt.Printf("  %s -path main.go\n", os.Args[0])
 	fmt.Printf("  %s -path ./src\n", os.Args[0])
 	fmt.Printf("  %s -path /path/to/java/project -output report.txt\n", os.Args[0])
+	fmt.Printf("  %s -packages -path ./\n", os.Args[0])
+	fmt.Printf("  %s -path ./src -format json -output base.json\n", os.Args[0])
+	fmt.Printf("  %s -path ./src -baseline base.json\n", os.Args[0])
+	fmt.Printf("  %s -path app.jar -decompile procyon,cfr -decompiler-libs ./libs\n", os.Args[0])
 }
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "deps":
+			os.Exit(runDeps(os.Args[2:]))
+		case "sdk-extract":
+			os.Exit(runSDKExtract(os.Args[2:]))
+		case "api-diff":
+			os.Exit(runAPIDiff(os.Args[2:]))
+		}
+	}
+
 	flag.Parse()
 
 	if showVersion {
@@ -58,53 +124,133 @@ func main() {
 	// 规范化路径
 	path = filepath.Clean(path)
 
-	// 创建分析器
-	codeAnalyzer := analyzer.NewCodeAnalyzer()
+	// 趋势对比只在文本报告中输出
+	if baseline != "" && format != "text" {
+		fmt.Printf("❌ -baseline 只能与 -format text 一起使用\n")
+		os.Exit(1)
+	}
 
-	var metrics []*models.QualityMetrics
-	var err error
+	// 创建分析器
+	opts := analyzer.DefaultOptions()
+	opts.FileTimeout = fileTimeout
+	opts.MaxFileSize = maxFileSize
+	opts.SnippetContext = snippetCtx
+	opts.GoPackages = goPackages
+	switch generated {
+	case analyzer.GeneratedSeparate, analyzer.GeneratedExclude, analyzer.GeneratedInclude:
+		opts.Generated = generated
+	default:
+		fmt.Printf("❌ 不支持的生成代码处理方式: %s\n", generated)
+		os.Exit(1)
+	}
+	opts.GeneratedPatterns = genPatterns
+	opts.Decompilers = decompilers
+	if decompile != "" {
+		selected, err := decompiler.Select(strings.Split(decompile, ","), decompLibs, decompilers)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		opts.Decompilers = selected
+	}
+	opts.Decompilers = availableDecompilers(opts.Decompilers)
+	opts.DecompileTimeout = decompTime
+	codeAnalyzer := analyzer.NewCodeAnalyzerWithOptions(opts)
 
 	// 检查路径是否存在
-	fileInfo, err := os.Stat(path)
-	if err != nil {
+	if _, err := os.Stat(path); err != nil {
 		fmt.Printf("❌ 错误: %v\n", err)
 		os.Exit(1)
 	}
 
-	fmt.Printf("🔍 正在分析: %s\n", path)
+	// 进度信息输出到标准错误，标准输出只有报告，-format json 的结果可以直接解析或作为基线
+	fmt.Fprintf(os.Stderr, "🔍 正在分析: %s\n", path)
 
-	// 根据路径类型进行分析
-	if fileInfo.IsDir() {
-		metrics, err = codeAnalyzer.AnalyzeDirectory(path)
-	} else {
-		metric, err := codeAnalyzer.AnalyzeFile(path)
-		if err == nil {
-			metrics = []*models.QualityMetrics{metric}
-		}
-	}
+	// Ctrl+C 取消分析
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
 
+	report, err := codeAnalyzer.Analyze(ctx, path)
 	if err != nil {
 		fmt.Printf("❌ 分析失败: %v\n", err)
 		os.Exit(1)
 	}
 
-	fmt.Printf("✅ 分析完成，共处理 %d 个文件\n\n", len(metrics))
+	fmt.Fprintf(os.Stderr, "✅ 分析完成，共处理 %d 个文件\n\n", len(report.Files))
+
+	var base *models.Report
+	if baseline != "" {
+		if base, err = loadReport(baseline); err != nil {
+			fmt.Printf("❌ 读取基线报告失败: %v\n", err)
+			os.Exit(1)
+		}
+	}
 
 	// 生成报告
+	out := os.Stdout
 	if outputFile != "" {
-		// 将标准输出重定向到文件
 		f, err := os.Create(outputFile)
 		if err != nil {
 			fmt.Printf("❌ 创建报告文件失败: %v\n", err)
 			os.Exit(1)
 		}
 		defer f.Close()
+		out = f
+	}
+
+	switch format {
+	case "json":
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(report); err != nil {
+			fmt.Printf("❌ 输出失败: %v\n", err)
+			os.Exit(1)
+		}
+	case "text":
+		// 将标准输出重定向到报告文件
 		oldStdout := os.Stdout
-		os.Stdout = f
-		reporter.GenerateReport(metrics)
+		os.Stdout = out
+		reporter.GenerateReport(report)
+		if base != nil {
+			reporter.GenerateTrendReport(report, base)
+		}
 		os.Stdout = oldStdout
+	default:
+		fmt.Printf("❌ 不支持的输出格式: %s\n", format)
+		os.Exit(1)
+	}
+
+	if outputFile != "" {
 		fmt.Printf("报告已保存到: %s\n", outputFile)
-	} else {
-		reporter.GenerateReport(metrics)
 	}
 }
+
+// loadReport 读取 -format json 输出的报告
+func loadReport(path string) (*models.Report, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var report models.Report
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// availableDecompilers 去掉无法执行的反编译器并给出提示，全部不可用时JAR包只分析字节码
+func availableDecompilers(decompilers []decompiler.Decompiler) []decompiler.Decompiler {
+	var available []decompiler.Decompiler
+	for _, d := range decompilers {
+		if err := d.Check(); err != nil {
+			fmt.Fprintf(os.Stderr, "⚠️  反编译器 %s 不可用: %v\n", d.Name, err)
+			continue
+		}
+		available = append(available, d)
+	}
+	if len(decompilers) > 0 && len(available) == 0 {
+		fmt.Fprintln(os.Stderr, "⚠️  没有可用的反编译器，JAR包只分析字节码")
+	}
+	return available
+}
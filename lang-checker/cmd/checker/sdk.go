@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+
+	"github.com/liujinliang/lang-checker/internal/sdk"
+	"github.com/liujinliang/lang-checker/internal/source"
+)
+
+// runSDKExtract 执行 sdk-extract 子命令：从JAR包的字节码中提取公开API
+func runSDKExtract(args []string) int {
+	fs := flag.NewFlagSet("sdk-extract", flag.ExitOnError)
+	path := fs.String("path", "", "JAR包路径")
+	format := fs.String("format", "markdown", "输出格式: markdown, json, schema（数据对象的JSON Schema）")
+	output := fs.String("output", "", "输出文件路径 (可选)")
+	maxSize := fs.Int64("max-size", source.DefaultMaxSize, "单个class文件的最大字节数，<=0表示不限制")
+	fs.Usage = func() {
+		fmt.Printf("使用方法:\n  %s sdk-extract -path <JAR包> [选项]\n\n选项:\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if *path == "" {
+		fs.Usage()
+		return 2
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	api, err := sdk.Extract(ctx, *path, *maxSize)
+	if err != nil {
+		fmt.Printf("❌ 提取失败: %v\n", err)
+		return 1
+	}
+
+	var out io.Writer = os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			fmt.Printf("❌ 创建输出文件失败: %v\n", err)
+			return 1
+		}
+		defer f.Close()
+		out = f
+	}
+
+	switch *format {
+	case "markdown", "md":
+		fmt.Fprint(out, sdk.Markdown(api))
+	case "json", "schema":
+		var v any = api
+		if *format == "schema" {
+			v = sdk.JSONSchema(api)
+		}
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		// 泛型签名中有大量尖括号，不做HTML转义
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(v); err != nil {
+			fmt.Printf("❌ 输出失败: %v\n", err)
+			return 1
+		}
+	default:
+		fmt.Printf("❌ 不支持的输出格式: %s\n", *format)
+		return 1
+	}
+
+	if *output != "" {
+		fmt.Printf("已提取%d个包的公开API，保存到: %s\n", len(api.Packages), *output)
+	}
+	return 0
+}
@@ -1,84 +1,452 @@
 package analyzer
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"io/fs"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
+	"time"
 
+	"github.com/liujinliang/lang-checker/internal/classfile"
+	"github.com/liujinliang/lang-checker/internal/decompiler"
 	"github.com/liujinliang/lang-checker/internal/detector"
+	"github.com/liujinliang/lang-checker/internal/generated"
 	"github.com/liujinliang/lang-checker/internal/models"
+	"github.com/liujinliang/lang-checker/internal/rules"
+	"github.com/liujinliang/lang-checker/internal/source"
 )
 
+// Options 分析选项
+type Options struct {
+	// Thresholds 规则阈值，未设置的项使用默认值
+	Thresholds rules.Thresholds
+	// EnabledRules 启用的规则ID，为空表示启用全部内置规则
+	EnabledRules []string
+	// DisabledRules 禁用的规则ID
+	DisabledRules []string
+	// Languages 需要分析的语言，为空表示全部支持的语言
+	Languages []models.Language
+	// FileTimeout 单个文件的分析时限，<=0表示不限制
+	FileTimeout time.Duration
+	// MaxFileSize 单个文件的最大字节数，超过时跳过并记录诊断信息，<=0表示不限制
+	MaxFileSize int64
+	// SnippetContext 问题代码片段中问题范围前后附带的行数，<0表示不生成代码片段
+	SnippetContext int
+	// Progress 目录分析时每完成一个文件回调一次，可为nil
+	Progress func(path string, done, total int)
+	// GoPackages 目录分析时按包加载Go代码并进行类型检查，使规则可以使用类型信息
+	GoPackages bool
+	// Generated 生成代码的处理方式，为空时按GeneratedSeparate处理
+	Generated string
+	// GeneratedPatterns 额外的生成代码识别模式，匹配文件内容
+	GeneratedPatterns []*regexp.Regexp
+	// Decompilers 分析JAR包时依次尝试的反编译器，为空表示只分析字节码。反编译出的源码按Java源码
+	// 检查规则、做AI检测和评分，所有反编译器都失败的类回退为字节码分析
+	Decompilers []decompiler.Decompiler
+	// DecompileTimeout 每个反编译器处理单个类的时限，<=0表示不限制
+	DecompileTimeout time.Duration
+
+	// TestThresholds 测试代码的阈值，未设置的项使用rules.DefaultTestThresholds
+	TestThresholds rules.Thresholds
+	// TestEnabledRules 测试代码启用的规则ID，为空时与EnabledRules相同
+	TestEnabledRules []string
+	// TestDisabledRules 测试代码额外禁用的规则ID
+	TestDisabledRules []string
+}
+
+// 生成代码的处理方式
+const (
+	// GeneratedSeparate 计算指标但不检查规则、不做AI检测和评分，在报告中单独列出
+	GeneratedSeparate = "separate"
+	// GeneratedExclude 跳过生成代码，记录为诊断信息
+	GeneratedExclude = "exclude"
+	// GeneratedInclude 与手写代码同等对待
+	GeneratedInclude = "include"
+)
+
+// DefaultOptions 返回默认分析选项
+func DefaultOptions() Options {
+	return Options{
+		Thresholds:     rules.DefaultThresholds(),
+		TestThresholds: rules.DefaultTestThresholds(),
+		FileTimeout:    30 * time.Second,
+		MaxFileSize:    source.DefaultMaxSize,
+		SnippetContext: rules.DefaultSnippetContext,
+		Generated:      GeneratedSeparate,
+	}
+}
+
+// ErrFileTimeout 单个文件分析超时
+var ErrFileTimeout = errors.New("文件分析超时")
+
+// ErrGeneratedCode 文件为生成代码且按GeneratedExclude跳过
+var ErrGeneratedCode = errors.New("生成代码已跳过")
+
+// ErrLanguageDisabled 文件的语言不在Options.Languages中
+var ErrLanguageDisabled = errors.New("语言未启用")
+
 // CodeAnalyzer 代码分析器
 type CodeAnalyzer struct {
 	goAnalyzer   *GoAnalyzer
 	javaAnalyzer *JavaAnalyzer
-	aiDetector   *detector.AIDetector
+	// 测试代码使用单独阈值和规则集的分析器
+	testGoAnalyzer   *GoAnalyzer
+	testJavaAnalyzer *JavaAnalyzer
+	aiDetector       *detector.AIDetector
+	generated        *generated.Detector
+	engine           *rules.Engine
+	options          Options
 }
 
 // NewCodeAnalyzer 创建新的代码分析器
 func NewCodeAnalyzer() *CodeAnalyzer {
+	return NewCodeAnalyzerWithOptions(DefaultOptions())
+}
+
+// NewCodeAnalyzerWithOptions 使用指定选项创建代码分析器
+func NewCodeAnalyzerWithOptions(opts Options) *CodeAnalyzer {
+	opts.Thresholds = opts.Thresholds.WithDefaults()
+	opts.TestThresholds = opts.TestThresholds.WithTestDefaults()
+	engine := rules.NewEngine(rules.Filter(rules.BuiltinRules(opts.Thresholds), opts.EnabledRules, opts.DisabledRules)...)
+	engine.SetSnippetContext(opts.SnippetContext)
+
+	testEnabled := opts.TestEnabledRules
+	if len(testEnabled) == 0 {
+		testEnabled = opts.EnabledRules
+	}
+	testDisabled := append(append([]string(nil), opts.DisabledRules...), opts.TestDisabledRules...)
+	testEngine := rules.NewEngine(rules.Filter(rules.BuiltinRules(opts.TestThresholds), testEnabled, testDisabled)...)
+	testEngine.SetSnippetContext(opts.SnippetContext)
+	testGoAnalyzer, testJavaAnalyzer := NewGoAnalyzer(testEngine, opts.TestThresholds), NewJavaAnalyzer(testEngine, opts.TestThresholds)
+	testGoAnalyzer.test, testJavaAnalyzer.test = true, true
+	javaAnalyzer := NewJavaAnalyzer(engine, opts.Thresholds)
+	javaAnalyzer.generated = opts.Generated != GeneratedInclude
+	testJavaAnalyzer.generated = javaAnalyzer.generated
+
 	return &CodeAnalyzer{
-		goAnalyzer:   NewGoAnalyzer(),
-		javaAnalyzer: NewJavaAnalyzer(),
-		aiDetector:   detector.NewAIDetector(),
+		goAnalyzer:       NewGoAnalyzer(engine, opts.Thresholds),
+		javaAnalyzer:     javaAnalyzer,
+		testGoAnalyzer:   testGoAnalyzer,
+		testJavaAnalyzer: testJavaAnalyzer,
+		aiDetector:       detector.NewAIDetector(),
+		generated:        generated.NewDetector(opts.GeneratedPatterns),
+		engine:           engine,
+		options:          opts,
 	}
 }
 
-// AnalyzeFile 分析单个文件
-func (ca *CodeAnalyzer) AnalyzeFile(filePath string) (*models.QualityMetrics, error) {
-	content, err := os.ReadFile(filePath)
+// Rules 返回当前启用的规则
+func (ca *CodeAnalyzer) Rules() []rules.Rule {
+	return ca.engine.Rules()
+}
+
+// Analyze 分析文件或目录，超时的文件记录为诊断信息而不中断分析
+func (ca *CodeAnalyzer) Analyze(ctx context.Context, path string) (*models.Report, error) {
+	info, err := os.Stat(path)
 	if err != nil {
 		return nil, err
 	}
+	if info.IsDir() {
+		return ca.AnalyzeDirectory(ctx, path)
+	}
+	report, err := ca.analyzeFiles(ctx, []string{path})
+	if err != nil {
+		return report, err
+	}
+	linkJavaClasses(report)
+	return report, ca.detectDuplicates(ctx, report)
+}
 
-	contentStr := string(content)
+// AnalyzeFile 分析单个文件，受FileTimeout和MaxFileSize限制。
+// class文件按字节码分析；JAR包包含多个class文件，需使用Analyze或AnalyzeJar
+func (ca *CodeAnalyzer) AnalyzeFile(ctx context.Context, filePath string) (*models.QualityMetrics, error) {
+	if err := ca.checkLanguage(filePath); err != nil {
+		return nil, err
+	}
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".class":
+		return ca.withFileTimeout(ctx, func(context.Context) (*models.QualityMetrics, error) {
+			return ca.analyzeClassFile(filePath)
+		})
+	case ".jar":
+		return nil, fmt.Errorf("JAR包包含多个class文件，请使用Analyze或AnalyzeJar: %s", filePath)
+	}
+	content, err := source.ReadBytes(filePath, ca.options.MaxFileSize)
+	if err != nil {
+		return nil, err
+	}
+	return ca.AnalyzeSource(ctx, filePath, content)
+}
+
+// AnalyzeSource 分析内存中的源码，filePath用于识别语言和标注问题位置，受FileTimeout限制。
+// 内容会按BOM、UTF-16或GBK/GB18030识别编码并转为UTF-8，二进制内容返回source.ErrBinary，
+// 语言未启用时返回ErrLanguageDisabled
+func (ca *CodeAnalyzer) AnalyzeSource(ctx context.Context, filePath string, content []byte) (*models.QualityMetrics, error) {
+	if err := ca.checkLanguage(filePath); err != nil {
+		return nil, err
+	}
+	return ca.withFileTimeout(ctx, func(ctx context.Context) (*models.QualityMetrics, error) {
+		return ca.analyzeSource(ctx, filePath, content)
+	})
+}
+
+// withFileTimeout 在FileTimeout限制内执行单个文件的分析。分析在当前goroutine中进行，
+// 解析、指标计算、规则和AI检测都会定期检查ctx，到期后停止计算而不是在后台继续运行
+func (ca *CodeAnalyzer) withFileTimeout(ctx context.Context, analyze func(context.Context) (*models.QualityMetrics, error)) (*models.QualityMetrics, error) {
+	if ca.options.FileTimeout <= 0 {
+		return analyze(ctx)
+	}
+
+	fileCtx, cancel := context.WithTimeout(ctx, ca.options.FileTimeout)
+	defer cancel()
+
+	metrics, err := analyze(fileCtx)
+	if err != nil && ctx.Err() == nil && errors.Is(err, context.DeadlineExceeded) {
+		return nil, ErrFileTimeout
+	}
+	return metrics, err
+}
+
+func (ca *CodeAnalyzer) analyzeSource(ctx context.Context, filePath string, content []byte) (*models.QualityMetrics, error) {
+	contentStr, encoding, err := source.Decode(content)
+	if err != nil {
+		return nil, err
+	}
+	reason, err := ca.checkGenerated(filePath, []byte(contentStr))
+	if err != nil {
+		return nil, err
+	}
 	var metrics *models.QualityMetrics
 	var analyzeErr error
 
-	switch detectLanguage(filePath) {
+	goAnalyzer, javaAnalyzer := ca.goAnalyzer, ca.javaAnalyzer
+	test := isTestFile(filePath)
+	if test {
+		goAnalyzer, javaAnalyzer = ca.testGoAnalyzer, ca.testJavaAnalyzer
+	}
+
+	switch lang := detectLanguage(filePath); lang {
 	case models.Go:
-		metrics, analyzeErr = ca.goAnalyzer.Analyze(contentStr, filePath)
+		metrics, analyzeErr = goAnalyzer.Analyze(ctx, contentStr, filePath)
 	case models.Java:
-		metrics, analyzeErr = ca.javaAnalyzer.Analyze(contentStr, filePath)
+		metrics, analyzeErr = javaAnalyzer.Analyze(ctx, contentStr, filePath)
+	default:
+		return nil, fmt.Errorf("不支持的语言: %s", filePath)
 	}
 
 	if analyzeErr != nil {
 		return nil, analyzeErr
 	}
+	metrics.Test = test
+	if encoding != source.UTF8 {
+		metrics.Encoding = encoding
+	}
+	markGenerated(metrics, reason)
+	return ca.finishMetrics(ctx, metrics, contentStr)
+}
+
+// checkGenerated 识别生成代码并返回原因，按GeneratedExclude处理时返回ErrGeneratedCode
+func (ca *CodeAnalyzer) checkGenerated(filePath string, content []byte) (string, error) {
+	if ca.options.Generated == GeneratedInclude {
+		return "", nil
+	}
+	reason, ok := ca.generated.Detect(filePath, content)
+	if !ok {
+		return "", nil
+	}
+	if ca.options.Generated == GeneratedExclude {
+		return reason, fmt.Errorf("%w: %s", ErrGeneratedCode, reason)
+	}
+	return reason, nil
+}
+
+// markGenerated 将文件标记为生成代码，生成代码的规则问题不具备可操作性，直接丢弃
+func markGenerated(metrics *models.QualityMetrics, reason string) {
+	if reason == "" {
+		return
+	}
+	metrics.Generated = true
+	metrics.GeneratedReason = reason
+	metrics.Issues = nil
+}
+
+// finishMetrics 保留源码，补充AI检测结果并计算质量得分，生成代码不参与
+func (ca *CodeAnalyzer) finishMetrics(ctx context.Context, metrics *models.QualityMetrics, content string) (*models.QualityMetrics, error) {
+	if metrics.Generated {
+		return metrics, nil
+	}
+	metrics.Content = content
 
 	// AI检测
-	aiResult := ca.aiDetector.DetectAI(contentStr)
+	aiResult, err := ca.aiDetector.DetectAI(ctx, content)
+	if err != nil {
+		return nil, err
+	}
 	metrics.AIGeneratedScore = aiResult.Score
 	metrics.AIIndicators = aiResult.Indicators
 
 	// 计算质量得分
-	metrics.Score = calculateQualityScore(metrics)
+	metrics.Score = calculateQualityScore(metrics, ca.thresholdsFor(metrics))
 
 	return metrics, nil
 }
 
-// AnalyzeDirectory 分析目录
-func (ca *CodeAnalyzer) AnalyzeDirectory(dirPath string) ([]*models.QualityMetrics, error) {
-	var allMetrics []*models.QualityMetrics
+// thresholdsFor 返回文件适用的阈值，测试代码使用TestThresholds
+func (ca *CodeAnalyzer) thresholdsFor(metrics *models.QualityMetrics) rules.Thresholds {
+	if metrics.Test {
+		return ca.options.TestThresholds
+	}
+	return ca.options.Thresholds
+}
+
+// AnalyzeDirectory 分析目录，计算Java类的继承指标，检测目录内跨文件的重复代码并统计测试代码指标
+func (ca *CodeAnalyzer) AnalyzeDirectory(ctx context.Context, dirPath string) (*models.Report, error) {
+	report, err := ca.analyzeDirectory(ctx, dirPath)
+	if err != nil {
+		return report, err
+	}
+	linkJavaClasses(report)
+	if err := ca.detectDuplicates(ctx, report); err != nil {
+		return report, err
+	}
+	return report, ca.analyzeTests(ctx, report)
+}
+
+func (ca *CodeAnalyzer) analyzeDirectory(ctx context.Context, dirPath string) (*models.Report, error) {
+	files, err := ca.CollectFiles(dirPath)
+	if err != nil {
+		return nil, err
+	}
+	if !ca.options.GoPackages || !ca.languageEnabled(models.Go) {
+		return ca.analyzeFiles(ctx, files)
+	}
+
+	// 包模式：Go文件按包加载并类型检查，其他语言和Go测试文件逐个文件分析
+	var others []string
+	for _, f := range files {
+		if detectLanguage(f) != models.Go || isTestFile(f) {
+			others = append(others, f)
+		}
+	}
+	report, err := ca.analyzeFiles(ctx, others)
+	if err != nil {
+		return report, err
+	}
+	goReport, err := ca.AnalyzeGoPackages(ctx, dirPath)
+	if goReport != nil {
+		report.Files = append(report.Files, goReport.Files...)
+		report.Diagnostics = append(report.Diagnostics, goReport.Diagnostics...)
+	}
+	return report, err
+}
+
+func (ca *CodeAnalyzer) analyzeFiles(ctx context.Context, files []string) (*models.Report, error) {
+	report := &models.Report{}
+	for i, path := range files {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
 
+		if isJar(path) {
+			if err := ca.analyzeJar(ctx, report, path); err != nil {
+				return report, err
+			}
+		} else {
+			metrics, err := ca.AnalyzeFile(ctx, path)
+			if err := ca.collect(report, path, metrics, err); err != nil {
+				return report, err
+			}
+		}
+
+		if ca.options.Progress != nil {
+			ca.options.Progress(path, i+1, len(files))
+		}
+	}
+	return report, nil
+}
+
+// collect 将单个文件的分析结果并入报告，超时、被跳过的生成代码、二进制和超大文件、语法错误以及无法解析的class文件记录为诊断信息，其他错误原样返回
+func (ca *CodeAnalyzer) collect(report *models.Report, path string, metrics *models.QualityMetrics, err error) error {
+	switch {
+	case errors.Is(err, ErrFileTimeout):
+		report.Diagnostics = append(report.Diagnostics, models.Diagnostic{
+			FilePath: path,
+			Kind:     models.DiagnosticTimeout,
+			Message:  fmt.Sprintf("分析超过时限%s，已跳过", ca.options.FileTimeout),
+		})
+	case errors.Is(err, ErrGeneratedCode):
+		report.Diagnostics = append(report.Diagnostics, models.Diagnostic{
+			FilePath: path,
+			Kind:     models.DiagnosticGenerated,
+			Message:  err.Error(),
+		})
+	case errors.Is(err, source.ErrBinary):
+		report.Diagnostics = append(report.Diagnostics, models.Diagnostic{
+			FilePath: path,
+			Kind:     models.DiagnosticBinary,
+			Message:  "文件内容为二进制，已跳过",
+		})
+	case errors.Is(err, source.ErrTooLarge):
+		report.Diagnostics = append(report.Diagnostics, models.Diagnostic{
+			FilePath: path,
+			Kind:     models.DiagnosticTooLarge,
+			Message:  err.Error() + "，已跳过",
+		})
+	case errors.Is(err, classfile.ErrFormat), errors.Is(err, classfile.ErrTruncated):
+		report.Diagnostics = append(report.Diagnostics, classFileDiagnostic(path, err))
+	case err != nil:
+		return err
+	default:
+		if n := len(metrics.SyntaxErrors); n > 0 {
+			report.Diagnostics = append(report.Diagnostics, models.Diagnostic{
+				FilePath: path,
+				Kind:     models.DiagnosticSyntax,
+				Message:  fmt.Sprintf("存在%d处语法错误，已跳过出错的代码，相关指标可能不完整，首个错误: %s", n, metrics.SyntaxErrors[0]),
+			})
+		}
+		report.Files = append(report.Files, metrics)
+	}
+	return nil
+}
+
+// CollectFiles 收集目录下需要分析的文件
+func (ca *CodeAnalyzer) CollectFiles(dirPath string) ([]string, error) {
+	var files []string
 	err := filepath.WalkDir(dirPath, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
 
-		if !d.IsDir() && isTargetFile(path) {
-			metrics, err := ca.AnalyzeFile(path)
-			if err != nil {
-				return err
-			}
-			allMetrics = append(allMetrics, metrics)
+		if !d.IsDir() && isTargetFile(path) && ca.languageEnabled(detectLanguage(path)) {
+			files = append(files, path)
 		}
 		return nil
 	})
+	return files, err
+}
+
+// checkLanguage 文件属于未启用的语言时返回ErrLanguageDisabled，无法识别的语言留给后续分析报错
+func (ca *CodeAnalyzer) checkLanguage(filePath string) error {
+	if lang := detectLanguage(filePath); lang != "" && !ca.languageEnabled(lang) {
+		return fmt.Errorf("%w: %s", ErrLanguageDisabled, filePath)
+	}
+	return nil
+}
 
-	return allMetrics, err
+func (ca *CodeAnalyzer) languageEnabled(lang models.Language) bool {
+	if len(ca.options.Languages) == 0 {
+		return true
+	}
+	for _, l := range ca.options.Languages {
+		if l == lang {
+			return true
+		}
+	}
+	return false
 }
 
 // 辅助函数
@@ -87,19 +455,28 @@ func detectLanguage(filePath string) models.Language {
 	switch ext {
 	case ".go":
 		return models.Go
-	case ".java":
+	case ".java", ".class", ".jar":
 		return models.Java
 	default:
-		return models.Go
+		return ""
 	}
 }
 
 func isTargetFile(path string) bool {
-	return (strings.HasSuffix(path, ".go") || strings.HasSuffix(path, ".java")) &&
-		!strings.Contains(path, "vendor/") && !strings.Contains(path, "target/")
+	return detectLanguage(path) != "" && !strings.Contains(path, "vendor/") && !strings.Contains(path, "target/")
+}
+
+func isJar(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".jar")
 }
 
-func calculateQualityScore(metrics *models.QualityMetrics) float64 {
+// 可维护性指数分级：>=85易维护，65~85中等，<65难维护
+const (
+	miHighlyMaintainable = 85
+	miModerate           = 65
+)
+
+func calculateQualityScore(metrics *models.QualityMetrics, th rules.Thresholds) float64 {
 	score := 100.0
 
 	// 基础扣分规则
@@ -113,12 +490,20 @@ func calculateQualityScore(metrics *models.QualityMetrics) float64 {
 	if metrics.LongFunctions > 0 {
 		score -= float64(metrics.LongFunctions) * 8
 	}
-	if metrics.DeepNesting > 4 {
-		score -= float64(metrics.DeepNesting-4) * 3
+	if metrics.DeepNesting > th.NestingDepth {
+		score -= float64(metrics.DeepNesting-th.NestingDepth) * 3
 	}
 	if metrics.DuplicateLines > 10 {
 		score -= float64(metrics.DuplicateLines) * 0.5
 	}
+	if metrics.CodeLines > 0 {
+		switch {
+		case metrics.MaintainabilityIndex < miModerate:
+			score -= 10
+		case metrics.MaintainabilityIndex < miHighlyMaintainable:
+			score -= 5
+		}
+	}
 
 	// AI生成代码扣分
 	if metrics.AIGeneratedScore > 70 {
@@ -0,0 +1,82 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// largeJavaSource 生成包含n个方法的Java类
+func largeJavaSource(n int) string {
+	var b strings.Builder
+	b.WriteString("package com.example;\n\npublic class Big {\n")
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&b, "    public int m%d(int x) {\n        if (x > %d) {\n            return x - %d;\n        }\n        return x;\n    }\n", i, i, i)
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+func TestAnalyzeSourceTimeout(t *testing.T) {
+	opts := DefaultOptions()
+	opts.FileTimeout = time.Millisecond
+	ca := NewCodeAnalyzerWithOptions(opts)
+	src := []byte(largeJavaSource(5000))
+
+	before := runtime.NumGoroutine()
+	_, err := ca.AnalyzeSource(context.Background(), "Big.java", src)
+	if !errors.Is(err, ErrFileTimeout) {
+		t.Fatalf("err = %v, want ErrFileTimeout", err)
+	}
+	// 超时后分析应已停止，不在后台继续运行
+	if after := runtime.NumGoroutine(); after > before {
+		t.Errorf("超时后仍有%d个goroutine在运行", after-before)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := ca.AnalyzeSource(ctx, "Big.java", src); !errors.Is(err, context.Canceled) {
+		t.Errorf("取消时 err = %v, want context.Canceled", err)
+	}
+}
+
+func TestAnalyzeSourceGeneratedMember(t *testing.T) {
+	src := `package com.example;
+
+public class User {
+    private String name;
+
+    @lombok.Generated
+    public String getName() {
+        System.out.println("generated");
+        return name;
+    }
+
+    public void greet() {
+        System.out.println("hello " + name);
+    }
+}
+`
+	ca := NewCodeAnalyzer()
+	metrics, err := ca.AnalyzeSource(context.Background(), "User.java", []byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if metrics.Generated {
+		t.Fatalf("成员上的注解不应使整个文件视为生成代码: %s", metrics.GeneratedReason)
+	}
+	var lines []int
+	for _, issue := range metrics.Issues {
+		if issue.RuleID == "java/system-out" {
+			lines = append(lines, issue.Line)
+		}
+	}
+	// 只保留手写方法greet中的问题
+	if len(lines) != 1 || lines[0] != 13 {
+		t.Errorf("java/system-out lines = %v, want [13]", lines)
+	}
+}
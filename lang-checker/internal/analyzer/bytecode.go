@@ -0,0 +1,319 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/liujinliang/lang-checker/internal/classfile"
+	"github.com/liujinliang/lang-checker/internal/complexity"
+	"github.com/liujinliang/lang-checker/internal/models"
+	"github.com/liujinliang/lang-checker/internal/rules"
+	"github.com/liujinliang/lang-checker/internal/source"
+)
+
+// maxAccessorBytecode getter/setter的方法体上限：aload_0, getfield, areturn等不超过8字节
+const maxAccessorBytecode = 8
+
+// AnalyzeJar 分析JAR包中的class文件，每个class文件作为报告中的一个文件，路径为 JAR路径!/包内路径。
+// 单个class文件受MaxFileSize限制，超限或无法解析时记录为诊断信息；整个JAR包受FileTimeout限制。
+// 配置了Decompilers时改为反编译后按源码分析，见decompileJar
+func (ca *CodeAnalyzer) AnalyzeJar(ctx context.Context, path string) (*models.Report, error) {
+	if len(ca.options.Decompilers) > 0 {
+		return ca.decompileJar(ctx, path)
+	}
+	if ca.options.FileTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, ca.options.FileTimeout)
+		defer cancel()
+	}
+
+	report := &models.Report{}
+	err := classfile.WalkJar(ctx, path, ca.options.MaxFileSize, func(e classfile.Entry) error {
+		entryPath := path + "!/" + e.Name
+		if e.Err != nil {
+			report.Diagnostics = append(report.Diagnostics, classFileDiagnostic(entryPath, e.Err))
+			return nil
+		}
+		if !e.Class.Is(classfile.AccModule) {
+			report.Files = append(report.Files, bytecodeMetrics(e.Class, entryPath, ca.options.Thresholds))
+		}
+		return nil
+	})
+	if errors.Is(err, context.DeadlineExceeded) && ctx.Err() != nil && ca.options.FileTimeout > 0 {
+		return report, ErrFileTimeout
+	}
+	return report, err
+}
+
+// analyzeClassFile 分析单个class文件
+func (ca *CodeAnalyzer) analyzeClassFile(filePath string) (*models.QualityMetrics, error) {
+	data, err := source.ReadBytes(filePath, ca.options.MaxFileSize)
+	if err != nil {
+		return nil, err
+	}
+	class, err := classfile.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	return bytecodeMetrics(class, filePath, ca.options.Thresholds), nil
+}
+
+// analyzeJar 分析JAR包并将结果并入报告。超时时保留已分析的class文件；
+// JAR包无法打开（如文件损坏）时记录为诊断信息，不中断整个目录的分析
+func (ca *CodeAnalyzer) analyzeJar(ctx context.Context, report *models.Report, path string) error {
+	jar, err := ca.AnalyzeJar(ctx, path)
+	report.Files = append(report.Files, jar.Files...)
+	report.Diagnostics = append(report.Diagnostics, jar.Diagnostics...)
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, ErrFileTimeout):
+		report.Diagnostics = append(report.Diagnostics, models.Diagnostic{
+			FilePath: path,
+			Kind:     models.DiagnosticTimeout,
+			Message:  fmt.Sprintf("分析超过时限%s，只包含前%d个class文件", ca.options.FileTimeout, len(jar.Files)),
+		})
+		return nil
+	case ctx.Err() != nil:
+		return err
+	}
+	report.Diagnostics = append(report.Diagnostics, classFileDiagnostic(path, err))
+	return nil
+}
+
+func classFileDiagnostic(path string, err error) models.Diagnostic {
+	if errors.Is(err, classfile.ErrEntryTooLarge) {
+		return models.Diagnostic{FilePath: path, Kind: models.DiagnosticTooLarge, Message: err.Error() + "，已跳过"}
+	}
+	return models.Diagnostic{FilePath: path, Kind: models.DiagnosticClassFile, Message: "无法解析: " + err.Error()}
+}
+
+// bytecodeMetrics 根据class文件计算方法和类指标。方法的圈复杂度按字节码中的分支估算，
+// 编译器生成的桥接方法、合成方法（如lambda体）、静态初始化块以及枚举的values/valueOf不计入
+func bytecodeMetrics(class *classfile.ClassFile, filePath string, th rules.Thresholds) *models.QualityMetrics {
+	metrics := &models.QualityMetrics{
+		FilePath: filePath,
+		Language: models.Java,
+		Package:  classfile.JavaName(class.Package()),
+		Bytecode: true,
+	}
+	name := bytecodeClassName(class)
+	simple := name[strings.LastIndexAny(name, ".$")+1:]
+
+	cm := models.ClassMetrics{
+		Name:       name,
+		Kind:       class.Kind(),
+		Superclass: bytecodeSuperclass(class),
+	}
+	for _, iface := range class.Interfaces {
+		if !(class.Is(classfile.AccAnnotation) && iface == "java/lang/annotation/Annotation") {
+			cm.Interfaces = append(cm.Interfaces, classfile.JavaName(iface))
+		}
+	}
+	for _, f := range class.Fields {
+		if !f.Is(classfile.AccSynthetic) && !f.Is(classfile.AccEnum) {
+			cm.Fields++
+		}
+	}
+
+	var methods []*classfile.Member
+	complexitySum := 0
+	for _, m := range class.Methods {
+		if !isSourceMethod(class, m) {
+			continue
+		}
+		methods = append(methods, m)
+		fm := bytecodeFunction(class, m)
+		if fm.Name == "<init>" {
+			fm.Name = simple
+		}
+		fm.Receiver = name
+		if fm.Lines > th.FunctionLength {
+			metrics.LongFunctions++
+		}
+		complexitySum += fm.CyclomaticComplexity
+		metrics.Functions = append(metrics.Functions, fm)
+
+		if cm.StartLine == 0 || (fm.StartLine > 0 && fm.StartLine < cm.StartLine) {
+			cm.StartLine = fm.StartLine
+		}
+		cm.EndLine = max(cm.EndLine, fm.EndLine)
+	}
+	metrics.FunctionCount = len(metrics.Functions)
+	metrics.CyclomaticComplexity = complexitySum - len(methods) + 1
+
+	cm.Methods = len(methods)
+	cm.WMC = complexitySum
+	for _, dep := range class.Dependencies() {
+		if !isJavaLangInternal(dep) {
+			cm.Dependencies = append(cm.Dependencies, classfile.JavaName(dep))
+		}
+	}
+	cm.CBO = len(cm.Dependencies)
+	cm.RFC = cm.Methods + bytecodeCalledMethods(class, methods)
+	cm.LCOM = bytecodeLCOM(class, methods)
+	// 匿名类不是具名类型，方法照常统计，但不单独给出类指标
+	if !isAnonymousClass(class) {
+		metrics.Classes = append(metrics.Classes, cm)
+	}
+	return metrics
+}
+
+// bytecodeFunction 计算单个方法的指标，行号来自LineNumberTable，编译时去掉调试信息的class文件没有行号
+func bytecodeFunction(class *classfile.ClassFile, m *classfile.Member) models.FunctionMetrics {
+	fm := models.FunctionMetrics{
+		Name:                 m.Name,
+		CyclomaticComplexity: 1,
+		Exported:             m.Is(classfile.AccPublic) && class.Is(classfile.AccPublic),
+	}
+	if mt, err := classfile.ParseMethodSignature(m.Descriptor); err == nil {
+		fm.Parameters = len(mt.Params)
+	}
+	if m.Code != nil {
+		fm.BytecodeSize = len(m.Code.Bytecode)
+		if cc, err := m.Code.Complexity(); err == nil {
+			fm.CyclomaticComplexity = cc
+		}
+		fm.StartLine, fm.EndLine = m.Code.LineRange()
+		if fm.StartLine > 0 {
+			fm.Lines = fm.EndLine - fm.StartLine + 1
+		}
+	}
+	return fm
+}
+
+// isSourceMethod 判断方法是否在源码中声明（含构造器）
+func isSourceMethod(class *classfile.ClassFile, m *classfile.Member) bool {
+	if m.Is(classfile.AccSynthetic) || m.Is(classfile.AccBridge) || m.Name == "<clinit>" {
+		return false
+	}
+	if class.Is(classfile.AccEnum) && m.Is(classfile.AccStatic) {
+		self := "L" + class.Name + ";"
+		if (m.Name == "values" && m.Descriptor == "()["+self) ||
+			(m.Name == "valueOf" && m.Descriptor == "(Ljava/lang/String;)"+self) {
+			return false
+		}
+	}
+	return true
+}
+
+// bytecodeClassName 返回去掉包名的类名，内部类为 Outer.Inner，匿名类和局部类保留编号（如 Outer$1）
+func bytecodeClassName(class *classfile.ClassFile) string {
+	name := class.Name
+	if pkg := class.Package(); pkg != "" {
+		name = name[len(pkg)+1:]
+	}
+	return classfile.JavaName(name)
+}
+
+// bytecodeSuperclass 返回父类的全限定名，Object以及枚举和record的隐式父类省略
+func bytecodeSuperclass(class *classfile.ClassFile) string {
+	switch class.SuperName {
+	case "", "java/lang/Object":
+		return ""
+	case "java/lang/Enum":
+		if class.Is(classfile.AccEnum) {
+			return ""
+		}
+	case "java/lang/Record":
+		if class.Record {
+			return ""
+		}
+	}
+	return classfile.JavaName(class.SuperName)
+}
+
+// isJavaLangInternal 判断是否为java.lang包中的类型或编译器生成代码引用的java.lang.invoke类型，不计入耦合度
+func isJavaLangInternal(name string) bool {
+	if rest, ok := strings.CutPrefix(name, "java/lang/"); ok {
+		return !strings.Contains(rest, "/") || strings.HasPrefix(rest, "invoke/")
+	}
+	return false
+}
+
+func isAnonymousClass(class *classfile.ClassFile) bool {
+	ic, ok := class.InnerClasses[class.Name]
+	if ok {
+		return ic.Name == ""
+	}
+	// 没有InnerClasses属性时按名称判断：$后全为数字
+	i := strings.LastIndexByte(class.Name, '$')
+	if i < 0 || i == len(class.Name)-1 {
+		return false
+	}
+	return strings.IndexFunc(class.Name[i+1:], func(r rune) bool { return !unicode.IsDigit(r) }) < 0
+}
+
+// bytecodeCalledMethods 统计方法调用的不同外部方法和构造器数，按所属类、名称和描述符区分
+func bytecodeCalledMethods(class *classfile.ClassFile, methods []*classfile.Member) int {
+	called := make(map[classfile.Ref]bool)
+	for _, m := range methods {
+		if m.Code == nil {
+			continue
+		}
+		refs, _ := m.Code.Refs(class.Pool)
+		for _, ref := range refs {
+			if ref.Op >= classfile.OpInvokevirtual && ref.Op <= classfile.OpInvokeinterface && ref.Class != class.Name {
+				ref.Op = 0
+				called[ref] = true
+			}
+		}
+	}
+	return len(called)
+}
+
+// bytecodeLCOM 按字节码计算LCOM4：实例方法通过读写本类实例字段或调用本类方法连通，
+// 构造器、静态方法、抽象方法和简单的getter/setter不参与。lambda体编译为合成方法，其中的字段访问不计入所在方法
+func bytecodeLCOM(class *classfile.ClassFile, methods []*classfile.Member) int {
+	if class.Is(classfile.AccInterface) {
+		return 0
+	}
+	instanceFields := make(map[string]bool)
+	for _, f := range class.Fields {
+		if !f.Is(classfile.AccStatic) {
+			instanceFields[f.Name] = true
+		}
+	}
+
+	var names []string
+	var fields, calls []map[string]bool
+	for _, m := range methods {
+		if m.Code == nil || m.Name == "<init>" || m.Is(classfile.AccStatic) || isBytecodeAccessor(m) {
+			continue
+		}
+		refs, _ := m.Code.Refs(class.Pool)
+		uses, invokes := make(map[string]bool), make(map[string]bool)
+		for _, ref := range refs {
+			if ref.Class != class.Name {
+				continue
+			}
+			switch {
+			case (ref.Op == classfile.OpGetfield || ref.Op == classfile.OpPutfield) && instanceFields[ref.Name]:
+				uses[ref.Name] = true
+			case ref.Op >= classfile.OpInvokevirtual && ref.Op <= classfile.OpInvokeinterface && ref.Name != "<init>":
+				invokes[ref.Name] = true
+			}
+		}
+		names = append(names, m.Name)
+		fields = append(fields, uses)
+		calls = append(calls, invokes)
+	}
+	if len(names) == 0 {
+		return 0
+	}
+	return complexity.LCOM4(names, fields, calls)
+}
+
+// isBytecodeAccessor 判断是否为getX/isX/setX形式且方法体极短的访问器
+func isBytecodeAccessor(m *classfile.Member) bool {
+	for _, prefix := range []string{"get", "is", "set"} {
+		rest, ok := strings.CutPrefix(m.Name, prefix)
+		if ok && rest != "" && unicode.IsUpper(rune(rest[0])) {
+			return len(m.Code.Bytecode) <= maxAccessorBytecode
+		}
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+package analyzer
+
+import (
+	"strings"
+
+	"github.com/liujinliang/lang-checker/internal/models"
+)
+
+// linkJavaClasses 在报告范围内解析Java类的继承关系，填充DIT和NOC。
+// 父类型先按全限定名查找，找不到时（如按需导入）按简单类名唯一匹配，分析范围外的父类型不计入
+func linkJavaClasses(report *models.Report) {
+	byName := make(map[string]*models.ClassMetrics)
+	bySimple := make(map[string][]*models.ClassMetrics)
+	for _, m := range report.Files {
+		for i := range m.Classes {
+			c := &m.Classes[i]
+			byName[qualifiedClassName(m.Package, c.Name)] = c
+			bySimple[simpleClassName(c.Name)] = append(bySimple[simpleClassName(c.Name)], c)
+		}
+	}
+	resolve := func(name string) *models.ClassMetrics {
+		if c, ok := byName[name]; ok {
+			return c
+		}
+		if candidates := bySimple[simpleClassName(name)]; len(candidates) == 1 {
+			return candidates[0]
+		}
+		return nil
+	}
+
+	parents := make(map[*models.ClassMetrics]*models.ClassMetrics)
+	for _, m := range report.Files {
+		for i := range m.Classes {
+			c := &m.Classes[i]
+			if p := resolve(c.Superclass); p != nil && p != c {
+				parents[c] = p
+				p.NOC++
+			}
+			for _, iface := range c.Interfaces {
+				if p := resolve(iface); p != nil && p != c {
+					p.NOC++
+				}
+			}
+		}
+	}
+
+	for c := range parents {
+		// 继承关系有环时（代码本身无法编译）以访问过的类为界
+		seen := map[*models.ClassMetrics]bool{c: true}
+		for p := parents[c]; p != nil && !seen[p]; p = parents[p] {
+			seen[p] = true
+			c.DIT++
+		}
+	}
+}
+
+func qualifiedClassName(pkg, name string) string {
+	if pkg == "" {
+		return name
+	}
+	return pkg + "." + name
+}
+
+func simpleClassName(name string) string {
+	return name[strings.LastIndex(name, ".")+1:]
+}
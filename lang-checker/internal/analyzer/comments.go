@@ -0,0 +1,161 @@
+package analyzer
+
+import (
+	"go/ast"
+	"go/scanner"
+	"go/token"
+	"strings"
+
+	"github.com/liujinliang/lang-checker/internal/models"
+)
+
+// lineCounts 行数统计结果
+type lineCounts struct {
+	total   int
+	code    int
+	comment int
+	blank   int
+}
+
+// countLines 根据代码和注释覆盖的行区间统计行数。
+// 同时包含代码和注释的行（行尾注释）同时计入代码行和注释行，空白行为二者都不包含的行。
+func countLines(content string, code, comment [][2]int) lineCounts {
+	total := strings.Count(content, "\n")
+	if len(content) > 0 && !strings.HasSuffix(content, "\n") {
+		total++
+	}
+
+	isCode := make([]bool, total+2)
+	isComment := make([]bool, total+2)
+	mark := func(spans [][2]int, flags []bool) {
+		for _, s := range spans {
+			for l := s[0]; l <= s[1] && l < len(flags); l++ {
+				flags[l] = true
+			}
+		}
+	}
+	mark(code, isCode)
+	mark(comment, isComment)
+
+	c := lineCounts{total: total}
+	for l := 1; l <= total; l++ {
+		if isCode[l] {
+			c.code++
+		}
+		if isComment[l] {
+			c.comment++
+		}
+		if !isCode[l] && !isComment[l] {
+			c.blank++
+		}
+	}
+	return c
+}
+
+// applyCommentMetrics 填充行数、注释率和文档覆盖率
+func applyCommentMetrics(metrics *models.QualityMetrics, lines lineCounts, public, documented int) {
+	metrics.TotalLines = lines.total
+	metrics.CodeLines = lines.code
+	metrics.CommentLines = lines.comment
+	metrics.BlankLines = lines.blank
+	if nonBlank := lines.total - lines.blank; nonBlank > 0 {
+		metrics.CommentRatio = float64(lines.comment) / float64(nonBlank) * 100
+	}
+
+	metrics.PublicDecls = public
+	metrics.DocumentedDecls = documented
+	if public > 0 {
+		metrics.DocCoverage = float64(documented) / float64(public) * 100
+	} else {
+		metrics.DocCoverage = 100
+	}
+}
+
+// goLineSpans 使用go/scanner扫描源码，返回代码和注释覆盖的行区间
+func goLineSpans(content string) (code, comment [][2]int) {
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(content))
+	var s scanner.Scanner
+	s.Init(file, []byte(content), nil, scanner.ScanComments)
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			return code, comment
+		}
+		// 自动插入的分号没有对应源码
+		if tok == token.SEMICOLON && lit == "\n" {
+			continue
+		}
+		line := file.Line(pos)
+		span := [2]int{line, line + strings.Count(lit, "\n")}
+		if tok == token.COMMENT {
+			comment = append(comment, span)
+		} else {
+			code = append(code, span)
+		}
+	}
+}
+
+// goDocCoverage 统计导出声明数量及其中带文档注释的数量。
+// 导出类型的方法、导出函数、导出类型以及含导出名称的常量/变量声明均计入，分组声明的组注释视为覆盖组内所有声明。
+func goDocCoverage(file *ast.File) (exported, documented int) {
+	count := func(hasDoc bool) {
+		exported++
+		if hasDoc {
+			documented++
+		}
+	}
+
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if !d.Name.IsExported() {
+				continue
+			}
+			if d.Recv != nil && !receiverExported(d.Recv) {
+				continue
+			}
+			count(d.Doc != nil)
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					if s.Name.IsExported() {
+						count(s.Doc != nil || d.Doc != nil)
+					}
+				case *ast.ValueSpec:
+					for _, name := range s.Names {
+						if name.IsExported() {
+							count(s.Doc != nil || d.Doc != nil || s.Comment != nil)
+							break
+						}
+					}
+				}
+			}
+		}
+	}
+	return exported, documented
+}
+
+func receiverExported(recv *ast.FieldList) bool {
+	if len(recv.List) == 0 {
+		return false
+	}
+	t := recv.List[0].Type
+	for {
+		switch x := t.(type) {
+		case *ast.StarExpr:
+			t = x.X
+		case *ast.IndexExpr:
+			t = x.X
+		case *ast.IndexListExpr:
+			t = x.X
+		case *ast.ParenExpr:
+			t = x.X
+		case *ast.Ident:
+			return x.IsExported()
+		default:
+			return false
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package analyzer
+
+import (
+	"context"
+	"strings"
+
+	"github.com/liujinliang/lang-checker/internal/classfile"
+	"github.com/liujinliang/lang-checker/internal/decompiler"
+	"github.com/liujinliang/lang-checker/internal/models"
+)
+
+// classGroup 一个顶层类及其内部类、匿名类的class文件
+type classGroup struct {
+	name    string
+	entries []classfile.Entry
+}
+
+// decompileJar 按顶层类反编译JAR包并分析还原出的源码。结果的路径是顶层类的 JAR路径!/包内路径，
+// 问题位置对应反编译出的源码；所有反编译器都失败的类记录诊断信息后按字节码分析。
+// 每个反编译器处理单个类受DecompileTimeout限制，分析还原出的源码受FileTimeout限制
+func (ca *CodeAnalyzer) decompileJar(ctx context.Context, path string) (*models.Report, error) {
+	report := &models.Report{}
+	var entries []classfile.Entry
+	err := classfile.WalkJar(ctx, path, ca.options.MaxFileSize, func(e classfile.Entry) error {
+		if e.Err != nil {
+			report.Diagnostics = append(report.Diagnostics, classFileDiagnostic(path+"!/"+e.Name, e.Err))
+		} else if !e.Class.Is(classfile.AccModule) {
+			entries = append(entries, e)
+		}
+		return nil
+	})
+	if err != nil {
+		return report, err
+	}
+
+	for _, g := range groupClasses(entries) {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+		if err := ca.decompileClass(ctx, report, path, g); err != nil {
+			return report, err
+		}
+	}
+	return report, nil
+}
+
+// decompileClass 反编译一个顶层类并将分析结果并入报告
+func (ca *CodeAnalyzer) decompileClass(ctx context.Context, report *models.Report, jarPath string, g classGroup) error {
+	classPath := jarPath + "!/" + g.name + ".class"
+	class := decompiler.Class{Name: g.name, Files: make(map[string][]byte, len(g.entries))}
+	for _, e := range g.entries {
+		class.Files[e.Name] = e.Data
+	}
+
+	result, err := decompiler.Decompile(ctx, ca.options.Decompilers, class, ca.options.DecompileTimeout)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		report.Diagnostics = append(report.Diagnostics, models.Diagnostic{
+			FilePath: classPath,
+			Kind:     models.DiagnosticDecompile,
+			Message:  "反编译失败，改为字节码分析: " + strings.ReplaceAll(err.Error(), "\n", "; "),
+		})
+		for _, e := range g.entries {
+			report.Files = append(report.Files, bytecodeMetrics(e.Class, jarPath+"!/"+e.Name, ca.options.Thresholds))
+		}
+		return nil
+	}
+
+	// 按.java路径分析以识别语言，再把结果映射回class文件
+	sourcePath := jarPath + "!/" + g.name + ".java"
+	metrics, err := ca.AnalyzeSource(ctx, sourcePath, result.Source)
+	if metrics != nil {
+		metrics.FilePath = classPath
+		metrics.Decompiler = result.Decompiler
+		for i := range metrics.Issues {
+			metrics.Issues[i].FilePath = classPath
+		}
+	}
+	return ca.collect(report, classPath, metrics, err)
+}
+
+// groupClasses 把class文件按所属的顶层类分组，保持顶层类首次出现的顺序
+func groupClasses(entries []classfile.Entry) []classGroup {
+	present := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		present[strings.TrimSuffix(e.Name, ".class")] = true
+	}
+	var groups []classGroup
+	index := make(map[string]int)
+	for _, e := range entries {
+		top := topLevelClass(strings.TrimSuffix(e.Name, ".class"), present)
+		i, ok := index[top]
+		if !ok {
+			i = len(groups)
+			index[top] = i
+			groups = append(groups, classGroup{name: top})
+		}
+		groups[i].entries = append(groups[i].entries, e)
+	}
+	return groups
+}
+
+// topLevelClass 返回内部名称name所属的顶层类：类名中第一个'$'之前的部分在JAR包中存在时取该类，
+// 否则name本身就是顶层类（类名中可以合法地包含'$'）
+func topLevelClass(name string, present map[string]bool) string {
+	start := strings.LastIndexByte(name, '/') + 1
+	for i := start + 1; i < len(name); i++ {
+		if name[i] == '$' && present[name[:i]] {
+			return name[:i]
+		}
+	}
+	return name
+}
@@ -0,0 +1,190 @@
+package analyzer
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/liujinliang/lang-checker/internal/decompiler"
+	"github.com/liujinliang/lang-checker/internal/models"
+)
+
+// 桩反编译器：参数为 {output} {class}，按包路径写出一个固定的Java类
+const stubDecompiler = `#!/bin/sh
+file="$1/$(echo "$2" | tr . /).java"
+mkdir -p "$(dirname "$file")"
+cat > "$file" <<'EOF'
+package com.example;
+
+public class Foo {
+    private int count;
+
+    public int next(int step) {
+        if (step > 0) {
+            count += step;
+        }
+        return count;
+    }
+}
+EOF
+`
+
+// minimalClass 构造只有类名和父类的class文件
+func minimalClass(name string) []byte {
+	var b bytes.Buffer
+	u2 := func(v int) { b.Write([]byte{byte(v >> 8), byte(v)}) }
+	utf8 := func(s string) { b.WriteByte(1); u2(len(s)); b.WriteString(s) }
+	b.Write([]byte{0xCA, 0xFE, 0xBA, 0xBE})
+	u2(0)
+	u2(52)
+	u2(5)
+	utf8(name)
+	b.WriteByte(7)
+	u2(1)
+	utf8("java/lang/Object")
+	b.WriteByte(7)
+	u2(3)
+	u2(0x21) // public super
+	u2(2)
+	u2(4)
+	u2(0) // interfaces
+	u2(0) // fields
+	u2(0) // methods
+	u2(0) // attributes
+	return b.Bytes()
+}
+
+func writeTestJar(t *testing.T, classes ...string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "app.jar")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	for _, name := range classes {
+		w, err := zw.Create(name + ".class")
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write(minimalClass(name))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	return path
+}
+
+func stubDecompilers(t *testing.T, scripts map[string]string, order ...string) []decompiler.Decompiler {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("桩反编译器是shell脚本")
+	}
+	var ds []decompiler.Decompiler
+	for _, name := range order {
+		path := filepath.Join(t.TempDir(), name+".sh")
+		if err := os.WriteFile(path, []byte(scripts[name]), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		ds = append(ds, decompiler.Decompiler{Name: name, Command: []string{path, decompiler.PlaceholderOutput, decompiler.PlaceholderClass}})
+	}
+	return ds
+}
+
+func TestAnalyzeJarDecompiled(t *testing.T) {
+	jar := writeTestJar(t, "com/example/Foo", "com/example/Foo$1", "com/example/Foo$Bar")
+	opts := DefaultOptions()
+	opts.Decompilers = stubDecompilers(t, map[string]string{
+		"broken": "#!/bin/sh\nexit 1\n",
+		"stub":   stubDecompiler,
+	}, "broken", "stub")
+	opts.DecompileTimeout = time.Minute
+
+	report, err := NewCodeAnalyzerWithOptions(opts).AnalyzeJar(context.Background(), jar)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Files) != 1 {
+		t.Fatalf("内部类应合并到顶层类，文件数 = %d", len(report.Files))
+	}
+	m := report.Files[0]
+	if want := jar + "!/com/example/Foo.class"; m.FilePath != want {
+		t.Errorf("FilePath = %s, want %s", m.FilePath, want)
+	}
+	if m.Decompiler != "stub" || m.Bytecode || m.Language != models.Java {
+		t.Errorf("Decompiler = %q, Bytecode = %v, Language = %s", m.Decompiler, m.Bytecode, m.Language)
+	}
+	if m.FunctionCount != 1 || m.Functions[0].CyclomaticComplexity != 2 || m.Score == 0 {
+		t.Errorf("应按源码分析: functions = %d, score = %.2f", m.FunctionCount, m.Score)
+	}
+	if len(m.Classes) != 1 || m.Classes[0].Name != "Foo" {
+		t.Errorf("Classes = %+v", m.Classes)
+	}
+}
+
+func TestAnalyzeJarDecompileFailed(t *testing.T) {
+	jar := writeTestJar(t, "com/example/Foo", "com/example/Foo$Bar")
+	opts := DefaultOptions()
+	opts.Decompilers = stubDecompilers(t, map[string]string{"broken": "#!/bin/sh\necho oops >&2\nexit 1\n"}, "broken")
+
+	report, err := NewCodeAnalyzerWithOptions(opts).AnalyzeJar(context.Background(), jar)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Files) != 2 || !report.Files[0].Bytecode || !report.Files[1].Bytecode {
+		t.Fatalf("反编译失败时应回退为字节码分析: %d个文件", len(report.Files))
+	}
+	if len(report.Diagnostics) != 1 || report.Diagnostics[0].Kind != models.DiagnosticDecompile {
+		t.Errorf("Diagnostics = %+v", report.Diagnostics)
+	}
+}
+
+func TestAnalyzeDecompiledJar(t *testing.T) {
+	jar := writeTestJar(t, "com/example/Foo", "com/example/FooTest")
+	dir := filepath.Dir(jar)
+	test := "package com.example;\n\npublic class BarTest {\n    void testNext() {\n        new Foo().next(1);\n    }\n}\n"
+	if err := os.WriteFile(filepath.Join(dir, "BarTest.java"), []byte(test), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	opts := DefaultOptions()
+	opts.Decompilers = stubDecompilers(t, map[string]string{"stub": stubDecompiler}, "stub")
+	opts.DecompileTimeout = time.Minute
+	ca := NewCodeAnalyzerWithOptions(opts)
+
+	// 重复代码检测和测试统计不能按反编译结果的虚拟路径读取文件
+	for _, path := range []string{jar, dir} {
+		report, err := ca.Analyze(context.Background(), path)
+		if err != nil {
+			t.Fatalf("Analyze(%s): %v", path, err)
+		}
+		decompiled := 0
+		for _, m := range report.Files {
+			if m.Decompiler == "stub" {
+				decompiled++
+			}
+		}
+		if decompiled != 2 {
+			t.Errorf("Analyze(%s): 反编译的文件数 = %d, want 2", path, decompiled)
+		}
+	}
+}
+
+func TestAnalyzeJarUnsafeEntry(t *testing.T) {
+	jar := writeTestJar(t, "com/example/Foo", "../../evil/Evil", "/abs/Evil")
+	opts := DefaultOptions()
+	opts.Decompilers = stubDecompilers(t, map[string]string{"stub": stubDecompiler}, "stub")
+
+	report, err := NewCodeAnalyzerWithOptions(opts).AnalyzeJar(context.Background(), jar)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Files) != 1 || report.Files[0].FilePath != jar+"!/com/example/Foo.class" {
+		t.Errorf("含..或以/开头的条目应跳过: %d个文件", len(report.Files))
+	}
+}
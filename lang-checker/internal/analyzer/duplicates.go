@@ -0,0 +1,46 @@
+package analyzer
+
+import (
+	"context"
+
+	"github.com/liujinliang/lang-checker/internal/duplicate"
+	"github.com/liujinliang/lang-checker/internal/models"
+)
+
+// detectDuplicates 对报告中的非生成代码源文件做跨文件重复代码检测，填充每个文件的DuplicateLines并重新计算得分。
+// 源码取自分析时保留的Content，不重新读取文件；反编译出的源码不是可修改的代码，不参与检测
+func (ca *CodeAnalyzer) detectDuplicates(ctx context.Context, report *models.Report) error {
+	var sources []duplicate.Source
+	for _, m := range report.Files {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if m.Generated || m.Bytecode || m.Decompiler != "" {
+			continue
+		}
+		src := duplicate.Source{Path: m.FilePath}
+		switch m.Language {
+		case models.Go:
+			src.Tokens = duplicate.GoTokens(m.Content)
+		case models.Java:
+			src.Tokens = duplicate.JavaTokens(m.Content)
+		}
+		sources = append(sources, src)
+	}
+
+	opts := duplicate.DefaultOptions()
+	opts.MinTokens = ca.options.Thresholds.DuplicateTokens
+	groups, lines, err := duplicate.Detect(ctx, sources, opts)
+	if err != nil {
+		return err
+	}
+
+	report.Clones = groups
+	for _, m := range report.Files {
+		if n := lines[m.FilePath]; n > 0 {
+			m.DuplicateLines = n
+			m.Score = calculateQualityScore(m, ca.thresholdsFor(m))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDetectDuplicatesFromAnalyzedContent(t *testing.T) {
+	body := `    public int sum(int[] values, int limit) {
+        int total = 0;
+        for (int i = 0; i < values.length; i++) {
+            if (values[i] > limit) {
+                total += values[i] * 2;
+            } else {
+                total -= values[i] / 3;
+            }
+        }
+        return total;
+    }
+`
+	dir := t.TempDir()
+	files := make(map[string]string)
+	for _, name := range []string{"A", "B"} {
+		files[name+".java"] = fmt.Sprintf("package com.example;\n\npublic class %s {\n%s}\n", name, body)
+	}
+	writeFiles(t, dir, files)
+
+	opts := DefaultOptions()
+	// 分析完成后删除文件，重复代码检测只能使用分析时保留的源码
+	opts.Progress = func(path string, done, total int) {
+		if err := os.Remove(path); err != nil {
+			t.Error(err)
+		}
+	}
+	report, err := NewCodeAnalyzerWithOptions(opts).AnalyzeDirectory(context.Background(), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Clones) != 1 || len(report.Clones[0].Locations) != 2 {
+		t.Fatalf("clones = %+v, want one group with 2 locations", report.Clones)
+	}
+	for _, m := range report.Files {
+		if m.DuplicateLines == 0 {
+			t.Errorf("%s: DuplicateLines = 0", filepath.Base(m.FilePath))
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package analyzer
+
+import (
+	"context"
+	"go/ast"
+	"go/token"
+
+	"github.com/liujinliang/lang-checker/internal/complexity"
+	"github.com/liujinliang/lang-checker/internal/java"
+	"github.com/liujinliang/lang-checker/internal/models"
+)
+
+// goFunctionMetrics 计算文件中每个函数声明的指标，comment为注释覆盖的行区间
+func goFunctionMetrics(ctx context.Context, node *ast.File, fset *token.FileSet, content string, comment [][2]int) ([]models.FunctionMetrics, error) {
+	var result []models.FunctionMetrics
+	for _, decl := range node.Decls {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok {
+			continue
+		}
+		startPos, endPos := fset.Position(fn.Pos()), fset.Position(fn.End())
+		start, end := startPos.Line, endPos.Line
+		fm := models.FunctionMetrics{
+			Name:                 fn.Name.Name,
+			StartLine:            start,
+			EndLine:              end,
+			Lines:                end - start + 1,
+			Parameters:           countFieldNames(fn.Type.Params),
+			CyclomaticComplexity: 1,
+			Exported:             fn.Name.IsExported() && (fn.Recv == nil || receiverExported(fn.Recv)),
+			CommentLines:         linesWithin(comment, start, end),
+		}
+		if fn.Recv != nil && len(fn.Recv.List) > 0 {
+			fm.Receiver = receiverTypeName(fn.Recv.List[0].Type)
+		}
+		if fn.Body != nil {
+			fm.CyclomaticComplexity += calculateTotalComplexity(fn.Body)
+			fm.CognitiveComplexity = complexity.GoCognitiveComplexity(fn.Body)
+			fm.MaxNesting = detectDeepNesting(fn.Body)
+		}
+		if endPos.Offset <= len(content) {
+			fm.Halstead = complexity.GoHalstead([]byte(content[startPos.Offset:endPos.Offset]))
+		}
+		fm.MaintainabilityIndex = functionMaintainability(fm)
+		result = append(result, fm)
+	}
+	return result, nil
+}
+
+// countFieldNames 统计参数个数，未命名参数按1个计
+func countFieldNames(fields *ast.FieldList) int {
+	if fields == nil {
+		return 0
+	}
+	count := 0
+	for _, f := range fields.List {
+		if len(f.Names) == 0 {
+			count++
+		} else {
+			count += len(f.Names)
+		}
+	}
+	return count
+}
+
+// receiverTypeName 返回接收者的类型名，去掉指针和类型参数
+func receiverTypeName(expr ast.Expr) string {
+	for {
+		switch t := expr.(type) {
+		case *ast.StarExpr:
+			expr = t.X
+		case *ast.IndexExpr:
+			expr = t.X
+		case *ast.IndexListExpr:
+			expr = t.X
+		case *ast.ParenExpr:
+			expr = t.X
+		case *ast.Ident:
+			return t.Name
+		default:
+			return ""
+		}
+	}
+}
+
+// nestingVisitor 跟踪控制结构的嵌套深度，else if 与所属的 if 视为同一层
+type nestingVisitor struct {
+	depth int
+	max   *int
+}
+
+func (v nestingVisitor) enter() nestingVisitor {
+	inner := nestingVisitor{depth: v.depth + 1, max: v.max}
+	if inner.depth > *v.max {
+		*v.max = inner.depth
+	}
+	return inner
+}
+
+func (v nestingVisitor) Visit(n ast.Node) ast.Visitor {
+	switch stmt := n.(type) {
+	case *ast.IfStmt:
+		inner := v.enter()
+		if stmt.Init != nil {
+			ast.Walk(inner, stmt.Init)
+		}
+		ast.Walk(inner, stmt.Cond)
+		ast.Walk(inner, stmt.Body)
+		if elseIf, ok := stmt.Else.(*ast.IfStmt); ok {
+			ast.Walk(v, elseIf)
+		} else if stmt.Else != nil {
+			ast.Walk(inner, stmt.Else)
+		}
+		return nil
+	case *ast.ForStmt, *ast.RangeStmt, *ast.SwitchStmt, *ast.TypeSwitchStmt, *ast.SelectStmt:
+		return v.enter()
+	}
+	return v
+}
+
+// javaFunctionMetrics 基于AST计算每个有方法体的Java方法和构造器的指标，comment为注释覆盖的行区间
+func javaFunctionMetrics(ctx context.Context, file *java.File, comment [][2]int) ([]models.FunctionMetrics, error) {
+	var result []models.FunctionMetrics
+	for _, m := range java.Methods(file) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if m.Body == nil {
+			continue
+		}
+		start := m.Name.Pos().Line
+		end := m.Body.End().Line
+		fm := models.FunctionMetrics{
+			Name:                 m.Name.Name,
+			Receiver:             m.Owner.TypeName(),
+			StartLine:            start,
+			EndLine:              end,
+			Lines:                end - start + 1,
+			Parameters:           len(m.Params),
+			Exported:             m.Has("public"),
+			CyclomaticComplexity: complexity.JavaCyclomaticComplexity(m.Body),
+			CognitiveComplexity:  complexity.JavaCognitiveComplexity(m.Body),
+			MaxNesting:           complexity.JavaMaxNesting(m.Body),
+			CommentLines:         linesWithin(comment, start, end),
+			Halstead:             complexity.JavaHalstead(file.Tokens[m.Name.First : m.Body.Last+1]),
+		}
+		fm.MaintainabilityIndex = functionMaintainability(fm)
+		result = append(result, fm)
+	}
+	return result, nil
+}
+
+// linesWithin 统计spans在[start, end]行范围内覆盖的行数
+func linesWithin(spans [][2]int, start, end int) int {
+	covered := make(map[int]bool)
+	for _, s := range spans {
+		for l := max(s[0], start); l <= min(s[1], end); l++ {
+			covered[l] = true
+		}
+	}
+	return len(covered)
+}
+
+func functionMaintainability(fm models.FunctionMetrics) float64 {
+	ratio := 0.0
+	if fm.Lines > 0 {
+		ratio = float64(fm.CommentLines) / float64(fm.Lines) * 100
+	}
+	return complexity.MaintainabilityIndex(fm.Halstead.Volume, float64(fm.CyclomaticComplexity), float64(fm.Lines), ratio)
+}
+
+// fileMaintainability 按文件内函数的平均体积、圈复杂度和行数计算文件的可维护性指数，
+// 没有函数时使用整个文件的度量
+func fileMaintainability(metrics *models.QualityMetrics) float64 {
+	n := len(metrics.Functions)
+	if n == 0 {
+		return complexity.MaintainabilityIndex(metrics.Halstead.Volume, float64(metrics.CyclomaticComplexity),
+			float64(metrics.CodeLines), metrics.CommentRatio)
+	}
+	var volume, cyclomatic, lines float64
+	for _, fn := range metrics.Functions {
+		volume += fn.Halstead.Volume
+		cyclomatic += float64(fn.CyclomaticComplexity)
+		lines += float64(fn.Lines)
+	}
+	return complexity.MaintainabilityIndex(volume/float64(n), cyclomatic/float64(n), lines/float64(n), metrics.CommentRatio)
+}
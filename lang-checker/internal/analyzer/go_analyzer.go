@@ -1,61 +1,98 @@
 package analyzer
 
 import (
+	"context"
 	"go/ast"
 	"go/parser"
 	"go/token"
+	"go/types"
+	"path/filepath"
 
+	"github.com/liujinliang/lang-checker/internal/complexity"
 	"github.com/liujinliang/lang-checker/internal/models"
 	"github.com/liujinliang/lang-checker/internal/rules"
 )
 
 // GoAnalyzer Go代码分析器
 type GoAnalyzer struct {
-	fileSet *token.FileSet
-	rules   []rules.GoRule
-}
-
-// GoRule Go语言规则接口
-type GoRule interface {
-	Check(node ast.Node, fset *token.FileSet) []models.Issue
-	Name() string
+	fileSet    *token.FileSet
+	engine     *rules.Engine
+	thresholds rules.Thresholds
+	// test 是否用于分析测试代码，传给规则的SourceFile.IsTest
+	test bool
 }
 
 // NewGoAnalyzer 创建新的Go分析器
-func NewGoAnalyzer() *GoAnalyzer {
+func NewGoAnalyzer(engine *rules.Engine, thresholds rules.Thresholds) *GoAnalyzer {
 	return &GoAnalyzer{
-		fileSet: token.NewFileSet(),
-		rules: []rules.GoRule{
-			&rules.FunctionLengthRule{},
-			&rules.CyclomaticComplexityRule{},
-			&rules.NamingConventionRule{},
-		},
+		fileSet:    token.NewFileSet(),
+		engine:     engine,
+		thresholds: thresholds.WithDefaults(),
 	}
 }
 
 // Analyze 分析Go代码
-func (ga *GoAnalyzer) Analyze(content string, filePath string) (*models.QualityMetrics, error) {
+func (ga *GoAnalyzer) Analyze(ctx context.Context, content string, filePath string) (*models.QualityMetrics, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	node, err := parser.ParseFile(ga.fileSet, filePath, content, parser.ParseComments)
 	if err != nil {
 		return nil, err
 	}
+	return ga.AnalyzeAST(ctx, node, content, filePath, nil, nil)
+}
 
+// AnalyzeAST 分析已解析的Go文件，info和pkg为包模式下的类型信息，单文件模式传nil
+func (ga *GoAnalyzer) AnalyzeAST(ctx context.Context, node *ast.File, content string, filePath string, info *types.Info, pkg *types.Package) (*models.QualityMetrics, error) {
 	metrics := &models.QualityMetrics{
 		FilePath: filePath,
 		Language: models.Go,
+		Package:  filepath.Dir(filePath),
 	}
 
+	code, comment := goLineSpans(content)
+
 	// 基础指标计算
 	metrics.FunctionCount = countFunctions(node)
 	metrics.CyclomaticComplexity = calculateTotalComplexity(node)
-	metrics.LongFunctions = countLongFunctions(node, ga.fileSet)
+	metrics.LongFunctions = countLongFunctions(node, ga.fileSet, ga.thresholds.FunctionLength)
 	metrics.DeepNesting = detectDeepNesting(node)
+	var err error
+	metrics.Functions, err = goFunctionMetrics(ctx, node, ga.fileSet, content, comment)
+	if err != nil {
+		return nil, err
+	}
+	for _, fn := range metrics.Functions {
+		metrics.CognitiveComplexity += fn.CognitiveComplexity
+	}
+
+	// 注释指标
+	exported, documented := goDocCoverage(node)
+	applyCommentMetrics(metrics, countLines(content, code, comment), exported, documented)
+
+	// Halstead度量和可维护性指数
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	metrics.Halstead = complexity.GoHalstead([]byte(content))
+	metrics.MaintainabilityIndex = fileMaintainability(metrics)
 
 	// 应用规则检查
-	for _, rule := range ga.rules {
-		issues := rule.Check(node, ga.fileSet)
-		metrics.Issues = append(metrics.Issues, issues...)
+	issues, err := ga.engine.Run(ctx, &rules.SourceFile{
+		Path:      filePath,
+		Language:  models.Go,
+		Content:   content,
+		Fset:      ga.fileSet,
+		AST:       node,
+		TypesInfo: info,
+		TypesPkg:  pkg,
+		IsTest:    ga.test,
+	})
+	if err != nil {
+		return nil, err
 	}
+	metrics.Issues = issues
 
 	return metrics, nil
 }
@@ -84,13 +121,13 @@ func calculateTotalComplexity(node ast.Node) int {
 	return total
 }
 
-func countLongFunctions(node ast.Node, fset *token.FileSet) int {
+func countLongFunctions(node ast.Node, fset *token.FileSet, maxLines int) int {
 	count := 0
 	ast.Inspect(node, func(n ast.Node) bool {
 		if fn, ok := n.(*ast.FuncDecl); ok {
 			start := fset.Position(fn.Pos())
 			end := fset.Position(fn.End())
-			if end.Line-start.Line > 50 {
+			if end.Line-start.Line > maxLines {
 				count++
 			}
 		}
@@ -99,20 +136,9 @@ func countLongFunctions(node ast.Node, fset *token.FileSet) int {
 	return count
 }
 
+// detectDeepNesting 计算控制结构的最大嵌套深度
 func detectDeepNesting(node ast.Node) int {
 	maxDepth := 0
-	currentDepth := 0
-
-	ast.Inspect(node, func(n ast.Node) bool {
-		switch n.(type) {
-		case *ast.IfStmt, *ast.ForStmt, *ast.RangeStmt, *ast.SelectStmt, *ast.SwitchStmt:
-			currentDepth++
-			if currentDepth > maxDepth {
-				maxDepth = currentDepth
-			}
-		}
-		return true
-	})
-
+	ast.Walk(nestingVisitor{max: &maxDepth}, node)
 	return maxDepth
 }
@@ -0,0 +1,101 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go/scanner"
+
+	"github.com/liujinliang/lang-checker/internal/depgraph"
+	"github.com/liujinliang/lang-checker/internal/gopkg"
+	"github.com/liujinliang/lang-checker/internal/models"
+	"github.com/liujinliang/lang-checker/internal/source"
+)
+
+// AnalyzeGoPackages 按包加载dir下的Go代码并进行类型检查后分析，规则可通过SourceFile.TypesInfo使用类型信息
+func (ca *CodeAnalyzer) AnalyzeGoPackages(ctx context.Context, dir string) (*models.Report, error) {
+	_, pkgs, skipped, err := gopkg.Load(ctx, ca.goAnalyzer.fileSet, dir, ca.options.MaxFileSize)
+	if err != nil {
+		return nil, err
+	}
+
+	total := 0
+	for _, pkg := range pkgs {
+		total += len(pkg.Files)
+	}
+
+	report := &models.Report{}
+	for _, f := range skipped {
+		if err := ca.collectSkipped(report, f); err != nil {
+			return report, err
+		}
+	}
+	done := 0
+	for _, pkg := range pkgs {
+		if len(pkg.TypeErrors) > 0 {
+			report.Diagnostics = append(report.Diagnostics, models.Diagnostic{
+				FilePath: pkg.Dir,
+				Kind:     models.DiagnosticTypeCheck,
+				Message:  fmt.Sprintf("包 %s 存在%d个类型错误，类型信息可能不完整: %v", pkg.ImportPath, len(pkg.TypeErrors), pkg.TypeErrors[0]),
+			})
+		}
+
+		for i, file := range pkg.Files {
+			if err := ctx.Err(); err != nil {
+				return report, err
+			}
+
+			path, content, encoding := pkg.FilePaths[i], pkg.Contents[i], pkg.Encodings[i]
+			if !isTargetFile(path) {
+				continue
+			}
+			metrics, err := ca.withFileTimeout(ctx, func(ctx context.Context) (*models.QualityMetrics, error) {
+				reason, err := ca.checkGenerated(path, []byte(content))
+				if err != nil {
+					return nil, err
+				}
+				metrics, err := ca.goAnalyzer.AnalyzeAST(ctx, file, content, path, pkg.Info, pkg.Types)
+				if err != nil {
+					return nil, err
+				}
+				if encoding != source.UTF8 {
+					metrics.Encoding = encoding
+				}
+				markGenerated(metrics, reason)
+				return ca.finishMetrics(ctx, metrics, content)
+			})
+			if err := ca.collect(report, path, metrics, err); err != nil {
+				return report, err
+			}
+
+			done++
+			if ca.options.Progress != nil {
+				ca.options.Progress(path, done, total)
+			}
+		}
+	}
+	return report, nil
+}
+
+// collectSkipped 将包模式下未能加载的文件记为诊断信息：语法错误记为syntax，超限和二进制文件同逐个文件分析
+func (ca *CodeAnalyzer) collectSkipped(report *models.Report, f gopkg.SkippedFile) error {
+	var list scanner.ErrorList
+	if errors.As(f.Err, &list) && len(list) > 0 {
+		report.Diagnostics = append(report.Diagnostics, models.Diagnostic{
+			FilePath: f.Path,
+			Kind:     models.DiagnosticSyntax,
+			Message:  fmt.Sprintf("存在%d处语法错误，已跳过该文件，首个错误: %s", len(list), list[0]),
+		})
+		return nil
+	}
+	return ca.collect(report, f.Path, nil, f.Err)
+}
+
+// AnalyzeGoDependencies 计算dir下Go包之间的依赖图、耦合度指标和导入环
+func (ca *CodeAnalyzer) AnalyzeGoDependencies(ctx context.Context, dir string) (*models.DependencyReport, error) {
+	mod, pkgs, _, err := gopkg.Load(ctx, ca.goAnalyzer.fileSet, dir, ca.options.MaxFileSize)
+	if err != nil {
+		return nil, err
+	}
+	return depgraph.Build(mod, pkgs), nil
+}
@@ -1,128 +1,149 @@
 package analyzer
 
 import (
-	"regexp"
-	"strings"
+	"context"
 
+	"github.com/liujinliang/lang-checker/internal/complexity"
+	"github.com/liujinliang/lang-checker/internal/generated"
+	"github.com/liujinliang/lang-checker/internal/java"
 	"github.com/liujinliang/lang-checker/internal/models"
 	"github.com/liujinliang/lang-checker/internal/rules"
 )
 
 // JavaAnalyzer Java代码分析器
 type JavaAnalyzer struct {
-	rules []JavaRule
-}
-
-// JavaRule Java语言规则接口
-type JavaRule interface {
-	CheckJava(content string) []models.Issue
-	Name() string
+	engine     *rules.Engine
+	thresholds rules.Thresholds
+	// test 是否用于分析测试代码，传给规则的SourceFile.IsTest
+	test bool
+	// generated 是否丢弃标注为生成代码的成员内的问题
+	generated bool
 }
 
 // NewJavaAnalyzer 创建新的Java分析器
-func NewJavaAnalyzer() *JavaAnalyzer {
+func NewJavaAnalyzer(engine *rules.Engine, thresholds rules.Thresholds) *JavaAnalyzer {
 	return &JavaAnalyzer{
-		rules: []JavaRule{
-			&rules.JavaFunctionLengthRule{},
-			&rules.JavaNamingConventionRule{},
-		},
+		engine:     engine,
+		thresholds: thresholds.WithDefaults(),
 	}
 }
 
 // Analyze 分析Java代码
-func (ja *JavaAnalyzer) Analyze(content string, filePath string) (*models.QualityMetrics, error) {
+func (ja *JavaAnalyzer) Analyze(ctx context.Context, content string, filePath string) (*models.QualityMetrics, error) {
 	metrics := &models.QualityMetrics{
 		FilePath: filePath,
 		Language: models.Java,
 	}
 
+	tokens := java.Tokenize(content)
+	file, err := java.ParseTokensContext(ctx, tokens)
+	if err != nil {
+		return nil, err
+	}
+	codeSpans, commentSpans := java.LineSpans(tokens)
+	metrics.Package = file.Package
+	for _, e := range file.Errors {
+		metrics.SyntaxErrors = append(metrics.SyntaxErrors, e.Error())
+	}
+
 	// 基础指标计算
-	metrics.FunctionCount = countJavaFunctions(content)
-	metrics.CyclomaticComplexity = calculateJavaCyclomaticComplexity(content)
-	metrics.LongFunctions = countJavaLongFunctions(content)
-	metrics.DeepNesting = detectJavaDeepNesting(content)
+	metrics.CyclomaticComplexity = complexity.JavaCyclomaticComplexity(file)
+	metrics.Functions, err = javaFunctionMetrics(ctx, file, commentSpans)
+	if err != nil {
+		return nil, err
+	}
+	metrics.FunctionCount = len(metrics.Functions)
+	for _, fn := range metrics.Functions {
+		if fn.EndLine-fn.StartLine > ja.thresholds.FunctionLength {
+			metrics.LongFunctions++
+		}
+		metrics.DeepNesting = max(metrics.DeepNesting, fn.MaxNesting)
+		metrics.CognitiveComplexity += fn.CognitiveComplexity
+	}
 
-	// 应用规则检查
-	for _, rule := range ja.rules {
-		issues := rule.CheckJava(content)
-		metrics.Issues = append(metrics.Issues, issues...)
+	for _, decl := range java.TypeDecls(file) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		metrics.Classes = append(metrics.Classes, complexity.JavaClass(file, decl))
 	}
 
-	return metrics, nil
-}
+	// 注释指标
+	public, documented := java.DocCoverage(file)
+	applyCommentMetrics(metrics, countLines(content, codeSpans, commentSpans), public, documented)
 
-// 辅助函数
-func countJavaFunctions(content string) int {
-	methodPattern := regexp.MustCompile(`(?m)^\s*(?:public|private|protected)?\s*(?:static)?\s*(?:\w+\s+)*\w+\s*\([^)]*\)`)
-	return len(methodPattern.FindAllString(content, -1))
-}
+	// Halstead度量和可维护性指数
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	metrics.Halstead = complexity.JavaHalstead(file.Tokens)
+	metrics.MaintainabilityIndex = fileMaintainability(metrics)
 
-func calculateJavaCyclomaticComplexity(content string) int {
-	complexity := 1
-	patterns := []string{
-		`\bif\s*\(`, `\belse\b`, `\bwhile\s*\(`, `\bfor\s*\(`,
-		`\bswitch\s*\(`, `\bcase\s+`, `\bcatch\s*\(`, `\b\?\s*`,
+	if err := ctx.Err(); err != nil {
+		return nil, err
 	}
 
-	for _, pattern := range patterns {
-		matches := regexp.MustCompile(pattern).FindAllString(content, -1)
-		complexity += len(matches)
+	// 应用规则检查
+	issues, err := ja.engine.Run(ctx, &rules.SourceFile{
+		Path:        filePath,
+		Language:    models.Java,
+		Content:     content,
+		JavaAST:     file,
+		JavaClasses: metrics.Classes,
+		IsTest:      ja.test,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if ja.generated {
+		issues = dropGeneratedMembers(file, issues)
 	}
+	metrics.Issues = issues
 
-	return complexity
+	return metrics, nil
 }
 
-func countJavaLongFunctions(content string) int {
-	lines := strings.Split(content, "\n")
-	count := 0
-	methodPattern := regexp.MustCompile(`(?m)^\s*(?:public|private|protected)?\s*(?:static)?\s*(?:\w+\s+)*\w+\s*\([^)]*\)`)
-
-	for i, line := range lines {
-		if methodPattern.MatchString(line) {
-			braceCount := 0
-			methodStart := i
-			methodEnd := i
-
-			for j := i; j < len(lines); j++ {
-				braceCount += strings.Count(lines[j], "{")
-				braceCount -= strings.Count(lines[j], "}")
-				if braceCount == 0 && j > i {
-					methodEnd = j
-					break
-				}
-			}
-
-			if methodEnd-methodStart > 50 {
-				count++
+// dropGeneratedMembers 丢弃标注了@Generated等注解的成员（方法、字段、内部类型）内的问题，
+// 文件其余部分仍是手写代码，照常检查
+func dropGeneratedMembers(file *java.File, issues []models.Issue) []models.Issue {
+	var ranges []java.Range
+	java.Inspect(file, func(n java.Node) bool {
+		var mods java.Modifiers
+		var r java.Range
+		switch n := n.(type) {
+		case *java.TypeDecl:
+			mods, r = n.Modifiers, n.Range
+		case *java.MethodDecl:
+			mods, r = n.Modifiers, n.Range
+		case *java.FieldDecl:
+			mods, r = n.Modifiers, n.Range
+		default:
+			return true
+		}
+		for _, a := range mods.Annotations {
+			if generated.IsGeneratedAnnotation(a.Name) {
+				ranges = append(ranges, r)
+				return false
 			}
 		}
+		return true
+	})
+	if ranges == nil {
+		return issues
 	}
-	return count
-}
-
-func detectJavaDeepNesting(content string) int {
-	lines := strings.Split(content, "\n")
-	maxDepth := 0
-	currentDepth := 0
 
-	for _, line := range lines {
-		// 检测控制结构开始
-		if regexp.MustCompile(`\b(if|for|while|switch|try)\s*\(`).MatchString(line) {
-			currentDepth++
-			if currentDepth > maxDepth {
-				maxDepth = currentDepth
+	kept := issues[:0]
+	for _, issue := range issues {
+		inside := false
+		for _, r := range ranges {
+			if issue.Line >= r.Start.Line && issue.Line <= r.Stop.Line {
+				inside = true
+				break
 			}
 		}
-
-		// 检测块结束
-		openBraces := strings.Count(line, "{")
-		closeBraces := strings.Count(line, "}")
-		currentDepth += openBraces - closeBraces
-
-		if currentDepth < 0 {
-			currentDepth = 0
+		if !inside {
+			kept = append(kept, issue)
 		}
 	}
-
-	return maxDepth
+	return kept
 }
@@ -0,0 +1,318 @@
+package analyzer
+
+import (
+	"context"
+	"go/scanner"
+	"go/token"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/liujinliang/lang-checker/internal/java"
+	"github.com/liujinliang/lang-checker/internal/models"
+)
+
+// isTestFile 判断是否为测试代码：Go的_test.go，Java的src/test/java目录或*Test.java
+func isTestFile(path string) bool {
+	if strings.HasSuffix(path, "_test.go") {
+		return true
+	}
+	if !strings.HasSuffix(path, ".java") {
+		return false
+	}
+	slashed := filepath.ToSlash(path)
+	return strings.Contains(slashed, "src/test/java/") || strings.HasSuffix(slashed, "Test.java")
+}
+
+// goTestPrefixes Go测试函数名前缀，去掉前缀后的部分视为被测对象名
+var goTestPrefixes = []string{"Test", "Benchmark", "Example", "Fuzz"}
+
+// javaIgnoredMethods 通常由框架隐式调用，不要求测试直接引用的Java方法
+var javaIgnoredMethods = map[string]bool{
+	"main": true, "equals": true, "hashCode": true, "toString": true,
+}
+
+// analyzeTests 按包统计测试与生产代码行数比例，并找出没有被测试引用的公开API。
+// Go的测试只计算同目录（含外部测试包）的引用；Java的测试只计算同包测试、以被测类命名的测试
+// （FooTest -> Foo）中的调用，以及能确定接收者类型的调用（Foo.bar()、foo.bar()且foo声明为Foo）。
+// 测试源码取自分析时保留的Content。分析范围内没有测试文件时不做统计，JAR包中反编译出的类不参与统计。
+func (ca *CodeAnalyzer) analyzeTests(ctx context.Context, report *models.Report) error {
+	goRefs := make(map[string]map[string]bool)
+	javaRefs := newJavaTestRefs()
+	hasTests := false
+	for _, m := range report.Files {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if !m.Test || m.Generated || m.Bytecode || m.Decompiler != "" {
+			continue
+		}
+		hasTests = true
+		switch m.Language {
+		case models.Go:
+			if goRefs[m.Package] == nil {
+				goRefs[m.Package] = make(map[string]bool)
+			}
+			collectGoTestRefs([]byte(m.Content), goRefs[m.Package])
+		case models.Java:
+			javaRefs.collect(m.Package, java.Parse(m.Content))
+		}
+	}
+	if !hasTests {
+		return nil
+	}
+
+	type key struct {
+		lang models.Language
+		pkg  string
+	}
+	packages := make(map[key]*models.PackageTestMetrics)
+	for _, m := range report.Files {
+		if m.Generated || m.Bytecode || m.Decompiler != "" {
+			continue
+		}
+		k := key{m.Language, m.Package}
+		p := packages[k]
+		if p == nil {
+			p = &models.PackageTestMetrics{Package: m.Package, Language: m.Language}
+			if p.Package == "" {
+				p.Package = "(default)"
+			}
+			packages[k] = p
+		}
+		if m.Test {
+			p.TestLines += m.CodeLines
+			continue
+		}
+		p.ProductionLines += m.CodeLines
+
+		for _, fn := range m.Functions {
+			if !fn.Exported || (m.Language == models.Java && javaIgnoredMethods[fn.Name]) {
+				continue
+			}
+			p.PublicAPI++
+			tested := javaRefs.tested(m.Package, fn.Receiver, fn.Name)
+			if m.Language == models.Go {
+				tested = goRefs[m.Package][fn.Name]
+			}
+			if !tested {
+				name := fn.Name
+				if fn.Receiver != "" {
+					name = fn.Receiver + "." + fn.Name
+				}
+				p.Untested = append(p.Untested, models.UntestedAPI{FilePath: m.FilePath, Line: fn.StartLine, Name: name})
+			}
+		}
+	}
+
+	report.Tests = nil
+	for _, p := range packages {
+		if p.ProductionLines > 0 {
+			p.TestRatio = float64(p.TestLines) / float64(p.ProductionLines)
+		}
+		report.Tests = append(report.Tests, *p)
+	}
+	sort.Slice(report.Tests, func(i, j int) bool {
+		a, b := report.Tests[i], report.Tests[j]
+		if a.Language != b.Language {
+			return a.Language < b.Language
+		}
+		return a.Package < b.Package
+	})
+	return nil
+}
+
+// collectGoTestRefs 收集测试文件中出现的标识符，以及测试函数名中的被测对象名（TestFoo_Bar -> Foo, Bar）
+func collectGoTestRefs(content []byte, refs map[string]bool) {
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(content))
+	var s scanner.Scanner
+	s.Init(file, content, nil, 0)
+	for {
+		_, tok, lit := s.Scan()
+		if tok == token.EOF {
+			return
+		}
+		if tok != token.IDENT {
+			continue
+		}
+		refs[lit] = true
+		for _, prefix := range goTestPrefixes {
+			if rest, ok := strings.CutPrefix(lit, prefix); ok {
+				for _, part := range strings.Split(rest, "_") {
+					refs[part] = true
+				}
+			}
+		}
+	}
+}
+
+// javaTestSuffixes 测试类名的后缀，去掉后缀后为被测类名
+var javaTestSuffixes = []string{"Tests", "Test", "IT", "TestCase"}
+
+// javaTestRefs Java测试对方法的引用，按包和类区分，避免不相关的同名方法被视为已测试
+type javaTestRefs struct {
+	// packages 包名 -> 该包的测试中调用的方法名
+	packages map[string]map[string]bool
+	// classes 类名 -> 以该类为被测类的测试中调用的方法名，以及以该类型为接收者调用的方法名
+	classes map[string]map[string]bool
+}
+
+func newJavaTestRefs() *javaTestRefs {
+	return &javaTestRefs{
+		packages: make(map[string]map[string]bool),
+		classes:  make(map[string]map[string]bool),
+	}
+}
+
+// tested 判断包pkg中类owner的方法name是否被测试引用
+func (r *javaTestRefs) tested(pkg, owner, name string) bool {
+	return r.packages[pkg][name] || (owner != "" && r.classes[owner][name])
+}
+
+func addRef(refs map[string]map[string]bool, key, name string) {
+	if refs[key] == nil {
+		refs[key] = make(map[string]bool)
+	}
+	refs[key][name] = true
+}
+
+// collect 收集包pkg中一个测试文件的方法调用、方法引用和对象创建，
+// testXxx形式的测试方法名视为调用了被测类的xxx方法
+func (r *javaTestRefs) collect(pkg string, file *java.File) {
+	var targets []string
+	for _, t := range file.Types {
+		if target := javaTestTarget(t.TypeName()); target != "" {
+			targets = append(targets, target)
+		}
+	}
+	vars := javaVarTypes(file)
+	// 接收者类型已知时只计入该类型，否则计入被测类
+	add := func(typ, name string) {
+		addRef(r.packages, pkg, name)
+		if typ != "" {
+			addRef(r.classes, typ, name)
+			return
+		}
+		for _, target := range targets {
+			addRef(r.classes, target, name)
+		}
+	}
+
+	java.Inspect(file, func(n java.Node) bool {
+		switch n := n.(type) {
+		case *java.CallExpr:
+			add(javaExprType(n.X, vars), n.Name.Name)
+		case *java.MethodRef:
+			typ := ""
+			if t, ok := n.X.(*java.Type); ok {
+				typ = javaSimpleName(t.Name)
+			} else if x, ok := n.X.(java.Expr); ok {
+				typ = javaExprType(x, vars)
+			}
+			if name := n.Name.Name; name != "new" {
+				add(typ, name)
+			} else if typ != "" {
+				add(typ, typ)
+			}
+		case *java.NewExpr:
+			typ := javaSimpleName(n.Type.Name)
+			add(typ, typ)
+		case *java.MethodDecl:
+			if rest, ok := strings.CutPrefix(n.Name.Name, "test"); ok && rest != "" {
+				runes := []rune(rest)
+				runes[0] = unicode.ToLower(runes[0])
+				add("", string(runes))
+			}
+		}
+		return true
+	})
+}
+
+// javaTestTarget 由测试类名推断被测类名（FooTest、FooTests、FooIT、TestFoo -> Foo），不是测试类名时返回空字符串
+func javaTestTarget(name string) string {
+	for _, suffix := range javaTestSuffixes {
+		if target, ok := strings.CutSuffix(name, suffix); ok && target != "" {
+			return target
+		}
+	}
+	if target, ok := strings.CutPrefix(name, "Test"); ok && target != "" {
+		return target
+	}
+	return ""
+}
+
+// javaVarTypes 收集文件中字段、局部变量和参数的声明类型（变量名 -> 简单类型名），
+// 不区分作用域；var声明的类型取自new表达式
+func javaVarTypes(file *java.File) map[string]string {
+	vars := make(map[string]string)
+	declare := func(typ *java.Type, v *java.VarDecl) {
+		name := javaSimpleName(typ.Name)
+		if name == "var" {
+			name = ""
+			if x, ok := v.Init.(*java.NewExpr); ok {
+				name = javaSimpleName(x.Type.Name)
+			}
+		}
+		if name != "" {
+			vars[v.Name.Name] = name
+		}
+	}
+	java.Inspect(file, func(n java.Node) bool {
+		switch n := n.(type) {
+		case *java.FieldDecl:
+			for _, v := range n.Vars {
+				declare(n.Type, v)
+			}
+		case *java.LocalVarStmt:
+			for _, v := range n.Vars {
+				declare(n.Type, v)
+			}
+		case *java.Param:
+			if n.Type != nil {
+				vars[n.Name.Name] = javaSimpleName(n.Type.Name)
+			}
+		}
+		return true
+	})
+	return vars
+}
+
+// javaExprType 推断调用接收者的简单类型名：已声明的变量、this.字段、new表达式、类型转换，
+// 或首字母大写的类名；无法确定时返回空字符串
+func javaExprType(x java.Expr, vars map[string]string) string {
+	switch x := x.(type) {
+	case *java.Identifier:
+		if typ, ok := vars[x.Name]; ok {
+			return typ
+		}
+		if isUpperName(x.Name) {
+			return x.Name
+		}
+	case *java.SelectorExpr:
+		if _, ok := x.X.(*java.ThisExpr); ok {
+			return vars[x.Name.Name]
+		}
+		if isUpperName(x.Name.Name) {
+			return x.Name.Name
+		}
+	case *java.NewExpr:
+		return javaSimpleName(x.Type.Name)
+	case *java.CastExpr:
+		return javaSimpleName(x.Types[0].Name)
+	case *java.ParenExpr:
+		return javaExprType(x.X, vars)
+	}
+	return ""
+}
+
+// javaSimpleName 返回限定名的最后一段
+func javaSimpleName(name string) string {
+	return name[strings.LastIndexByte(name, '.')+1:]
+}
+
+func isUpperName(name string) bool {
+	r := []rune(name)
+	return len(r) > 0 && unicode.IsUpper(r[0])
+}
@@ -0,0 +1,111 @@
+package analyzer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestAnalyzeTestsJavaScope(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"src/main/java/com/a/Foo.java": `package com.a;
+
+public class Foo {
+    public void run() {
+    }
+
+    public void stop() {
+    }
+
+    public void reset() {
+    }
+}
+`,
+		"src/main/java/com/b/Bar.java": `package com.b;
+
+public class Bar {
+    public void run() {
+    }
+
+    public void stop() {
+    }
+}
+`,
+		"src/main/java/com/b/Qux.java": `package com.b;
+
+public class Qux {
+    public void run() {
+    }
+
+    public static void parse() {
+    }
+}
+`,
+		"src/test/java/com/t/FooTest.java": `package com.t;
+
+import com.a.Foo;
+import com.b.Bar;
+import com.b.Qux;
+
+public class FooTest {
+    private final Bar bar = new Bar();
+
+    public void testReset() {
+    }
+
+    public void testRun() {
+        Foo foo = new Foo();
+        foo.run();
+        bar.stop();
+        Qux.parse();
+    }
+}
+`,
+	})
+
+	opts := DefaultOptions()
+	// 分析完成后删除文件，测试统计只能使用分析时保留的源码
+	opts.Progress = func(path string, done, total int) {
+		if err := os.Remove(path); err != nil {
+			t.Error(err)
+		}
+	}
+	report, err := NewCodeAnalyzerWithOptions(opts).AnalyzeDirectory(context.Background(), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var untested []string
+	for _, p := range report.Tests {
+		for _, api := range p.Untested {
+			untested = append(untested, api.Name)
+		}
+	}
+	sort.Strings(untested)
+	// Foo.run、Foo.reset由FooTest覆盖，Bar.stop、Qux.parse以接收者类型限定，
+	// 其余同名方法不因名称相同被视为已测试
+	want := []string{"Bar.run", "Foo.stop", "Qux.run"}
+	if len(untested) != len(want) {
+		t.Fatalf("untested = %v, want %v", untested, want)
+	}
+	for i := range want {
+		if untested[i] != want[i] {
+			t.Fatalf("untested = %v, want %v", untested, want)
+		}
+	}
+}
@@ -0,0 +1,492 @@
+// Package apidiff 对比Java公开API的两个版本（源码目录或JAR包），按向后兼容性把变更分为破坏性和非破坏性，
+// 对应检查清单4.1.1.2“变更修改应该向后兼容，不破坏现有功能”
+package apidiff
+
+import (
+	"context"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/liujinliang/lang-checker/internal/sdk"
+)
+
+// Checklist 对应的检查清单条目
+const Checklist = "4.1.1.2"
+
+// ChecklistDoc 检查清单条目所在的文档
+const ChecklistDoc = "reviews/java-code-review-chapters/4.1-requirement-design-check.md"
+
+// 变更类型
+const (
+	ClassAdded            = "class-added"
+	ClassRemoved          = "class-removed"
+	ClassKindChanged      = "class-kind-changed"
+	SuperclassChanged     = "superclass-changed"
+	InterfaceAdded        = "interface-added"
+	InterfaceRemoved      = "interface-removed"
+	TypeParamsChanged     = "type-parameters-changed"
+	EnumConstantAdded     = "enum-constant-added"
+	EnumConstantRemoved   = "enum-constant-removed"
+	ConstructorAdded      = "constructor-added"
+	ConstructorRemoved    = "constructor-removed"
+	MethodAdded           = "method-added"
+	MethodRemoved         = "method-removed"
+	AbstractMethodAdded   = "abstract-method-added"
+	ReturnTypeChanged     = "return-type-changed"
+	ExceptionAdded        = "exception-added"
+	ExceptionRemoved      = "exception-removed"
+	FieldAdded            = "field-added"
+	FieldRemoved          = "field-removed"
+	FieldTypeChanged      = "field-type-changed"
+	ConstantChanged       = "constant-value-changed"
+	VisibilityNarrowed    = "visibility-narrowed"
+	VisibilityWidened     = "visibility-widened"
+	FinalAdded            = "final-added"
+	FinalRemoved          = "final-removed"
+	AbstractAdded         = "abstract-added"
+	AbstractRemoved       = "abstract-removed"
+	StaticChanged         = "static-changed"
+	AnnotationDefaultLost = "annotation-default-removed"
+)
+
+// Change 一项API变更
+type Change struct {
+	Kind     string `json:"kind"`
+	Breaking bool   `json:"breaking"`
+	// Class 类型的全限定名
+	Class string `json:"class"`
+	// Member 成员，方法和构造器为 名称(擦除后的参数类型)，字段为字段名；类型本身的变更为空
+	Member  string `json:"member,omitempty"`
+	Message string `json:"message"`
+	Old     string `json:"old,omitempty"`
+	New     string `json:"new,omitempty"`
+}
+
+// Report 两个版本的对比结果，破坏性变更排在前面
+type Report struct {
+	Old         string   `json:"old"`
+	New         string   `json:"new"`
+	Checklist   string   `json:"checklist"`
+	Breaking    int      `json:"breaking"`
+	NonBreaking int      `json:"nonBreaking"`
+	Changes     []Change `json:"changes"`
+	// Errors 两个版本中无法解析而跳过的文件
+	Errors []string `json:"errors,omitempty"`
+}
+
+// Load 读取一个版本的公开API：.jar按字节码提取，其他路径按Java源码目录或文件提取
+func Load(ctx context.Context, path string, maxSize int64) (*sdk.API, error) {
+	if strings.EqualFold(filepath.Ext(path), ".jar") {
+		return sdk.Extract(ctx, path, maxSize)
+	}
+	return sdk.ExtractSource(ctx, path, maxSize)
+}
+
+// Compare 对比旧版本和新版本的公开API。删除或改变已有的类型、方法和字段，缩小可见性，增加抛出的异常，
+// 以及给可被实现或继承的类型增加抽象方法都是破坏性变更；新增和放宽属于非破坏性变更
+func Compare(oldAPI, newAPI *sdk.API) *Report {
+	r := &Report{Old: oldAPI.JarInfo.Name, New: newAPI.JarInfo.Name, Checklist: Checklist}
+	for _, e := range oldAPI.Errors {
+		r.Errors = append(r.Errors, oldAPI.JarInfo.Name+": "+e)
+	}
+	for _, e := range newAPI.Errors {
+		r.Errors = append(r.Errors, newAPI.JarInfo.Name+": "+e)
+	}
+
+	oldClasses, newClasses := classIndex(oldAPI), classIndex(newAPI)
+	d := &differ{}
+	for _, name := range unionKeys(oldClasses, newClasses) {
+		o, n := oldClasses[name], newClasses[name]
+		switch {
+		case o == nil:
+			// 外层类型也是新增的，只报告外层类型
+			if outer := outerName(name); outer == "" || oldClasses[outer] != nil || newClasses[outer] == nil {
+				d.add(n.ClassName, "", ClassAdded, false, "新增公开类型", "", typeDecl(n))
+			}
+		case n == nil:
+			if outer := outerName(name); outer == "" || newClasses[outer] != nil || oldClasses[outer] == nil {
+				d.add(o.ClassName, "", ClassRemoved, true, "删除了公开类型，或类型不再对包外可见", typeDecl(o), "")
+			}
+		default:
+			d.compareClass(o, n)
+		}
+	}
+
+	sort.SliceStable(d.changes, func(i, j int) bool { return d.changes[i].Breaking && !d.changes[j].Breaking })
+	r.Changes = d.changes
+	for _, c := range r.Changes {
+		if c.Breaking {
+			r.Breaking++
+		} else {
+			r.NonBreaking++
+		}
+	}
+	return r
+}
+
+func classIndex(api *sdk.API) map[string]*sdk.Class {
+	index := make(map[string]*sdk.Class)
+	for _, c := range api.Classes() {
+		index[c.ClassName] = c
+	}
+	return index
+}
+
+func unionKeys[V any](a, b map[string]V) []string {
+	var keys []string
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// outerName 返回内部类型的外层类型名，按首字母大写的段判断，顶层类型返回空
+func outerName(name string) string {
+	i := strings.LastIndexByte(name, '.')
+	if i < 0 {
+		return ""
+	}
+	outer := name[:i]
+	last := outer[strings.LastIndexByte(outer, '.')+1:]
+	if last == "" || last[0] < 'A' || last[0] > 'Z' {
+		return ""
+	}
+	return outer
+}
+
+type differ struct {
+	changes []Change
+}
+
+func (d *differ) add(class, member, kind string, breaking bool, message, old, new string) {
+	d.changes = append(d.changes, Change{
+		Kind: kind, Breaking: breaking, Class: class, Member: member, Message: message, Old: old, New: new,
+	})
+}
+
+func (d *differ) compareClass(o, n *sdk.Class) {
+	name := o.ClassName
+	if o.ClassType != n.ClassType {
+		d.add(name, "", ClassKindChanged, true, "类型种类由"+o.ClassType+"改为"+n.ClassType, o.ClassType, n.ClassType)
+		return
+	}
+	d.compareModifiers(name, "", "类型", o.Modifiers, n.Modifiers, "已有的子类无法编译")
+	switch oldAbstract, newAbstract := slices.Contains(o.Modifiers, "abstract"), slices.Contains(n.Modifiers, "abstract"); {
+	case !oldAbstract && newAbstract:
+		d.add(name, "", AbstractAdded, true, "类型增加了abstract，已有的实例化代码无法编译", "", "abstract")
+	case oldAbstract && !newAbstract:
+		d.add(name, "", AbstractRemoved, false, "类型去掉了abstract", "abstract", "")
+	}
+
+	if o.SuperClass != n.SuperClass {
+		// 从Object改为继承其他类不影响已有调用方，失去原父类则会
+		breaking := o.SuperClass != "" && erase(o.SuperClass, nil) != erase(n.SuperClass, nil)
+		d.add(name, "", SuperclassChanged, breaking, "父类变更", o.SuperClass, n.SuperClass)
+	}
+	oldIfaces, newIfaces := erasedSet(o.Interfaces), erasedSet(n.Interfaces)
+	for _, i := range o.Interfaces {
+		if !newIfaces[erase(i, nil)] {
+			d.add(name, "", InterfaceRemoved, true, "不再实现或继承接口 "+i, i, "")
+		}
+	}
+	for _, i := range n.Interfaces {
+		if !oldIfaces[erase(i, nil)] {
+			d.add(name, "", InterfaceAdded, false, "新增实现或继承的接口 "+i, "", i)
+		}
+	}
+	if o.TypeParams != n.TypeParams {
+		// 原始类型加上泛型参数对已有调用方兼容，改变已有的类型参数会导致源码编译失败
+		d.add(name, "", TypeParamsChanged, o.TypeParams != "", "类型参数变更", o.TypeParams, n.TypeParams)
+	}
+
+	for _, c := range o.EnumConstants {
+		if !slices.Contains(n.EnumConstants, c) {
+			d.add(name, c, EnumConstantRemoved, true, "删除了枚举常量", c, "")
+		}
+	}
+	for _, c := range n.EnumConstants {
+		if !slices.Contains(o.EnumConstants, c) {
+			d.add(name, c, EnumConstantAdded, false, "新增枚举常量", "", c)
+		}
+	}
+
+	d.compareFields(o, n)
+	d.compareMethods(o, n, o.Constructors, n.Constructors, true)
+	d.compareMethods(o, n, o.Methods, n.Methods, false)
+}
+
+// compareModifiers 对比可见性和final、static修饰符。finalEffect为增加final造成的影响，
+// 为空表示增加final不影响已有代码（如最终类中的方法）
+func (d *differ) compareModifiers(class, member, what string, o, n []string, finalEffect string) {
+	switch ov, nv := visibility(o), visibility(n); {
+	case nv < ov:
+		d.add(class, member, VisibilityNarrowed, true, what+"的可见性由"+visibilityName(ov)+"缩小为"+visibilityName(nv),
+			visibilityName(ov), visibilityName(nv))
+	case nv > ov:
+		d.add(class, member, VisibilityWidened, false, what+"的可见性由"+visibilityName(ov)+"放宽为"+visibilityName(nv),
+			visibilityName(ov), visibilityName(nv))
+	}
+	oldFinal, newFinal := slices.Contains(o, "final"), slices.Contains(n, "final")
+	switch {
+	case !oldFinal && newFinal:
+		d.add(class, member, FinalAdded, finalEffect != "", strings.TrimSuffix(what+"增加了final，"+finalEffect, "，"), "", "final")
+	case oldFinal && !newFinal:
+		d.add(class, member, FinalRemoved, false, what+"去掉了final", "final", "")
+	}
+	oldStatic, newStatic := slices.Contains(o, "static"), slices.Contains(n, "static")
+	if oldStatic != newStatic {
+		d.add(class, member, StaticChanged, true, what+"的static修饰符变更，已编译的调用方会出现链接错误", modifierName(oldStatic, "static"), modifierName(newStatic, "static"))
+	}
+}
+
+func (d *differ) compareFields(o, n *sdk.Class) {
+	newFields := make(map[string]sdk.Field, len(n.Fields))
+	for _, f := range n.Fields {
+		newFields[f.FieldName] = f
+	}
+	oldFields := make(map[string]bool, len(o.Fields))
+	for _, of := range o.Fields {
+		oldFields[of.FieldName] = true
+		nf, ok := newFields[of.FieldName]
+		if !ok {
+			d.add(o.ClassName, of.FieldName, FieldRemoved, true, "删除了字段，或字段不再对包外可见", fieldDecl(of), "")
+			continue
+		}
+		if of.Type != nf.Type {
+			d.add(o.ClassName, of.FieldName, FieldTypeChanged, true, "字段类型变更", of.Type, nf.Type)
+		}
+		d.compareModifiers(o.ClassName, of.FieldName, "字段", of.Modifiers, nf.Modifiers, "已有的赋值无法编译")
+		if of.DefaultValue != "" && nf.DefaultValue != "" && of.DefaultValue != nf.DefaultValue {
+			d.add(o.ClassName, of.FieldName, ConstantChanged, false, "常量值变更，已编译的调用方内联了旧值，需要重新编译",
+				of.DefaultValue, nf.DefaultValue)
+		}
+	}
+	for _, nf := range n.Fields {
+		if !oldFields[nf.FieldName] {
+			d.add(n.ClassName, nf.FieldName, FieldAdded, false, "新增字段", "", fieldDecl(nf))
+		}
+	}
+}
+
+func (d *differ) compareMethods(oc, nc *sdk.Class, oldMethods, newMethods []sdk.Method, constructor bool) {
+	what, removed, added := "方法", MethodRemoved, MethodAdded
+	if constructor {
+		what, removed, added = "构造方法", ConstructorRemoved, ConstructorAdded
+	}
+	newByKey := make(map[string]sdk.Method, len(newMethods))
+	for _, m := range newMethods {
+		newByKey[methodKey(nc, m)] = m
+	}
+	oldKeys := make(map[string]bool, len(oldMethods))
+	for _, om := range oldMethods {
+		key := methodKey(oc, om)
+		oldKeys[key] = true
+		nm, ok := newByKey[key]
+		if !ok {
+			d.add(oc.ClassName, key, removed, true, "删除了"+what+"或改变了参数，或"+what+"不再对包外可见", om.Signature, "")
+			continue
+		}
+		d.compareMethod(oc, nc, key, what, om, nm)
+	}
+	for _, nm := range newMethods {
+		key := methodKey(nc, nm)
+		if oldKeys[key] {
+			continue
+		}
+		// 给接口或可继承的抽象类增加抽象方法会使已有实现类无法编译
+		if !constructor && isAbstract(nc, nm) && !slices.Contains(nc.Modifiers, "final") {
+			d.add(nc.ClassName, key, AbstractMethodAdded, true, "新增抽象方法，已有的实现类需要实现它", "", nm.Signature)
+			continue
+		}
+		d.add(nc.ClassName, key, added, false, "新增"+what, "", nm.Signature)
+	}
+}
+
+func (d *differ) compareMethod(oc, nc *sdk.Class, key, what string, om, nm sdk.Method) {
+	class := oc.ClassName
+	if renameTypeVars(om.ReturnType, om.TypeParams) != renameTypeVars(nm.ReturnType, nm.TypeParams) {
+		d.add(class, key, ReturnTypeChanged, true, "返回类型变更", om.ReturnType, nm.ReturnType)
+	}
+	// 最终类中的方法和构造器本来就不能重写，增加final不影响已有代码
+	finalEffect := "已有的重写无法编译"
+	if slices.Contains(nc.Modifiers, "final") || what == "构造方法" {
+		finalEffect = ""
+	}
+	d.compareModifiers(class, key, what, om.Modifiers, nm.Modifiers, finalEffect)
+	if oldAbs, newAbs := isAbstract(oc, om), isAbstract(nc, nm); !oldAbs && newAbs {
+		d.add(class, key, AbstractAdded, true, what+"改为抽象方法，已有的实现类需要实现它", om.Signature, nm.Signature)
+	} else if oldAbs && !newAbs {
+		d.add(class, key, AbstractRemoved, false, what+"改为有默认实现", om.Signature, nm.Signature)
+	}
+	if om.DefaultValue != "" && nm.DefaultValue == "" && oc.ClassType == "annotation" {
+		d.add(class, key, AnnotationDefaultLost, true, "注解元素去掉了默认值，已有的用法需要显式赋值", om.DefaultValue, "")
+	}
+
+	oldExc, newExc := erasedSet(om.Exceptions), erasedSet(nm.Exceptions)
+	for _, e := range nm.Exceptions {
+		if !oldExc[erase(e, nil)] {
+			d.add(class, key, ExceptionAdded, true, what+"声明了新的异常 "+e+"，已有调用方需要处理", "", e)
+		}
+	}
+	for _, e := range om.Exceptions {
+		if !newExc[erase(e, nil)] {
+			// 对已编译的调用方兼容，但捕获该受检异常的源码会因“异常不会被抛出”而无法编译
+			d.add(class, key, ExceptionRemoved, false, what+"不再声明异常 "+e+"，捕获该受检异常的调用方需要调整", e, "")
+		}
+	}
+}
+
+// isAbstract 判断方法是否为抽象方法：类中带abstract的方法，接口中没有default和static的方法，注解中没有默认值的元素
+func isAbstract(c *sdk.Class, m sdk.Method) bool {
+	switch c.ClassType {
+	case "interface":
+		return !slices.Contains(m.Modifiers, "default") && !slices.Contains(m.Modifiers, "static")
+	case "annotation":
+		return m.DefaultValue == ""
+	}
+	return slices.Contains(m.Modifiers, "abstract")
+}
+
+// methodKey 按方法名和擦除后的参数类型标识方法，如 find(java.lang.String,java.util.List)
+func methodKey(c *sdk.Class, m sdk.Method) string {
+	vars := typeVarBounds(c.TypeParams)
+	for k, v := range typeVarBounds(m.TypeParams) {
+		vars[k] = v
+	}
+	params := make([]string, len(m.Parameters))
+	for i, p := range m.Parameters {
+		params[i] = erase(p.Type, vars)
+	}
+	return m.MethodName + "(" + strings.Join(params, ",") + ")"
+}
+
+// erase 擦除类型中的泛型实参，类型参数替换为其第一个上界（没有上界时为Object），可变参数按数组处理
+func erase(t string, vars map[string]string) string {
+	t = strings.TrimSpace(t)
+	dims := ""
+	for {
+		if rest, ok := strings.CutSuffix(t, "[]"); ok {
+			t, dims = rest, dims+"[]"
+		} else if rest, ok := strings.CutSuffix(t, "..."); ok {
+			t, dims = rest, dims+"[]"
+		} else {
+			break
+		}
+	}
+	if i := strings.IndexByte(t, '<'); i >= 0 {
+		t = t[:i]
+	}
+	if bound, ok := vars[t]; ok {
+		t = bound
+	}
+	return t + dims
+}
+
+// splitTypeParams 把 <T extends A<B, C>, U> 拆为 [T extends A<B, C>, U]
+func splitTypeParams(params string) []string {
+	params = strings.TrimSpace(params)
+	if len(params) < 2 || params[0] != '<' {
+		return nil
+	}
+	depth, start := 0, 1
+	var parts []string
+	for i := 1; i < len(params)-1; i++ {
+		switch params[i] {
+		case '<':
+			depth++
+		case '>':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, strings.TrimSpace(params[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, strings.TrimSpace(params[start:len(params)-1]))
+}
+
+// renameTypeVars 把类型中方法自己的类型参数按位置改名，使 <T> T 与 <U> U 视为相同
+func renameTypeVars(t, params string) string {
+	for i, p := range splitTypeParams(params) {
+		name, _, _ := strings.Cut(p, " ")
+		re := regexp.MustCompile(`\b` + regexp.QuoteMeta(name) + `\b`)
+		t = re.ReplaceAllString(t, "#"+strconv.Itoa(i))
+	}
+	return t
+}
+
+// typeVarBounds 解析 <T extends A & B, U> 形式的类型参数，返回类型参数到擦除后上界的映射
+func typeVarBounds(params string) map[string]string {
+	vars := make(map[string]string)
+	for _, p := range splitTypeParams(params) {
+		name, bound, ok := strings.Cut(p, " extends ")
+		if !ok {
+			vars[name] = "java.lang.Object"
+			continue
+		}
+		bound, _, _ = strings.Cut(bound, " & ")
+		vars[name] = erase(bound, nil)
+	}
+	// 上界引用其他类型参数时再擦除一次，如 <T, U extends T>
+	for name, bound := range vars {
+		if b, ok := vars[bound]; ok {
+			vars[name] = b
+		}
+	}
+	return vars
+}
+
+func erasedSet(types []string) map[string]bool {
+	set := make(map[string]bool, len(types))
+	for _, t := range types {
+		set[erase(t, nil)] = true
+	}
+	return set
+}
+
+// visibility 可见性等级：public为2，protected为1，其他（已不在公开API中）为0
+func visibility(mods []string) int {
+	switch {
+	case slices.Contains(mods, "public"):
+		return 2
+	case slices.Contains(mods, "protected"):
+		return 1
+	}
+	return 0
+}
+
+func visibilityName(v int) string {
+	switch v {
+	case 2:
+		return "public"
+	case 1:
+		return "protected"
+	}
+	return "包内可见"
+}
+
+func modifierName(has bool, name string) string {
+	if has {
+		return name
+	}
+	return "非" + name
+}
+
+func typeDecl(c *sdk.Class) string {
+	return strings.TrimSpace(strings.Join(c.Modifiers, " ") + " " + c.ClassType + " " + c.ClassName + c.TypeParams)
+}
+
+func fieldDecl(f sdk.Field) string {
+	return strings.TrimSpace(strings.Join(f.Modifiers, " ") + " " + f.Type + " " + f.FieldName)
+}
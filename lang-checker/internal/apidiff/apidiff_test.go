@@ -0,0 +1,151 @@
+package apidiff
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// extract 把Java源码写入dir下的Api.java，返回文件路径
+func extract(t *testing.T, dir, src string) string {
+	t.Helper()
+	path := filepath.Join(dir, "Api.java")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("package com.example;\n\n"+src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new string
+		kind     string
+		breaking bool
+	}{
+		{"删除方法",
+			"public class Api { public void a() {} public void b() {} }",
+			"public class Api { public void a() {} }",
+			MethodRemoved, true},
+		{"新增方法",
+			"public class Api { public void a() {} }",
+			"public class Api { public void a() {} public void b() {} }",
+			MethodAdded, false},
+		{"接口新增抽象方法",
+			"public interface Api { void a(); }",
+			"public interface Api { void a(); void b(); }",
+			AbstractMethodAdded, true},
+		{"接口新增默认方法",
+			"public interface Api { void a(); }",
+			"public interface Api { void a(); default void b() {} }",
+			MethodAdded, false},
+		{"修改返回类型",
+			"public class Api { public int size() { return 0; } }",
+			"public class Api { public long size() { return 0; } }",
+			ReturnTypeChanged, true},
+		{"修改参数类型",
+			"public class Api { public void put(int v) {} }",
+			"public class Api { public void put(long v) {} }",
+			MethodRemoved, true},
+		{"增加受检异常",
+			"public class Api { public void read() {} }",
+			"public class Api { public void read() throws java.io.IOException {} }",
+			ExceptionAdded, true},
+		{"减少受检异常",
+			"public class Api { public void read() throws java.io.IOException {} }",
+			"public class Api { public void read() {} }",
+			ExceptionRemoved, false},
+		{"缩小可见性",
+			"public class Api { public void a() {} }",
+			"public class Api { protected void a() {} }",
+			VisibilityNarrowed, true},
+		{"放宽可见性",
+			"public class Api { protected void a() {} }",
+			"public class Api { public void a() {} }",
+			VisibilityWidened, false},
+		{"类增加final",
+			"public class Api {}",
+			"public final class Api {}",
+			FinalAdded, true},
+		// 二进制兼容，但调用方内联了旧值，只提示重新编译
+		{"修改常量值",
+			"public class Api { public static final int MAX = 10; }",
+			"public class Api { public static final int MAX = 20; }",
+			ConstantChanged, false},
+		{"删除字段",
+			"public class Api { public int count; }",
+			"public class Api {}",
+			FieldRemoved, true},
+		{"删除枚举常量",
+			"public enum Api { A, B }",
+			"public enum Api { A }",
+			EnumConstantRemoved, true},
+		{"新增枚举常量",
+			"public enum Api { A }",
+			"public enum Api { A, B }",
+			EnumConstantAdded, false},
+		{"类改为接口",
+			"public class Api {}",
+			"public interface Api {}",
+			ClassKindChanged, true},
+		{"类型不再公开",
+			"public class Api {}",
+			"class Api {}",
+			ClassRemoved, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			oldAPI, err := Load(context.Background(), extract(t, filepath.Join(dir, "old"), tt.old), 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			newAPI, err := Load(context.Background(), extract(t, filepath.Join(dir, "new"), tt.new), 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			r := Compare(oldAPI, newAPI)
+			found := false
+			for _, c := range r.Changes {
+				if c.Kind == tt.kind {
+					found = true
+					if c.Breaking != tt.breaking {
+						t.Errorf("%s: breaking = %v, want %v", c.Kind, c.Breaking, tt.breaking)
+					}
+				}
+			}
+			if !found {
+				t.Errorf("未报告%s: %+v", tt.kind, r.Changes)
+			}
+			if tt.breaking != (r.Breaking > 0) {
+				t.Errorf("Breaking = %d, NonBreaking = %d: %+v", r.Breaking, r.NonBreaking, r.Changes)
+			}
+		})
+	}
+}
+
+func TestCompareUnchanged(t *testing.T) {
+	src := `public class Api<T> {
+    public static final String NAME = "api";
+    protected int count;
+    public Api() {}
+    public <R> R map(java.util.function.Function<T, R> f) throws java.io.IOException { return null; }
+}`
+	dir := t.TempDir()
+	oldAPI, err := Load(context.Background(), extract(t, filepath.Join(dir, "old"), src), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 只修改实现和格式
+	newAPI, err := Load(context.Background(), extract(t, filepath.Join(dir, "new"), src+"\n// 注释\n"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := Compare(oldAPI, newAPI); len(r.Changes) != 0 {
+		t.Errorf("changes = %+v, want none", r.Changes)
+	}
+}
@@ -0,0 +1,51 @@
+package apidiff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Text 按破坏性和非破坏性分组输出对比结果
+func Text(r *Report) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "API兼容性对比（检查清单%s 变更修改应该向后兼容，见%s）\n", r.Checklist, ChecklistDoc)
+	fmt.Fprintf(&b, "旧版本: %s\n新版本: %s\n", r.Old, r.New)
+	fmt.Fprintf(&b, "破坏性变更: %d, 非破坏性变更: %d\n", r.Breaking, r.NonBreaking)
+	if len(r.Changes) == 0 {
+		b.WriteString("\n公开API没有变化\n")
+	}
+
+	writeGroup := func(title string, breaking bool) {
+		first := true
+		for _, c := range r.Changes {
+			if c.Breaking != breaking {
+				continue
+			}
+			if first {
+				fmt.Fprintf(&b, "\n%s:\n", title)
+				first = false
+			}
+			target := c.Class
+			if c.Member != "" {
+				target += "#" + c.Member
+			}
+			fmt.Fprintf(&b, "- [%s] %s: %s\n", c.Kind, target, c.Message)
+			if c.Old != "" {
+				fmt.Fprintf(&b, "    旧: %s\n", c.Old)
+			}
+			if c.New != "" {
+				fmt.Fprintf(&b, "    新: %s\n", c.New)
+			}
+		}
+	}
+	writeGroup("破坏性变更", true)
+	writeGroup("非破坏性变更", false)
+
+	if len(r.Errors) > 0 {
+		b.WriteString("\n未能解析的文件（相关API未参与对比）:\n")
+		for _, e := range r.Errors {
+			fmt.Fprintf(&b, "- %s\n", e)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,166 @@
+package classfile
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Annotation 注解，Type为注解类型的描述符（如 Ljavax/annotation/Nonnull;）
+type Annotation struct {
+	Type string
+	// Visible 运行时可见（RetentionPolicy.RUNTIME）
+	Visible  bool
+	Elements []Element
+}
+
+// Element 注解的一个元素
+type Element struct {
+	Name  string
+	Value ElementValue
+}
+
+// ElementValue 注解元素值，Tag为元素值类型：
+// B C D F I J S Z s 为常量（Const），e 为枚举（EnumType、Const为常量名），
+// c 为类字面量（Class为返回类型描述符），@ 为嵌套注解，[ 为数组
+type ElementValue struct {
+	Tag        byte
+	Const      string
+	EnumType   string
+	Class      string
+	Annotation *Annotation
+	Array      []ElementValue
+}
+
+// TypeName 返回注解类型的全限定名，如 javax.annotation.Nonnull
+func (a *Annotation) TypeName() string {
+	return JavaName(strings.TrimSuffix(strings.TrimPrefix(a.Type, "L"), ";"))
+}
+
+// String 按源码形式输出注解，如 @Size(max=10)
+func (a *Annotation) String() string {
+	var b strings.Builder
+	b.WriteString("@" + a.TypeName())
+	if len(a.Elements) > 0 {
+		b.WriteByte('(')
+		for i, e := range a.Elements {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			if len(a.Elements) > 1 || e.Name != "value" {
+				b.WriteString(e.Name + "=")
+			}
+			b.WriteString(e.Value.String())
+		}
+		b.WriteByte(')')
+	}
+	return b.String()
+}
+
+// String 按源码形式输出元素值
+func (v ElementValue) String() string {
+	switch v.Tag {
+	case 's':
+		return strconv.Quote(v.Const)
+	case 'C':
+		return strconv.QuoteRune(rune(atoi(v.Const)))
+	case 'Z':
+		return strconv.FormatBool(v.Const != "0")
+	case 'J':
+		return v.Const + "L"
+	case 'F':
+		return v.Const + "f"
+	case 'e':
+		return descriptorType(v.EnumType) + "." + v.Const
+	case 'c':
+		return descriptorType(v.Class) + ".class"
+	case '@':
+		return v.Annotation.String()
+	case '[':
+		parts := make([]string, len(v.Array))
+		for i, e := range v.Array {
+			parts[i] = e.String()
+		}
+		return "{" + strings.Join(parts, ", ") + "}"
+	}
+	return v.Const
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+// descriptorType 把字段描述符转为源码形式的类型名，无法解析时原样返回
+func descriptorType(desc string) string {
+	if t, err := ParseFieldSignature(desc); err == nil {
+		return t
+	}
+	return desc
+}
+
+func (p *parser) annotations(r *reader, visible bool) []*Annotation {
+	var list []*Annotation
+	for n := r.u2(); n > 0 && r.err == nil; n-- {
+		list = append(list, p.annotation(r, visible))
+	}
+	return list
+}
+
+func (p *parser) annotation(r *reader, visible bool) *Annotation {
+	a := &Annotation{Type: p.utf8(r.u2()), Visible: visible}
+	for n := r.u2(); n > 0 && r.err == nil; n-- {
+		name := p.utf8(r.u2())
+		a.Elements = append(a.Elements, Element{Name: name, Value: p.elementValue(r, visible)})
+	}
+	return a
+}
+
+func (p *parser) elementValue(r *reader, visible bool) ElementValue {
+	v := ElementValue{Tag: r.u1()}
+	switch v.Tag {
+	case 'B', 'C', 'I', 'S', 'Z', 'J':
+		v.Const = strconv.FormatInt(p.constInt(r.u2()), 10)
+	case 'D', 'F':
+		v.Const = strconv.FormatFloat(p.constFloat(r.u2()), 'g', -1, 64)
+	case 's':
+		v.Const = p.utf8(r.u2())
+	case 'e':
+		v.EnumType = p.utf8(r.u2())
+		v.Const = p.utf8(r.u2())
+	case 'c':
+		v.Class = p.utf8(r.u2())
+	case '@':
+		v.Annotation = p.annotation(r, visible)
+	case '[':
+		for n := r.u2(); n > 0 && r.err == nil; n-- {
+			v.Array = append(v.Array, p.elementValue(r, visible))
+		}
+	default:
+		if r.err == nil {
+			p.fail(fmt.Errorf("%w: 未知的注解元素类型%q", ErrFormat, v.Tag))
+			r.err = p.err
+		}
+	}
+	return v
+}
+
+func (p *parser) constInt(i uint16) int64 {
+	if int(i) > 0 && int(i) < len(p.pool) {
+		if c := p.pool[i]; c.Tag == TagInteger || c.Tag == TagLong {
+			return c.Int
+		}
+	}
+	p.fail(fmt.Errorf("%w: 常量池下标%d不是整数常量", ErrFormat, i))
+	return 0
+}
+
+func (p *parser) constFloat(i uint16) float64 {
+	if int(i) > 0 && int(i) < len(p.pool) {
+		if c := p.pool[i]; c.Tag == TagFloat || c.Tag == TagDouble {
+			return c.Float
+		}
+	}
+	p.fail(fmt.Errorf("%w: 常量池下标%d不是浮点常量", ErrFormat, i))
+	return 0
+}
@@ -0,0 +1,179 @@
+package classfile
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// 分析用到的操作码
+const (
+	OpIfeq            = 0x99
+	OpIfAcmpne        = 0xa6
+	OpTableswitch     = 0xaa
+	OpLookupswitch    = 0xab
+	OpGetstatic       = 0xb2
+	OpPutstatic       = 0xb3
+	OpGetfield        = 0xb4
+	OpPutfield        = 0xb5
+	OpInvokevirtual   = 0xb6
+	OpInvokespecial   = 0xb7
+	OpInvokestatic    = 0xb8
+	OpInvokeinterface = 0xb9
+	OpInvokedynamic   = 0xba
+	OpNew             = 0xbb
+	OpWide            = 0xc4
+	OpIfnull          = 0xc6
+	OpIfnonnull       = 0xc7
+)
+
+// opcodeLengths 定长指令的字节数（含操作码），0表示变长指令或未定义的操作码
+var opcodeLengths = func() [256]int {
+	var l [256]int
+	set := func(from, to, n int) {
+		for op := from; op <= to; op++ {
+			l[op] = n
+		}
+	}
+	set(0x00, 0x0f, 1) // nop、常量
+	l[0x10], l[0x11], l[0x12], l[0x13], l[0x14] = 2, 3, 2, 3, 3
+	set(0x15, 0x19, 2) // xload
+	set(0x1a, 0x35, 1) // xload_n、xaload
+	set(0x36, 0x3a, 2) // xstore
+	set(0x3b, 0x83, 1) // xstore_n、xastore、栈操作、算术
+	l[0x84] = 3        // iinc
+	set(0x85, 0x98, 1) // 类型转换、比较
+	set(0x99, 0xa8, 3) // 条件跳转、goto、jsr
+	l[0xa9] = 2        // ret
+	set(0xac, 0xb1, 1) // return
+	set(0xb2, 0xb8, 3) // 字段访问、方法调用
+	l[0xb9], l[0xba], l[0xbb], l[0xbc], l[0xbd] = 5, 5, 3, 2, 3
+	set(0xbe, 0xbf, 1) // arraylength、athrow
+	set(0xc0, 0xc1, 3) // checkcast、instanceof
+	set(0xc2, 0xc3, 1) // monitorenter、monitorexit
+	l[0xc5], l[0xc6], l[0xc7], l[0xc8], l[0xc9] = 4, 3, 3, 5, 5
+	l[0xca], l[0xfe], l[0xff] = 1, 1, 1
+	return l
+}()
+
+// Instructions 依次回调方法体中的每条指令，ins为含操作码和操作数的完整指令
+func (c *Code) Instructions(f func(pc int, ins []byte)) error {
+	code := c.Bytecode
+	for pc := 0; pc < len(code); {
+		n, err := instructionLength(code, pc)
+		if err != nil {
+			return err
+		}
+		f(pc, code[pc:pc+n])
+		pc += n
+	}
+	return nil
+}
+
+func instructionLength(code []byte, pc int) (int, error) {
+	op := code[pc]
+	n := opcodeLengths[op]
+	switch op {
+	case OpTableswitch, OpLookupswitch:
+		// 操作数按4字节对齐
+		base := pc + 1 + (4-(pc+1)%4)%4
+		if base+12 > len(code) {
+			return 0, fmt.Errorf("%w: 偏移%d处的switch指令不完整", ErrTruncated, pc)
+		}
+		if op == OpTableswitch {
+			low, high := int32(binary.BigEndian.Uint32(code[base+4:])), int32(binary.BigEndian.Uint32(code[base+8:]))
+			n = base - pc + 12 + int(int64(high)-int64(low)+1)*4
+		} else {
+			pairs := int(binary.BigEndian.Uint32(code[base+4:]))
+			n = base - pc + 8 + pairs*8
+		}
+	case OpWide:
+		n = 4
+		if pc+1 < len(code) && code[pc+1] == 0x84 {
+			n = 6
+		}
+	}
+	if n <= 0 {
+		return 0, fmt.Errorf("%w: 偏移%d处的操作码0x%02x未定义", ErrFormat, pc, op)
+	}
+	if pc+n > len(code) {
+		return 0, fmt.Errorf("%w: 偏移%d处的指令不完整", ErrTruncated, pc)
+	}
+	return n, nil
+}
+
+// Complexity 基于字节码估算圈复杂度：1 + 条件跳转数 + switch中非default的分支数 + catch块数。
+// 编译器会把 && 和 || 拆成多个条件跳转，因此通常略高于按源码计算的值
+func (c *Code) Complexity() (int, error) {
+	complexity := 1
+	for _, h := range c.Handlers {
+		if h.CatchType != "" {
+			complexity++
+		}
+	}
+	err := c.Instructions(func(pc int, ins []byte) {
+		switch op := ins[0]; {
+		case op >= OpIfeq && op <= OpIfAcmpne, op == OpIfnull, op == OpIfnonnull:
+			complexity++
+		case op == OpTableswitch || op == OpLookupswitch:
+			complexity += switchCases(pc, ins)
+		}
+	})
+	return complexity, err
+}
+
+// switchCases 统计跳转目标不同于default的分支数
+func switchCases(pc int, ins []byte) int {
+	base := (4 - (pc+1)%4) % 4
+	ops := ins[1+base:]
+	def := binary.BigEndian.Uint32(ops)
+	count := 0
+	if ins[0] == OpTableswitch {
+		for off := 12; off+4 <= len(ops); off += 4 {
+			if binary.BigEndian.Uint32(ops[off:]) != def {
+				count++
+			}
+		}
+		return count
+	}
+	for off := 8; off+8 <= len(ops); off += 8 {
+		if binary.BigEndian.Uint32(ops[off+4:]) != def {
+			count++
+		}
+	}
+	return count
+}
+
+// Ref 指令引用的类成员
+type Ref struct {
+	Op                      byte
+	Class, Name, Descriptor string
+}
+
+// Refs 返回方法体中的字段访问、方法调用和对象创建指令引用的成员，对象创建的Name为空
+func (c *Code) Refs(pool Pool) ([]Ref, error) {
+	var refs []Ref
+	var refErr error
+	err := c.Instructions(func(pc int, ins []byte) {
+		op := ins[0]
+		switch {
+		case op >= OpGetstatic && op <= OpInvokeinterface:
+			class, name, desc, err := pool.MemberRef(binary.BigEndian.Uint16(ins[1:]))
+			if err != nil {
+				refErr = err
+				return
+			}
+			refs = append(refs, Ref{Op: op, Class: class, Name: name, Descriptor: desc})
+		case op == OpNew:
+			class, err := pool.ClassName(binary.BigEndian.Uint16(ins[1:]))
+			if err != nil {
+				refErr = err
+				return
+			}
+			refs = append(refs, Ref{Op: op, Class: class})
+		}
+	})
+	if err == nil {
+		err = refErr
+	}
+	return refs, err
+}
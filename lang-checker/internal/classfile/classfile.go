@@ -0,0 +1,418 @@
+// Package classfile 纯Go解析Java class文件和JAR包，不依赖JVM
+package classfile
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrFormat 内容不是合法的class文件
+	ErrFormat = errors.New("class文件格式错误")
+	// ErrTruncated class文件被截断
+	ErrTruncated = errors.New("class文件不完整")
+)
+
+const magic = 0xCAFEBABE
+
+// 访问标志，类、字段和方法共用同一组取值，含义随所在位置不同
+const (
+	AccPublic       = 0x0001
+	AccPrivate      = 0x0002
+	AccProtected    = 0x0004
+	AccStatic       = 0x0008
+	AccFinal        = 0x0010
+	AccSynchronized = 0x0020 // 方法
+	AccSuper        = 0x0020 // 类
+	AccVolatile     = 0x0040 // 字段
+	AccBridge       = 0x0040 // 方法
+	AccTransient    = 0x0080 // 字段
+	AccVarargs      = 0x0080 // 方法
+	AccNative       = 0x0100
+	AccInterface    = 0x0200
+	AccAbstract     = 0x0400
+	AccStrict       = 0x0800
+	AccSynthetic    = 0x1000
+	AccAnnotation   = 0x2000
+	AccEnum         = 0x4000
+	AccModule       = 0x8000
+)
+
+// ClassFile 解析后的class文件，类名均为内部名称（如 java/util/Map$Entry）
+type ClassFile struct {
+	MinorVersion, MajorVersion uint16
+	Pool                       Pool
+	AccessFlags                uint16
+	Name                       string
+	// SuperName 父类，java/lang/Object和module-info为空
+	SuperName  string
+	Interfaces []string
+	Fields     []*Member
+	Methods    []*Member
+
+	// SourceFile SourceFile属性记录的源文件名
+	SourceFile string
+	// Signature 泛型类签名，非泛型类为空
+	Signature   string
+	Annotations []*Annotation
+	// Record 是否为record（含Record属性）
+	Record bool
+	// InnerClasses InnerClasses属性中的内部类声明，按内部名称索引
+	InnerClasses map[string]InnerClass
+}
+
+// InnerClass InnerClasses属性中的一项
+type InnerClass struct {
+	Outer string
+	// Name 源码中的简单名称，匿名类为空
+	Name        string
+	AccessFlags uint16
+}
+
+// Member 字段或方法
+type Member struct {
+	AccessFlags uint16
+	Name        string
+	Descriptor  string
+	// Signature 泛型签名，为空时以Descriptor为准
+	Signature   string
+	Annotations []*Annotation
+	// ParameterAnnotations 方法参数上的注解，按参数顺序
+	ParameterAnnotations [][]*Annotation
+	// MethodParameters MethodParameters属性记录的参数名（javac -parameters），没有该属性时为空
+	MethodParameters []string
+	// AnnotationDefault 注解类型中元素的默认值
+	AnnotationDefault *ElementValue
+	// Exceptions 方法Exceptions属性声明的受检异常
+	Exceptions []string
+	// Code 方法体，抽象方法和本地方法为nil
+	Code *Code
+	// ConstantValue 静态常量字段的初始值，其他字段为nil
+	ConstantValue any
+}
+
+// Is 判断是否设置了访问标志
+func (m *Member) Is(flag uint16) bool {
+	return m.AccessFlags&flag != 0
+}
+
+// ParameterNames 返回方法的参数名：优先取MethodParameters属性，其次按槽位从LocalVariableTable中查找，
+// 都没有或数量与描述符中的参数对不上时返回nil
+func (m *Member) ParameterNames() []string {
+	mt, err := ParseMethodSignature(m.Descriptor)
+	if err != nil {
+		return nil
+	}
+	if len(m.MethodParameters) == len(mt.Params) && len(mt.Params) > 0 {
+		return m.MethodParameters
+	}
+	if m.Code == nil || len(m.Code.Locals) == 0 {
+		return nil
+	}
+	slots := make(map[uint16]string)
+	for _, l := range m.Code.Locals {
+		// 参数在方法入口处即有效
+		if l.StartPC == 0 {
+			slots[l.Index] = l.Name
+		}
+	}
+	var slot uint16
+	if !m.Is(AccStatic) {
+		slot = 1
+	}
+	names := make([]string, len(mt.Params))
+	for i, t := range mt.Params {
+		name, ok := slots[slot]
+		if !ok {
+			return nil
+		}
+		names[i] = name
+		slot++
+		if t == "long" || t == "double" {
+			slot++
+		}
+	}
+	return names
+}
+
+// Code 方法的Code属性
+type Code struct {
+	MaxStack, MaxLocals uint16
+	Bytecode            []byte
+	Handlers            []ExceptionHandler
+	// Lines LineNumberTable，编译时未保留调试信息时为空
+	Lines []LineNumber
+	// Locals LocalVariableTable，编译时未保留调试信息时为空
+	Locals []LocalVariable
+}
+
+// LocalVariable 局部变量表中的一项，Index为局部变量槽位
+type LocalVariable struct {
+	StartPC, Length uint16
+	Name            string
+	Descriptor      string
+	Index           uint16
+}
+
+// ExceptionHandler 异常表中的一项，CatchType为空表示finally
+type ExceptionHandler struct {
+	StartPC, EndPC, HandlerPC uint16
+	CatchType                 string
+}
+
+// LineNumber 字节码偏移与源码行号的对应
+type LineNumber struct {
+	PC   uint16
+	Line int
+}
+
+// LineRange 返回方法体对应的源码行范围，没有行号信息时返回0, 0
+func (c *Code) LineRange() (start, end int) {
+	for _, l := range c.Lines {
+		if start == 0 || l.Line < start {
+			start = l.Line
+		}
+		end = max(end, l.Line)
+	}
+	return start, end
+}
+
+// Is 判断类是否设置了访问标志
+func (c *ClassFile) Is(flag uint16) bool {
+	return c.AccessFlags&flag != 0
+}
+
+// Kind 返回类型的种类：class、interface、enum、record或@interface
+func (c *ClassFile) Kind() string {
+	switch {
+	case c.Is(AccAnnotation):
+		return "@interface"
+	case c.Is(AccInterface):
+		return "interface"
+	case c.Is(AccEnum):
+		return "enum"
+	case c.Record:
+		return "record"
+	}
+	return "class"
+}
+
+// Package 返回包的内部名称（如 java/util），默认包为空
+func (c *ClassFile) Package() string {
+	if i := strings.LastIndexByte(c.Name, '/'); i >= 0 {
+		return c.Name[:i]
+	}
+	return ""
+}
+
+// Parse 解析class文件内容
+func Parse(data []byte) (*ClassFile, error) {
+	r := &reader{data: data}
+	if r.u4() != magic {
+		if r.err != nil {
+			return nil, r.err
+		}
+		return nil, fmt.Errorf("%w: 魔数不是0xCAFEBABE", ErrFormat)
+	}
+	cf := &ClassFile{MinorVersion: r.u2(), MajorVersion: r.u2()}
+	pool, err := readPool(r)
+	if err != nil {
+		return nil, err
+	}
+	cf.Pool = pool
+	p := &parser{r: r, pool: pool}
+
+	cf.AccessFlags = r.u2()
+	cf.Name = p.className(r.u2())
+	if super := r.u2(); super != 0 {
+		cf.SuperName = p.className(super)
+	}
+	for n := r.u2(); n > 0 && p.ok(); n-- {
+		cf.Interfaces = append(cf.Interfaces, p.className(r.u2()))
+	}
+	for n := r.u2(); n > 0 && p.ok(); n-- {
+		cf.Fields = append(cf.Fields, p.member())
+	}
+	for n := r.u2(); n > 0 && p.ok(); n-- {
+		cf.Methods = append(cf.Methods, p.member())
+	}
+	p.attributes(func(name string, ar *reader) {
+		switch name {
+		case "SourceFile":
+			cf.SourceFile = p.utf8(ar.u2())
+		case "Signature":
+			cf.Signature = p.utf8(ar.u2())
+		case "RuntimeVisibleAnnotations", "RuntimeInvisibleAnnotations":
+			cf.Annotations = append(cf.Annotations, p.annotations(ar, name == "RuntimeVisibleAnnotations")...)
+		case "Record":
+			cf.Record = true
+		case "InnerClasses":
+			cf.InnerClasses = p.innerClasses(ar)
+		}
+	})
+	if p.err != nil {
+		return nil, p.err
+	}
+	return cf, r.err
+}
+
+// parser 解析常量池之后的部分，记录第一个错误
+type parser struct {
+	r    *reader
+	pool Pool
+	err  error
+}
+
+func (p *parser) ok() bool {
+	if p.err == nil {
+		p.err = p.r.err
+	}
+	return p.err == nil
+}
+
+func (p *parser) fail(err error) {
+	if p.err == nil {
+		p.err = err
+	}
+}
+
+func (p *parser) utf8(i uint16) string {
+	s, err := p.pool.Utf8(i)
+	if err != nil {
+		p.fail(err)
+	}
+	return s
+}
+
+func (p *parser) className(i uint16) string {
+	s, err := p.pool.ClassName(i)
+	if err != nil {
+		p.fail(err)
+	}
+	return s
+}
+
+// attributes 读取属性表，对每个属性用只包含该属性内容的reader回调，未知属性直接跳过
+func (p *parser) attributes(f func(name string, r *reader)) {
+	for n := p.r.u2(); n > 0 && p.ok(); n-- {
+		name := p.utf8(p.r.u2())
+		data := p.r.bytes(int(p.r.u4()))
+		if !p.ok() {
+			return
+		}
+		ar := &reader{data: data}
+		f(name, ar)
+		if ar.err != nil {
+			p.fail(fmt.Errorf("属性%s: %w", name, ar.err))
+		}
+	}
+}
+
+func (p *parser) member() *Member {
+	m := &Member{AccessFlags: p.r.u2()}
+	m.Name = p.utf8(p.r.u2())
+	m.Descriptor = p.utf8(p.r.u2())
+	p.attributes(func(name string, ar *reader) {
+		switch name {
+		case "Signature":
+			m.Signature = p.utf8(ar.u2())
+		case "RuntimeVisibleAnnotations", "RuntimeInvisibleAnnotations":
+			m.Annotations = append(m.Annotations, p.annotations(ar, name == "RuntimeVisibleAnnotations")...)
+		case "Exceptions":
+			for n := ar.u2(); n > 0 && ar.err == nil; n-- {
+				m.Exceptions = append(m.Exceptions, p.className(ar.u2()))
+			}
+		case "RuntimeVisibleParameterAnnotations", "RuntimeInvisibleParameterAnnotations":
+			visible := name == "RuntimeVisibleParameterAnnotations"
+			n := int(ar.u1())
+			if len(m.ParameterAnnotations) < n {
+				m.ParameterAnnotations = append(m.ParameterAnnotations, make([][]*Annotation, n-len(m.ParameterAnnotations))...)
+			}
+			for i := 0; i < n && ar.err == nil; i++ {
+				m.ParameterAnnotations[i] = append(m.ParameterAnnotations[i], p.annotations(ar, visible)...)
+			}
+		case "MethodParameters":
+			for n := ar.u1(); n > 0 && ar.err == nil; n-- {
+				var name string
+				if i := ar.u2(); i != 0 {
+					name = p.utf8(i)
+				}
+				ar.u2() // access_flags
+				m.MethodParameters = append(m.MethodParameters, name)
+			}
+		case "AnnotationDefault":
+			v := p.elementValue(ar, true)
+			m.AnnotationDefault = &v
+		case "Code":
+			m.Code = p.code(ar)
+		case "ConstantValue":
+			m.ConstantValue = p.constantValue(ar.u2())
+		}
+	})
+	return m
+}
+
+func (p *parser) code(r *reader) *Code {
+	c := &Code{MaxStack: r.u2(), MaxLocals: r.u2()}
+	c.Bytecode = r.bytes(int(r.u4()))
+	for n := r.u2(); n > 0 && r.err == nil; n-- {
+		h := ExceptionHandler{StartPC: r.u2(), EndPC: r.u2(), HandlerPC: r.u2()}
+		if catchType := r.u2(); catchType != 0 {
+			h.CatchType = p.className(catchType)
+		}
+		c.Handlers = append(c.Handlers, h)
+	}
+	if r.err != nil {
+		return c
+	}
+	// Code属性内部的属性表
+	outer := p.r
+	p.r = r
+	p.attributes(func(name string, ar *reader) {
+		switch name {
+		case "LineNumberTable":
+			for n := ar.u2(); n > 0 && ar.err == nil; n-- {
+				c.Lines = append(c.Lines, LineNumber{PC: ar.u2(), Line: int(ar.u2())})
+			}
+		case "LocalVariableTable":
+			for n := ar.u2(); n > 0 && ar.err == nil; n-- {
+				c.Locals = append(c.Locals, LocalVariable{StartPC: ar.u2(), Length: ar.u2(), Name: p.utf8(ar.u2()), Descriptor: p.utf8(ar.u2()), Index: ar.u2()})
+			}
+		}
+	})
+	p.r = outer
+	return c
+}
+
+func (p *parser) constantValue(i uint16) any {
+	if int(i) <= 0 || int(i) >= len(p.pool) {
+		p.fail(fmt.Errorf("%w: 常量池下标%d越界", ErrFormat, i))
+		return nil
+	}
+	switch c := p.pool[i]; c.Tag {
+	case TagInteger, TagLong:
+		return c.Int
+	case TagFloat, TagDouble:
+		return c.Float
+	case TagString:
+		return p.utf8(c.Ref1)
+	}
+	return nil
+}
+
+func (p *parser) innerClasses(r *reader) map[string]InnerClass {
+	inner := make(map[string]InnerClass)
+	for n := r.u2(); n > 0 && r.err == nil; n-- {
+		innerIdx, outerIdx, nameIdx, flags := r.u2(), r.u2(), r.u2(), r.u2()
+		ic := InnerClass{AccessFlags: flags}
+		if outerIdx != 0 {
+			ic.Outer = p.className(outerIdx)
+		}
+		if nameIdx != 0 {
+			ic.Name = p.utf8(nameIdx)
+		}
+		inner[p.className(innerIdx)] = ic
+	}
+	return inner
+}
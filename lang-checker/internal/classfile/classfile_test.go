@@ -0,0 +1,192 @@
+package classfile
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// testClass 构造只含类名、父类和访问标志的最小class文件
+func testClass(name, super string, flags int) []byte {
+	var b bytes.Buffer
+	u2 := func(v int) { b.Write([]byte{byte(v >> 8), byte(v)}) }
+	utf8 := func(s string) { b.WriteByte(1); u2(len(s)); b.WriteString(s) }
+	b.Write([]byte{0xCA, 0xFE, 0xBA, 0xBE})
+	u2(0)
+	u2(61)
+	u2(5)
+	utf8(name)
+	b.WriteByte(7)
+	u2(1)
+	utf8(super)
+	b.WriteByte(7)
+	u2(3)
+	u2(flags)
+	u2(2)
+	u2(4)
+	u2(0) // interfaces
+	u2(0) // fields
+	u2(0) // methods
+	u2(0) // attributes
+	return b.Bytes()
+}
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		name  string
+		flags int
+		kind  string
+	}{
+		{"class", 0x0021, "class"},
+		{"interface", 0x0601, "interface"},
+		{"enum", 0x4031, "enum"},
+		{"annotation", 0x2601, "@interface"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cf, err := Parse(testClass("com/example/Foo", "java/lang/Object", c.flags))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cf.Name != "com/example/Foo" || cf.SuperName != "java/lang/Object" {
+				t.Errorf("Name = %q, SuperName = %q", cf.Name, cf.SuperName)
+			}
+			if cf.MajorVersion != 61 {
+				t.Errorf("MajorVersion = %d, want 61", cf.MajorVersion)
+			}
+			if got := cf.Package(); got != "com/example" {
+				t.Errorf("Package() = %q, want com/example", got)
+			}
+			if got := cf.Kind(); got != c.kind {
+				t.Errorf("Kind() = %q, want %q", got, c.kind)
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	data := testClass("Foo", "java/lang/Object", 0x0021)
+	cases := []struct {
+		name string
+		data []byte
+		want error
+	}{
+		{"empty", nil, ErrTruncated},
+		{"bad magic", append([]byte{0xCA, 0xFE, 0xBA, 0xBF}, data[4:]...), ErrFormat},
+		{"truncated pool", data[:16], ErrTruncated},
+		{"truncated body", data[:len(data)-4], ErrTruncated},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := Parse(c.data)
+			if !errors.Is(err, c.want) {
+				t.Errorf("Parse() error = %v, want %v", err, c.want)
+			}
+		})
+	}
+}
+
+func TestJavaName(t *testing.T) {
+	cases := map[string]string{
+		"Foo":                   "Foo",
+		"java/lang/String":      "java.lang.String",
+		"java/util/Map$Entry":   "java.util.Map.Entry",
+		"com/example/Foo$1":     "com.example.Foo$1",
+		"com/example/Foo$1Bar":  "com.example.Foo$1Bar",
+		"com/example/$Proxy":    "com.example.$Proxy",
+		"com/example/A$B$C":     "com.example.A.B.C",
+		"com/example/Outer$1$2": "com.example.Outer$1$2",
+	}
+	for in, want := range cases {
+		if got := JavaName(in); got != want {
+			t.Errorf("JavaName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestParseMethodSignature(t *testing.T) {
+	cases := []struct {
+		sig  string
+		want *MethodType
+	}{
+		{"()V", &MethodType{Result: "void"}},
+		{"(ILjava/lang/String;[J)Z", &MethodType{
+			Params: []string{"int", "java.lang.String", "long[]"},
+			Result: "boolean",
+		}},
+		{"<T:Ljava/lang/Object;>(Ljava/util/List<TT;>;)TT;", &MethodType{
+			TypeParams: "<T>",
+			Params:     []string{"java.util.List<T>"},
+			Result:     "T",
+		}},
+		{"<T::Ljava/lang/Comparable<-TT;>;>(Ljava/util/Map<Ljava/lang/String;+TT;>;)V", &MethodType{
+			TypeParams: "<T extends java.lang.Comparable<? super T>>",
+			Params:     []string{"java.util.Map<java.lang.String, ? extends T>"},
+			Result:     "void",
+		}},
+		{"(Ljava/util/Map$Entry<**>;)V^Ljava/io/IOException;", &MethodType{
+			Params: []string{"java.util.Map.Entry<?, ?>"},
+			Result: "void",
+			Throws: []string{"java.io.IOException"},
+		}},
+	}
+	for _, c := range cases {
+		got, err := ParseMethodSignature(c.sig)
+		if err != nil {
+			t.Errorf("ParseMethodSignature(%q) error: %v", c.sig, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("ParseMethodSignature(%q) = %+v, want %+v", c.sig, got, c.want)
+		}
+	}
+}
+
+func TestParseSignatureInvalid(t *testing.T) {
+	for _, sig := range []string{"", "(I", "(Q)V", "(Ljava/lang/String)V", "()VX"} {
+		if _, err := ParseMethodSignature(sig); err == nil {
+			t.Errorf("ParseMethodSignature(%q) 应返回错误", sig)
+		}
+	}
+	for _, sig := range []string{"", "Ljava/lang/String", "II"} {
+		if _, err := ParseFieldSignature(sig); err == nil {
+			t.Errorf("ParseFieldSignature(%q) 应返回错误", sig)
+		}
+	}
+}
+
+func TestParseFieldSignature(t *testing.T) {
+	cases := map[string]string{
+		"I":                                    "int",
+		"[[Ljava/lang/Object;":                 "java.lang.Object[][]",
+		"Ljava/util/List<Ljava/lang/String;>;": "java.util.List<java.lang.String>",
+		"Lcom/example/Outer<TT;>.Inner<Ljava/lang/Long;>;": "com.example.Outer<T>.Inner<java.lang.Long>",
+	}
+	for sig, want := range cases {
+		got, err := ParseFieldSignature(sig)
+		if err != nil {
+			t.Errorf("ParseFieldSignature(%q) error: %v", sig, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("ParseFieldSignature(%q) = %q, want %q", sig, got, want)
+		}
+	}
+}
+
+func TestParseClassSignature(t *testing.T) {
+	sig := "<K:Ljava/lang/Object;V:Ljava/lang/Number;>Ljava/util/AbstractMap<TK;TV;>;Ljava/io/Serializable;"
+	got, err := ParseClassSignature(sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &ClassType{
+		TypeParams: "<K, V extends java.lang.Number>",
+		Super:      "java.util.AbstractMap<K, V>",
+		Interfaces: []string{"java.io.Serializable"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseClassSignature() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,97 @@
+package classfile
+
+import (
+	"sort"
+	"strings"
+)
+
+// Dependencies 返回类引用的其他类（内部名称，去重排序），来源包括常量池中的类和成员描述符、
+// 字段和方法的描述符及泛型签名、声明的异常和注解，不含自身
+func (c *ClassFile) Dependencies() []string {
+	deps := make(map[string]bool)
+	add := func(names ...string) {
+		for _, n := range names {
+			deps[n] = true
+		}
+	}
+	addDescriptor := func(desc string) {
+		if strings.HasPrefix(desc, "(") {
+			add(signatureClasses(desc, 'm')...)
+		} else {
+			add(signatureClasses(desc, 'f')...)
+		}
+	}
+
+	for _, k := range c.Pool {
+		switch k.Tag {
+		case TagClass:
+			if name, err := c.Pool.Utf8(k.Ref1); err == nil {
+				if strings.HasPrefix(name, "[") {
+					addDescriptor(name)
+				} else {
+					add(name)
+				}
+			}
+		case TagNameAndType, TagMethodType:
+			ref := k.Ref2
+			if k.Tag == TagMethodType {
+				ref = k.Ref1
+			}
+			if desc, err := c.Pool.Utf8(ref); err == nil {
+				addDescriptor(desc)
+			}
+		}
+	}
+
+	if c.Signature != "" {
+		add(signatureClasses(c.Signature, 'c')...)
+	}
+	addAnnotationDeps(c.Annotations, add, addDescriptor)
+	for _, m := range append(append([]*Member(nil), c.Fields...), c.Methods...) {
+		addDescriptor(m.Descriptor)
+		if m.Signature != "" {
+			if strings.HasPrefix(m.Signature, "(") || strings.HasPrefix(m.Signature, "<") {
+				add(signatureClasses(m.Signature, 'm')...)
+			} else {
+				add(signatureClasses(m.Signature, 'f')...)
+			}
+		}
+		add(m.Exceptions...)
+		addAnnotationDeps(m.Annotations, add, addDescriptor)
+	}
+
+	delete(deps, c.Name)
+	delete(deps, "")
+	list := make([]string, 0, len(deps))
+	for d := range deps {
+		list = append(list, d)
+	}
+	sort.Strings(list)
+	return list
+}
+
+func addAnnotationDeps(annotations []*Annotation, add func(...string), addDescriptor func(string)) {
+	var value func(v ElementValue)
+	value = func(v ElementValue) {
+		switch v.Tag {
+		case 'e':
+			addDescriptor(v.EnumType)
+		case 'c':
+			if v.Class != "V" {
+				addDescriptor(v.Class)
+			}
+		case '@':
+			addAnnotationDeps([]*Annotation{v.Annotation}, add, addDescriptor)
+		case '[':
+			for _, e := range v.Array {
+				value(e)
+			}
+		}
+	}
+	for _, a := range annotations {
+		addDescriptor(a.Type)
+		for _, e := range a.Elements {
+			value(e.Value)
+		}
+	}
+}
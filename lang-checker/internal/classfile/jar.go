@@ -0,0 +1,93 @@
+package classfile
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ErrEntryTooLarge JAR包中的class文件超过大小限制
+var ErrEntryTooLarge = errors.New("class文件过大")
+
+// Entry JAR包中的一个class文件，解析失败时Err非nil
+type Entry struct {
+	// Name 包内路径，如 com/example/Foo.class
+	Name  string
+	Class *ClassFile
+	// Data class文件的原始内容，供反编译等需要原文件的场景使用
+	Data []byte
+	Err  error
+}
+
+// WalkJar 按包内路径顺序解析JAR包中的class文件并逐个回调，maxSize为单个class文件的最大字节数（<=0不限制）。
+// META-INF下的条目（含多版本JAR的versions目录）、module-info和package-info，以及含..或以/开头的非法路径不解析；
+// 单个class文件的错误通过Entry.Err传给回调，回调返回错误或ctx取消时停止遍历
+func WalkJar(ctx context.Context, path string, maxSize int64, fn func(Entry) error) error {
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+
+	var files []*zip.File
+	for _, f := range zr.File {
+		if isClassEntry(f.Name) {
+			files = append(files, f)
+		}
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Name < files[j].Name })
+
+	for _, f := range files {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		entry := Entry{Name: f.Name}
+		data, err := readEntry(f, maxSize)
+		if err == nil {
+			entry.Data = data
+			entry.Class, err = Parse(data)
+		}
+		entry.Err = err
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func isClassEntry(name string) bool {
+	if !strings.HasSuffix(name, ".class") || strings.HasPrefix(name, "META-INF/") || !filepath.IsLocal(name) {
+		return false
+	}
+	base := name[strings.LastIndexByte(name, '/')+1:]
+	return base != "module-info.class" && base != "package-info.class"
+}
+
+func readEntry(f *zip.File, maxSize int64) ([]byte, error) {
+	if maxSize > 0 && f.UncompressedSize64 > uint64(maxSize) {
+		return nil, fmt.Errorf("%w: %d字节，上限%d字节", ErrEntryTooLarge, f.UncompressedSize64, maxSize)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	var r io.Reader = rc
+	if maxSize > 0 {
+		// 压缩包头中的大小可能被篡改，实际读取时再限制一次
+		r = io.LimitReader(rc, maxSize+1)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if maxSize > 0 && int64(len(data)) > maxSize {
+		return nil, fmt.Errorf("%w: 超过%d字节", ErrEntryTooLarge, maxSize)
+	}
+	return data, nil
+}
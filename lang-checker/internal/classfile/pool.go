@@ -0,0 +1,158 @@
+package classfile
+
+import (
+	"fmt"
+	"math"
+	"unicode/utf16"
+)
+
+// 常量池项的标签
+const (
+	TagUtf8               = 1
+	TagInteger            = 3
+	TagFloat              = 4
+	TagLong               = 5
+	TagDouble             = 6
+	TagClass              = 7
+	TagString             = 8
+	TagFieldref           = 9
+	TagMethodref          = 10
+	TagInterfaceMethodref = 11
+	TagNameAndType        = 12
+	TagMethodHandle       = 15
+	TagMethodType         = 16
+	TagDynamic            = 17
+	TagInvokeDynamic      = 18
+	TagModule             = 19
+	TagPackage            = 20
+)
+
+// Constant 常量池项。引用其他项的常量使用Ref1/Ref2保存下标：
+// Class/String/MethodType/Module/Package为Ref1，Fieldref/Methodref为类和NameAndType，
+// NameAndType为名称和描述符，MethodHandle为引用种类和目标，Dynamic为引导方法和NameAndType
+type Constant struct {
+	Tag        uint8
+	Ref1, Ref2 uint16
+	// Text Utf8常量的内容
+	Text string
+	// Int Integer/Long常量的值，Float/Double常量的值保存在Float中
+	Int   int64
+	Float float64
+}
+
+// Pool 常量池，下标从1开始，Long和Double之后的下标不可用
+type Pool []Constant
+
+func readPool(r *reader) (Pool, error) {
+	count := int(r.u2())
+	pool := make(Pool, count)
+	for i := 1; i < count; i++ {
+		c := Constant{Tag: r.u1()}
+		switch c.Tag {
+		case TagUtf8:
+			c.Text = decodeModifiedUTF8(r.bytes(int(r.u2())))
+		case TagInteger:
+			c.Int = int64(int32(r.u4()))
+		case TagFloat:
+			c.Float = float64(math.Float32frombits(r.u4()))
+		case TagLong:
+			c.Int = int64(r.u8())
+		case TagDouble:
+			c.Float = math.Float64frombits(r.u8())
+		case TagClass, TagString, TagMethodType, TagModule, TagPackage:
+			c.Ref1 = r.u2()
+		case TagFieldref, TagMethodref, TagInterfaceMethodref, TagNameAndType, TagDynamic, TagInvokeDynamic:
+			c.Ref1, c.Ref2 = r.u2(), r.u2()
+		case TagMethodHandle:
+			c.Ref1, c.Ref2 = uint16(r.u1()), r.u2()
+		default:
+			if r.err == nil {
+				return nil, fmt.Errorf("%w: 常量池第%d项的标签%d未知", ErrFormat, i, c.Tag)
+			}
+		}
+		if r.err != nil {
+			return nil, r.err
+		}
+		pool[i] = c
+		// Long和Double占两个下标
+		if c.Tag == TagLong || c.Tag == TagDouble {
+			i++
+		}
+	}
+	return pool, nil
+}
+
+func (p Pool) get(i uint16, tag uint8) (Constant, error) {
+	if int(i) <= 0 || int(i) >= len(p) || p[i].Tag != tag {
+		return Constant{}, fmt.Errorf("%w: 常量池下标%d不是预期的类型%d", ErrFormat, i, tag)
+	}
+	return p[i], nil
+}
+
+// Utf8 返回Utf8常量的内容
+func (p Pool) Utf8(i uint16) (string, error) {
+	c, err := p.get(i, TagUtf8)
+	return c.Text, err
+}
+
+// ClassName 返回Class常量的内部名称，如 java/lang/String，数组类型为描述符形式
+func (p Pool) ClassName(i uint16) (string, error) {
+	c, err := p.get(i, TagClass)
+	if err != nil {
+		return "", err
+	}
+	return p.Utf8(c.Ref1)
+}
+
+// MemberRef 返回Fieldref、Methodref或InterfaceMethodref常量引用的类内部名称、成员名和描述符
+func (p Pool) MemberRef(i uint16) (class, name, descriptor string, err error) {
+	if int(i) <= 0 || int(i) >= len(p) {
+		return "", "", "", fmt.Errorf("%w: 常量池下标%d越界", ErrFormat, i)
+	}
+	c := p[i]
+	if c.Tag != TagFieldref && c.Tag != TagMethodref && c.Tag != TagInterfaceMethodref {
+		return "", "", "", fmt.Errorf("%w: 常量池下标%d不是成员引用", ErrFormat, i)
+	}
+	if class, err = p.ClassName(c.Ref1); err != nil {
+		return "", "", "", err
+	}
+	name, descriptor, err = p.NameAndType(c.Ref2)
+	return class, name, descriptor, err
+}
+
+// NameAndType 返回NameAndType常量的名称和描述符
+func (p Pool) NameAndType(i uint16) (name, descriptor string, err error) {
+	c, err := p.get(i, TagNameAndType)
+	if err != nil {
+		return "", "", err
+	}
+	if name, err = p.Utf8(c.Ref1); err != nil {
+		return "", "", err
+	}
+	descriptor, err = p.Utf8(c.Ref2)
+	return name, descriptor, err
+}
+
+// decodeModifiedUTF8 解码class文件使用的改良UTF-8：U+0000编码为两个字节，
+// 增补字符按UTF-16代理对分别编码为三个字节
+func decodeModifiedUTF8(b []byte) string {
+	units := make([]uint16, 0, len(b))
+	for i := 0; i < len(b); {
+		c := b[i]
+		switch {
+		case c < 0x80:
+			units = append(units, uint16(c))
+			i++
+		case c&0xe0 == 0xc0 && i+1 < len(b):
+			units = append(units, uint16(c&0x1f)<<6|uint16(b[i+1]&0x3f))
+			i += 2
+		case c&0xf0 == 0xe0 && i+2 < len(b):
+			units = append(units, uint16(c&0x0f)<<12|uint16(b[i+1]&0x3f)<<6|uint16(b[i+2]&0x3f))
+			i += 3
+		default:
+			units = append(units, 0xfffd)
+			i++
+		}
+	}
+	return string(utf16.Decode(units))
+}
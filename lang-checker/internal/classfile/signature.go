@@ -0,0 +1,250 @@
+package classfile
+
+import (
+	"fmt"
+	"strings"
+)
+
+// JavaName 把内部名称转为源码形式的全限定名，如 java/util/Map$Entry 为 java.util.Map.Entry；
+// 匿名类和局部类的编号（如 Foo$1）保留'$'
+func JavaName(internal string) string {
+	name := strings.ReplaceAll(internal, "/", ".")
+	var b strings.Builder
+	for i := 0; i < len(name); i++ {
+		if name[i] == '$' && i+1 < len(name) && (name[i+1] < '0' || name[i+1] > '9') && i > 0 && name[i-1] != '.' {
+			b.WriteByte('.')
+			continue
+		}
+		b.WriteByte(name[i])
+	}
+	return b.String()
+}
+
+// MethodType 源码形式的方法签名
+type MethodType struct {
+	// TypeParams 类型参数，如 <T extends Comparable<T>>，没有时为空
+	TypeParams string
+	Params     []string
+	Result     string
+	// Throws 泛型签名中声明的异常，签名中没有时为空，应以Member.Exceptions为准
+	Throws []string
+}
+
+// ClassType 源码形式的泛型类签名
+type ClassType struct {
+	TypeParams string
+	Super      string
+	Interfaces []string
+}
+
+// ParseMethodSignature 解析方法描述符或泛型方法签名，类型名为源码形式的全限定名
+func ParseMethodSignature(sig string) (*MethodType, error) {
+	p := &sigParser{s: sig}
+	return p.methodType(), p.err
+}
+
+// ParseFieldSignature 解析字段描述符或泛型字段签名
+func ParseFieldSignature(sig string) (string, error) {
+	p := &sigParser{s: sig}
+	t := p.javaType()
+	p.end()
+	return t, p.err
+}
+
+// ParseClassSignature 解析泛型类签名
+func ParseClassSignature(sig string) (*ClassType, error) {
+	p := &sigParser{s: sig}
+	ct := &ClassType{TypeParams: p.typeParams(), Super: p.refType()}
+	for p.err == nil && p.pos < len(p.s) {
+		ct.Interfaces = append(ct.Interfaces, p.refType())
+	}
+	return ct, p.err
+}
+
+// sigParser 按JVMS 4.7.9.1的语法解析签名，描述符是不含泛型的特例。
+// 解析过程中遇到的类名记录在classes中（内部名称）
+type sigParser struct {
+	s       string
+	pos     int
+	err     error
+	classes []string
+}
+
+func (p *sigParser) fail(msg string) {
+	if p.err == nil {
+		p.err = fmt.Errorf("%w: 签名%q第%d个字符处%s", ErrFormat, p.s, p.pos, msg)
+	}
+	p.pos = len(p.s)
+}
+
+func (p *sigParser) peek() byte {
+	if p.pos < len(p.s) {
+		return p.s[p.pos]
+	}
+	return 0
+}
+
+func (p *sigParser) expect(c byte) {
+	if p.peek() != c {
+		p.fail(fmt.Sprintf("期望%q", c))
+		return
+	}
+	p.pos++
+}
+
+func (p *sigParser) end() {
+	if p.err == nil && p.pos != len(p.s) {
+		p.fail("有多余的内容")
+	}
+}
+
+func (p *sigParser) identifier(stops string) string {
+	start := p.pos
+	for p.pos < len(p.s) && !strings.ContainsRune(stops, rune(p.s[p.pos])) {
+		p.pos++
+	}
+	if p.pos == start {
+		p.fail("缺少标识符")
+	}
+	return p.s[start:p.pos]
+}
+
+var baseTypes = map[byte]string{
+	'B': "byte", 'C': "char", 'D': "double", 'F': "float",
+	'I': "int", 'J': "long", 'S': "short", 'Z': "boolean",
+}
+
+func (p *sigParser) methodType() *MethodType {
+	mt := &MethodType{TypeParams: p.typeParams()}
+	p.expect('(')
+	for p.err == nil && p.peek() != ')' {
+		mt.Params = append(mt.Params, p.javaType())
+	}
+	p.expect(')')
+	if p.peek() == 'V' {
+		p.pos++
+		mt.Result = "void"
+	} else {
+		mt.Result = p.javaType()
+	}
+	for p.err == nil && p.peek() == '^' {
+		p.pos++
+		mt.Throws = append(mt.Throws, p.refType())
+	}
+	p.end()
+	return mt
+}
+
+func (p *sigParser) typeParams() string {
+	if p.peek() != '<' {
+		return ""
+	}
+	p.pos++
+	var params []string
+	for p.err == nil && p.peek() != '>' {
+		name := p.identifier(":>")
+		var bounds []string
+		// 类上界可以为空（只有接口上界），Object上界省略
+		for p.err == nil && p.peek() == ':' {
+			p.pos++
+			if c := p.peek(); c == 'L' || c == 'T' || c == '[' {
+				if b := p.refType(); b != "java.lang.Object" {
+					bounds = append(bounds, b)
+				}
+			}
+		}
+		if len(bounds) > 0 {
+			name += " extends " + strings.Join(bounds, " & ")
+		}
+		params = append(params, name)
+	}
+	p.expect('>')
+	return "<" + strings.Join(params, ", ") + ">"
+}
+
+func (p *sigParser) javaType() string {
+	if t, ok := baseTypes[p.peek()]; ok {
+		p.pos++
+		return t
+	}
+	return p.refType()
+}
+
+func (p *sigParser) refType() string {
+	switch p.peek() {
+	case 'L':
+		return p.classType()
+	case 'T':
+		p.pos++
+		name := p.identifier(";")
+		p.expect(';')
+		return name
+	case '[':
+		p.pos++
+		return p.javaType() + "[]"
+	}
+	p.fail("期望引用类型")
+	return ""
+}
+
+// classType 解析 L包名/类名<实参>.内部类<实参>; 内部类部分的名称以'$'拼接记录
+func (p *sigParser) classType() string {
+	p.expect('L')
+	internal := p.identifier("<.;")
+	var b strings.Builder
+	b.WriteString(JavaName(internal))
+	for p.err == nil {
+		if p.peek() == '<' {
+			b.WriteString(p.typeArgs())
+		}
+		if p.peek() != '.' {
+			break
+		}
+		p.pos++
+		inner := p.identifier("<.;")
+		internal += "$" + inner
+		b.WriteString("." + inner)
+	}
+	p.expect(';')
+	p.classes = append(p.classes, internal)
+	return b.String()
+}
+
+func (p *sigParser) typeArgs() string {
+	p.expect('<')
+	var args []string
+	for p.err == nil && p.peek() != '>' {
+		switch p.peek() {
+		case '*':
+			p.pos++
+			args = append(args, "?")
+		case '+':
+			p.pos++
+			args = append(args, "? extends "+p.refType())
+		case '-':
+			p.pos++
+			args = append(args, "? super "+p.refType())
+		default:
+			args = append(args, p.refType())
+		}
+	}
+	p.expect('>')
+	return "<" + strings.Join(args, ", ") + ">"
+}
+
+// signatureClasses 返回描述符或签名中引用的类（内部名称），kind为'm'方法、'f'字段、'c'类签名
+func signatureClasses(sig string, kind byte) []string {
+	p := &sigParser{s: sig}
+	switch kind {
+	case 'm':
+		p.methodType()
+	case 'c':
+		p.typeParams()
+		for p.err == nil && p.pos < len(p.s) {
+			p.refType()
+		}
+	default:
+		p.javaType()
+	}
+	return p.classes
+}
@@ -0,0 +1,367 @@
+package complexity
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/liujinliang/lang-checker/internal/java"
+	"github.com/liujinliang/lang-checker/internal/models"
+)
+
+// javaLangTypes java.lang中的常用类型，几乎所有类都会用到，不计入耦合度
+var javaLangTypes = map[string]bool{
+	"Object": true, "String": true, "StringBuilder": true, "CharSequence": true,
+	"Boolean": true, "Byte": true, "Character": true, "Short": true, "Integer": true,
+	"Long": true, "Float": true, "Double": true, "Number": true, "Void": true,
+	"Math": true, "System": true, "Iterable": true, "Comparable": true,
+	"Override": true, "Deprecated": true, "SuppressWarnings": true, "FunctionalInterface": true,
+}
+
+// javaLangSupertypes 常被继承或实现的java.lang类型，补全全限定名时不按同包处理
+var javaLangSupertypes = map[string]bool{
+	"Runnable": true, "Cloneable": true, "AutoCloseable": true, "Thread": true,
+	"Throwable": true, "Exception": true, "RuntimeException": true, "Error": true,
+}
+
+// JavaClass 计算类型声明的CK指标。DIT和NOC依赖分析范围内的其他文件，这里为0，由调用方补全；
+// 内部类和局部类单独计算，其成员不计入外层类型，匿名类计入所在的类型
+func JavaClass(file *java.File, decl *java.TypeDecl) models.ClassMetrics {
+	cm := models.ClassMetrics{
+		Name:      decl.NestedName(),
+		Kind:      decl.Kind.String(),
+		StartLine: decl.Name.Pos().Line,
+		EndLine:   decl.End().Line,
+		Fields:    len(decl.Components),
+	}
+	for _, t := range decl.Extends {
+		if decl.Kind == java.ClassKind {
+			cm.Superclass = QualifyJavaType(file, t.Name)
+		} else {
+			cm.Interfaces = append(cm.Interfaces, QualifyJavaType(file, t.Name))
+		}
+	}
+	for _, t := range decl.Implements {
+		cm.Interfaces = append(cm.Interfaces, QualifyJavaType(file, t.Name))
+	}
+
+	var methods []*java.MethodDecl
+	for _, member := range decl.Members {
+		switch m := member.(type) {
+		case *java.MethodDecl:
+			methods = append(methods, m)
+			if m.Body != nil {
+				cm.WMC += JavaCyclomaticComplexity(m.Body)
+			} else {
+				cm.WMC++
+			}
+		case *java.FieldDecl:
+			cm.Fields += len(m.Vars)
+		}
+	}
+	cm.Methods = len(methods)
+	coupled := javaCoupledTypes(decl)
+	cm.CBO = len(coupled)
+	cm.Dependencies = qualifyAll(file, coupled)
+	cm.RFC = cm.Methods + len(javaCalledMethods(decl, methods))
+	cm.LCOM = javaLCOM(decl, methods)
+	return cm
+}
+
+// qualifyAll 把类型名补全为全限定名，去重排序
+func qualifyAll(file *java.File, names map[string]bool) []string {
+	qualified := make(map[string]bool, len(names))
+	for name := range names {
+		qualified[QualifyJavaType(file, name)] = true
+	}
+	list := make([]string, 0, len(qualified))
+	for name := range qualified {
+		list = append(list, name)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// QualifyJavaType 按文件的单类型导入、文件内声明的类型、常用java.lang类型和包名把类型名补全为全限定名，
+// 已是全限定名（首段小写）时原样返回。按需导入（*）无法确定来源，按同包处理
+func QualifyJavaType(file *java.File, name string) string {
+	head, rest, _ := strings.Cut(name, ".")
+	if head == "" || unicode.IsLower(rune(head[0])) {
+		return name
+	}
+	if rest != "" {
+		rest = "." + rest
+	}
+	for _, imp := range file.Imports {
+		if !imp.Static && !imp.Wildcard && (imp.Path == head || strings.HasSuffix(imp.Path, "."+head)) {
+			return imp.Path + rest
+		}
+	}
+	for _, t := range java.TypeDecls(file) {
+		if t.TypeName() == head {
+			return qualify(file.Package, t.NestedName()+rest)
+		}
+	}
+	if javaLangTypes[head] || javaLangSupertypes[head] {
+		return "java.lang." + head + rest
+	}
+	return qualify(file.Package, head+rest)
+}
+
+func qualify(pkg, name string) string {
+	if pkg == "" {
+		return name
+	}
+	return pkg + "." + name
+}
+
+// javaTypeKey 去掉类型名中的包名部分，如 java.util.Map.Entry 为 Map.Entry
+func javaTypeKey(name string) string {
+	parts := strings.Split(name, ".")
+	for i, p := range parts {
+		if p != "" && unicode.IsUpper(rune(p[0])) {
+			return strings.Join(parts[i:], ".")
+		}
+	}
+	return name
+}
+
+// inspectClass 遍历类型自身的代码，跳过其中具名的内部类和局部类
+func inspectClass(decl *java.TypeDecl, f func(java.Node) bool) {
+	java.Inspect(decl, func(n java.Node) bool {
+		if t, ok := n.(*java.TypeDecl); ok && t != decl && t.Name != nil {
+			return false
+		}
+		return f(n)
+	})
+}
+
+// javaCoupledTypes 返回类型引用的其他类型：声明和表达式中出现的类型，以及静态成员访问的类名
+func javaCoupledTypes(decl *java.TypeDecl) map[string]bool {
+	self := map[string]bool{decl.TypeName(): true, decl.NestedName(): true}
+	inspectClass(decl, func(n java.Node) bool {
+		switch x := n.(type) {
+		case *java.TypeDecl:
+			for _, tp := range x.TypeParams {
+				self[tp.Name] = true
+			}
+		case *java.MethodDecl:
+			for _, tp := range x.TypeParams {
+				self[tp.Name] = true
+			}
+		}
+		return true
+	})
+
+	coupled := make(map[string]bool)
+	add := func(name string) {
+		key := javaTypeKey(name)
+		if key == "" || key == "?" || key == "var" || java.IsPrimitive(key) || javaLangTypes[key] || self[key] {
+			return
+		}
+		coupled[key] = true
+	}
+	inspectClass(decl, func(n java.Node) bool {
+		switch x := n.(type) {
+		case *java.Type:
+			add(x.Name)
+		case *java.SelectorExpr:
+			addStaticReceiver(x.X, add)
+		case *java.CallExpr:
+			addStaticReceiver(x.X, add)
+		case *java.MethodRef:
+			if recv, ok := x.X.(java.Expr); ok {
+				addStaticReceiver(recv, add)
+			}
+		}
+		return true
+	})
+	return coupled
+}
+
+// addStaticReceiver 接收者是首字母大写且含小写字母的标识符时视为类名，如 Collections.sort
+func addStaticReceiver(x java.Expr, add func(string)) {
+	id, ok := x.(*java.Identifier)
+	if !ok || id.Name == "" || !unicode.IsUpper(rune(id.Name[0])) || strings.ToUpper(id.Name) == id.Name {
+		return
+	}
+	add(id.Name)
+}
+
+// javaCalledMethods 返回类型调用的不属于自身的方法和构造器，按名称和参数个数区分
+func javaCalledMethods(decl *java.TypeDecl, methods []*java.MethodDecl) map[string]bool {
+	own := make(map[string]bool, len(methods))
+	for _, m := range methods {
+		own[methodKey(m.Name.Name, len(m.Params))] = true
+	}
+	called := make(map[string]bool)
+	inspectClass(decl, func(n java.Node) bool {
+		switch x := n.(type) {
+		case *java.CallExpr:
+			key := methodKey(x.Name.Name, len(x.Args))
+			if (x.X == nil || isThis(x.X)) && own[key] {
+				break
+			}
+			called[key] = true
+		case *java.NewExpr:
+			called[methodKey("new "+javaTypeKey(x.Type.Name), len(x.Args))] = true
+		}
+		return true
+	})
+	return called
+}
+
+func methodKey(name string, params int) string {
+	return fmt.Sprintf("%s/%d", name, params)
+}
+
+func isThis(x java.Node) bool {
+	this, ok := x.(*java.ThisExpr)
+	return ok && this.Qualifier == nil
+}
+
+// javaLCOM 计算LCOM4：实例方法之间通过共享实例字段或相互调用连成的组数，
+// 构造器、静态方法和简单的getter/setter不参与；接口和注解没有实例状态，返回0
+func javaLCOM(decl *java.TypeDecl, methods []*java.MethodDecl) int {
+	if decl.Kind == java.InterfaceKind || decl.Kind == java.AnnotationKind {
+		return 0
+	}
+	fields := make(map[string]bool)
+	for _, c := range decl.Components {
+		fields[c.Name.Name] = true
+	}
+	for _, member := range decl.Members {
+		if f, ok := member.(*java.FieldDecl); ok && !f.Has("static") {
+			for _, v := range f.Vars {
+				fields[v.Name.Name] = true
+			}
+		}
+	}
+
+	var considered []*java.MethodDecl
+	for _, m := range methods {
+		if m.Body != nil && !m.Constructor && !m.Has("static") && !isAccessor(m) {
+			considered = append(considered, m)
+		}
+	}
+	if len(considered) == 0 {
+		return 0
+	}
+
+	names := make([]string, len(considered))
+	uses := make([]map[string]bool, len(considered))
+	calls := make([]map[string]bool, len(considered))
+	for i, m := range considered {
+		names[i] = m.Name.Name
+		uses[i], calls[i] = javaMemberUsage(m.Body, fields)
+	}
+	return LCOM4(names, uses, calls)
+}
+
+// LCOM4 计算方法按共享字段或相互调用连通的组数：names[i]为第i个方法名，
+// fields[i]为其访问的实例字段，calls[i]为其调用的本类方法名（同名重载视为同一方法）
+func LCOM4(names []string, fields, calls []map[string]bool) int {
+	parent := make([]int, len(names))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	union := func(a, b int) { parent[find(a)] = find(b) }
+
+	byName := make(map[string][]int)
+	for i, name := range names {
+		byName[name] = append(byName[name], i)
+	}
+	fieldOwner := make(map[string]int)
+	for i := range names {
+		for f := range fields[i] {
+			if j, ok := fieldOwner[f]; ok {
+				union(i, j)
+			} else {
+				fieldOwner[f] = i
+			}
+		}
+		for name := range calls[i] {
+			for _, j := range byName[name] {
+				union(i, j)
+			}
+		}
+	}
+
+	groups := 0
+	for i := range names {
+		if find(i) == i {
+			groups++
+		}
+	}
+	return groups
+}
+
+// isAccessor 判断是否为getX/isX/setX形式且方法体不超过一条语句的访问器
+func isAccessor(m *java.MethodDecl) bool {
+	name := m.Name.Name
+	for _, prefix := range []string{"get", "is", "set"} {
+		rest, ok := strings.CutPrefix(name, prefix)
+		if ok && rest != "" && unicode.IsUpper(rune(rest[0])) {
+			return len(m.Body.Stmts) <= 1
+		}
+	}
+	return false
+}
+
+// javaMemberUsage 返回方法体中访问的实例字段（name或this.name）和无接收者或以this为接收者调用的方法名，
+// 不区分同名的局部变量
+func javaMemberUsage(body *java.Block, fields map[string]bool) (used, calls map[string]bool) {
+	used, calls = make(map[string]bool), make(map[string]bool)
+	var visit func(java.Node) bool
+	visit = func(n java.Node) bool {
+		switch x := n.(type) {
+		case *java.Identifier:
+			if fields[x.Name] {
+				used[x.Name] = true
+			}
+		case *java.SelectorExpr:
+			if isThis(x.X) && fields[x.Name.Name] {
+				used[x.Name.Name] = true
+			}
+			java.Inspect(x.X, visit)
+			return false
+		case *java.CallExpr:
+			if x.X == nil || isThis(x.X) {
+				calls[x.Name.Name] = true
+			} else {
+				java.Inspect(x.X, visit)
+			}
+			for _, arg := range x.Args {
+				java.Inspect(arg, visit)
+			}
+			return false
+		case *java.MethodRef:
+			if isThis(x.X) {
+				calls[x.Name.Name] = true
+			}
+			java.Inspect(x.X, visit)
+			return false
+		case *java.VarDecl:
+			if x.Init != nil {
+				java.Inspect(x.Init, visit)
+			}
+			return false
+		case *java.LabeledStmt:
+			java.Inspect(x.Stmt, visit)
+			return false
+		case *java.Type, *java.Param, *java.TypePattern, *java.BranchStmt:
+			return false
+		}
+		return true
+	}
+	java.Inspect(body, visit)
+	return used, calls
+}
@@ -0,0 +1,107 @@
+// Package complexity 函数级复杂度度量，供分析器和规则共用
+package complexity
+
+import (
+	"go/ast"
+	"go/token"
+)
+
+// GoCognitiveComplexity 计算Go函数的认知复杂度：
+// 分支和循环结构+1并叠加当前嵌套层级，else/else if、带标签的跳转+1，
+// 每段相同的逻辑运算符序列+1，闭包使嵌套层级加深
+func GoCognitiveComplexity(fn ast.Node) int {
+	c := &goCognitive{counted: make(map[*ast.BinaryExpr]bool)}
+	ast.Walk(cognitiveVisitor{c: c}, fn)
+	return c.total
+}
+
+type goCognitive struct {
+	total   int
+	counted map[*ast.BinaryExpr]bool
+}
+
+type cognitiveVisitor struct {
+	c       *goCognitive
+	nesting int
+}
+
+func (v cognitiveVisitor) nested() cognitiveVisitor {
+	return cognitiveVisitor{c: v.c, nesting: v.nesting + 1}
+}
+
+func (v cognitiveVisitor) Visit(n ast.Node) ast.Visitor {
+	switch node := n.(type) {
+	case *ast.IfStmt:
+		v.visitIf(node, false)
+		return nil
+	case *ast.ForStmt, *ast.RangeStmt, *ast.SwitchStmt, *ast.TypeSwitchStmt, *ast.SelectStmt:
+		v.c.total += 1 + v.nesting
+		return v.nested()
+	case *ast.FuncLit:
+		return v.nested()
+	case *ast.BranchStmt:
+		if node.Label != nil {
+			v.c.total++
+		}
+	case *ast.BinaryExpr:
+		if isLogical(node.Op) && !v.c.counted[node] {
+			v.c.total += v.c.logicalSequences(node)
+		}
+	}
+	return v
+}
+
+// visitIf 处理if/else if链，else if和else只加1，不叠加嵌套层级
+func (v cognitiveVisitor) visitIf(stmt *ast.IfStmt, elseIf bool) {
+	if elseIf {
+		v.c.total++
+	} else {
+		v.c.total += 1 + v.nesting
+	}
+	inner := v.nested()
+	if stmt.Init != nil {
+		ast.Walk(v, stmt.Init)
+	}
+	ast.Walk(v, stmt.Cond)
+	ast.Walk(inner, stmt.Body)
+	switch els := stmt.Else.(type) {
+	case *ast.IfStmt:
+		v.visitIf(els, true)
+	case *ast.BlockStmt:
+		v.c.total++
+		ast.Walk(inner, els)
+	}
+}
+
+// logicalSequences 统计逻辑表达式中相同运算符的连续段数，如 a && b || c 为2
+func (c *goCognitive) logicalSequences(expr *ast.BinaryExpr) int {
+	var ops []token.Token
+	var flatten func(e ast.Expr)
+	flatten = func(e ast.Expr) {
+		switch x := e.(type) {
+		case *ast.ParenExpr:
+			flatten(x.X)
+		case *ast.BinaryExpr:
+			if !isLogical(x.Op) {
+				return
+			}
+			c.counted[x] = true
+			flatten(x.X)
+			ops = append(ops, x.Op)
+			flatten(x.Y)
+		}
+	}
+	flatten(expr)
+
+	count := 0
+	for i, op := range ops {
+		if i == 0 || op != ops[i-1] {
+			count++
+		}
+	}
+	return count
+}
+
+func isLogical(op token.Token) bool {
+	return op == token.LAND || op == token.LOR
+}
@@ -0,0 +1,112 @@
+package complexity
+
+import (
+	"go/scanner"
+	"go/token"
+	"math"
+
+	"github.com/liujinliang/lang-checker/internal/java"
+	"github.com/liujinliang/lang-checker/internal/models"
+)
+
+// HalsteadCounter 累计Halstead度量的操作符和操作数
+type HalsteadCounter struct {
+	operators      map[string]int
+	operands       map[string]int
+	totalOperators int
+	totalOperands  int
+}
+
+// NewHalsteadCounter 创建计数器
+func NewHalsteadCounter() *HalsteadCounter {
+	return &HalsteadCounter{
+		operators: make(map[string]int),
+		operands:  make(map[string]int),
+	}
+}
+
+// Operator 记录一个操作符
+func (h *HalsteadCounter) Operator(text string) {
+	h.operators[text]++
+	h.totalOperators++
+}
+
+// Operand 记录一个操作数
+func (h *HalsteadCounter) Operand(text string) {
+	h.operands[text]++
+	h.totalOperands++
+}
+
+// Result 计算词汇量、长度、体积、难度和工作量
+func (h *HalsteadCounter) Result() models.Halstead {
+	r := models.Halstead{
+		DistinctOperators: len(h.operators),
+		DistinctOperands:  len(h.operands),
+		TotalOperators:    h.totalOperators,
+		TotalOperands:     h.totalOperands,
+	}
+	r.Vocabulary = r.DistinctOperators + r.DistinctOperands
+	r.Length = r.TotalOperators + r.TotalOperands
+	if r.Vocabulary > 0 {
+		r.Volume = float64(r.Length) * math.Log2(float64(r.Vocabulary))
+	}
+	if r.DistinctOperands > 0 {
+		r.Difficulty = float64(r.DistinctOperators) / 2 * float64(r.TotalOperands) / float64(r.DistinctOperands)
+	}
+	r.Effort = r.Difficulty * r.Volume
+	return r
+}
+
+// GoHalstead 扫描Go源码片段计算Halstead度量：标识符和字面量为操作数，关键字和运算符为操作符，
+// 成对括号只计开括号，注释和自动插入的分号不计
+func GoHalstead(src []byte) models.Halstead {
+	h := NewHalsteadCounter()
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(src))
+	var s scanner.Scanner
+	// 片段可能不是完整文件，忽略扫描错误
+	s.Init(file, src, func(token.Position, string) {}, 0)
+	for {
+		_, tok, lit := s.Scan()
+		switch {
+		case tok == token.EOF:
+			return h.Result()
+		case tok == token.SEMICOLON && lit == "\n",
+			tok == token.RPAREN, tok == token.RBRACK, tok == token.RBRACE:
+		case tok == token.IDENT || tok.IsLiteral():
+			h.Operand(lit)
+		default:
+			h.Operator(tok.String())
+		}
+	}
+}
+
+// JavaHalstead 基于Java词法单元计算Halstead度量，规则与GoHalstead一致，
+// true/false/null/this/super视为操作数
+func JavaHalstead(toks []java.Token) models.Halstead {
+	h := NewHalsteadCounter()
+	for _, tok := range toks {
+		switch {
+		case tok.Kind.IsComment(), tok.Is(")"), tok.Is("]"), tok.Is("}"):
+		case tok.Kind == java.Ident || tok.Kind.IsLiteral():
+			h.Operand(tok.Text)
+		case tok.Is("true"), tok.Is("false"), tok.Is("null"), tok.Is("this"), tok.Is("super"):
+			h.Operand(tok.Text)
+		default:
+			h.Operator(tok.Text)
+		}
+	}
+	return h.Result()
+}
+
+// MaintainabilityIndex 计算经典可维护性指数（含注释项）：
+// MI = 171 - 5.2·ln(V) - 0.23·CC - 16.2·ln(LOC) + 50·sin(√(2.4·CM))，
+// commentRatio为注释行占比（百分比），V和LOC小于1时按1计
+func MaintainabilityIndex(volume, cyclomatic, loc, commentRatio float64) float64 {
+	cm := commentRatio / 100
+	return 171 -
+		5.2*math.Log(math.Max(volume, 1)) -
+		0.23*cyclomatic -
+		16.2*math.Log(math.Max(loc, 1)) +
+		50*math.Sin(math.Sqrt(2.4*cm))
+}
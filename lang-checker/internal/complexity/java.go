@@ -0,0 +1,170 @@
+package complexity
+
+import "github.com/liujinliang/lang-checker/internal/java"
+
+// JavaCyclomaticComplexity 计算圈复杂度：1 + if、for、while、do、catch、带标签的case分支以及三元运算符的个数
+func JavaCyclomaticComplexity(node java.Node) int {
+	count := 1
+	java.Inspect(node, func(n java.Node) bool {
+		switch n := n.(type) {
+		case *java.IfStmt, *java.ForStmt, *java.ForEachStmt, *java.WhileStmt, *java.DoStmt,
+			*java.CatchClause, *java.CondExpr:
+			count++
+		case *java.SwitchCase:
+			if len(n.Labels) > 0 {
+				count++
+			}
+		}
+		return true
+	})
+	return count
+}
+
+// JavaMaxNesting 计算方法体内控制结构的最大嵌套深度，else if与if处于同一层级
+func JavaMaxNesting(body java.Node) int {
+	maxDepth := 0
+	java.Walk(javaNestingVisitor{max: &maxDepth}, body)
+	return maxDepth
+}
+
+type javaNestingVisitor struct {
+	depth int
+	max   *int
+}
+
+func (v javaNestingVisitor) enter() javaNestingVisitor {
+	inner := javaNestingVisitor{depth: v.depth + 1, max: v.max}
+	*v.max = max(*v.max, inner.depth)
+	return inner
+}
+
+func (v javaNestingVisitor) Visit(n java.Node) java.Visitor {
+	switch n := n.(type) {
+	case *java.IfStmt:
+		inner := v.enter()
+		java.Walk(v, n.Cond)
+		java.Walk(inner, n.Then)
+		if elseIf, ok := n.Else.(*java.IfStmt); ok {
+			java.Walk(v, elseIf)
+		} else if n.Else != nil {
+			java.Walk(inner, n.Else)
+		}
+		return nil
+	case *java.TryStmt:
+		inner := v.enter()
+		for _, r := range n.Resources {
+			java.Walk(v, r)
+		}
+		java.Walk(inner, n.Body)
+		for _, c := range n.Catches {
+			java.Walk(inner, c.Body)
+		}
+		if n.Finally != nil {
+			java.Walk(inner, n.Finally)
+		}
+		return nil
+	case *java.ForStmt, *java.ForEachStmt, *java.WhileStmt, *java.DoStmt,
+		*java.SwitchStmt, *java.SwitchExpr, *java.SyncStmt:
+		return v.enter()
+	}
+	return v
+}
+
+// JavaCognitiveComplexity 计算Java方法体的认知复杂度，规则与GoCognitiveComplexity一致：
+// 分支、循环、switch、catch和三元运算符+1并叠加嵌套层级，else/else if、带标签的跳转+1，
+// 每段相同的逻辑运算符序列+1，lambda、匿名类和局部类使嵌套层级加深
+func JavaCognitiveComplexity(body java.Node) int {
+	c := &javaCognitive{counted: make(map[*java.BinaryExpr]bool)}
+	java.Walk(javaCognitiveVisitor{c: c}, body)
+	return c.total
+}
+
+type javaCognitive struct {
+	total   int
+	counted map[*java.BinaryExpr]bool
+}
+
+type javaCognitiveVisitor struct {
+	c       *javaCognitive
+	nesting int
+}
+
+func (v javaCognitiveVisitor) nested() javaCognitiveVisitor {
+	return javaCognitiveVisitor{c: v.c, nesting: v.nesting + 1}
+}
+
+func (v javaCognitiveVisitor) Visit(n java.Node) java.Visitor {
+	switch node := n.(type) {
+	case *java.IfStmt:
+		v.visitIf(node, false)
+		return nil
+	case *java.ForStmt, *java.ForEachStmt, *java.WhileStmt, *java.DoStmt,
+		*java.SwitchStmt, *java.SwitchExpr, *java.CatchClause, *java.CondExpr:
+		v.c.total += 1 + v.nesting
+		return v.nested()
+	case *java.LambdaExpr, *java.TypeDecl:
+		return v.nested()
+	case *java.BranchStmt:
+		if node.Label != nil {
+			v.c.total++
+		}
+	case *java.BinaryExpr:
+		if isJavaLogical(node.Op) && !v.c.counted[node] {
+			v.c.total += v.c.logicalSequences(node)
+		}
+	}
+	return v
+}
+
+// visitIf 处理if/else if链，else if和else只加1，不叠加嵌套层级
+func (v javaCognitiveVisitor) visitIf(stmt *java.IfStmt, elseIf bool) {
+	if elseIf {
+		v.c.total++
+	} else {
+		v.c.total += 1 + v.nesting
+	}
+	inner := v.nested()
+	java.Walk(v, stmt.Cond)
+	java.Walk(inner, stmt.Then)
+	switch els := stmt.Else.(type) {
+	case *java.IfStmt:
+		v.visitIf(els, true)
+	case nil:
+	default:
+		v.c.total++
+		java.Walk(inner, els)
+	}
+}
+
+// logicalSequences 统计逻辑表达式中相同运算符的连续段数，如 a && b || c 为2
+func (c *javaCognitive) logicalSequences(expr *java.BinaryExpr) int {
+	var ops []string
+	var flatten func(e java.Expr)
+	flatten = func(e java.Expr) {
+		switch x := e.(type) {
+		case *java.ParenExpr:
+			flatten(x.X)
+		case *java.BinaryExpr:
+			if !isJavaLogical(x.Op) {
+				return
+			}
+			c.counted[x] = true
+			flatten(x.X)
+			ops = append(ops, x.Op)
+			flatten(x.Y)
+		}
+	}
+	flatten(expr)
+
+	count := 0
+	for i, op := range ops {
+		if i == 0 || op != ops[i-1] {
+			count++
+		}
+	}
+	return count
+}
+
+func isJavaLogical(op string) bool {
+	return op == "&&" || op == "||"
+}
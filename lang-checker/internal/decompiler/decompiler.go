@@ -0,0 +1,294 @@
+// Package decompiler 通过可配置的命令模板调用外部反编译器（CFR、Procyon、jd-cli等），把class文件还原为Java源码
+package decompiler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// 内置反编译器名称
+const (
+	CFR     = "cfr"
+	Procyon = "procyon"
+	JDCLI   = "jd-cli"
+)
+
+// 命令模板中的占位符
+const (
+	// PlaceholderInput 待反编译的class文件路径
+	PlaceholderInput = "{input}"
+	// PlaceholderOutput 反编译结果的输出目录，反编译器把源码写到标准输出时可以不使用
+	PlaceholderOutput = "{output}"
+	// PlaceholderClasspath class文件所在的根目录，内部类的class文件也在其中
+	PlaceholderClasspath = "{classpath}"
+	// PlaceholderClass 类的全限定名，如 com.example.Foo
+	PlaceholderClass = "{class}"
+)
+
+// maxOutput 反编译器标准输出和错误输出的保留上限
+const maxOutput = 4 << 20
+
+var (
+	// ErrTimeout 反编译超过时限
+	ErrTimeout = errors.New("反编译超时")
+	// ErrNoOutput 反编译器正常退出但没有生成源码
+	ErrNoOutput = errors.New("未生成源码")
+	// ErrUnsafePath 类名或class文件的包内路径含..或为绝对路径，写出时会越出临时目录
+	ErrUnsafePath = errors.New("非法的class文件路径")
+)
+
+// Decompiler 通过命令模板调用的反编译器
+type Decompiler struct {
+	// Name 名称，标注在反编译出的源码的分析结果中
+	Name string
+	// Command 命令及参数，其中的{input}、{output}、{classpath}和{class}在执行时替换
+	Command []string
+}
+
+// Defaults 返回libDir下CFR、Procyon和jd-cli的默认命令模板，依次作为回退顺序
+func Defaults(libDir string) []Decompiler {
+	jar := func(name string) string { return filepath.Join(libDir, name) }
+	return []Decompiler{
+		{Name: CFR, Command: []string{"java", "-jar", jar("cfr-0.152.jar"), PlaceholderInput, "--outputdir", PlaceholderOutput, "--silent", "true"}},
+		{Name: Procyon, Command: []string{"java", "-jar", jar("procyon-decompiler-0.6.0.jar"), "-o", PlaceholderOutput, PlaceholderInput}},
+		{Name: JDCLI, Command: []string{"java", "-jar", jar("jd-cli-1.2.1.jar"), "-od", PlaceholderOutput, PlaceholderInput}},
+	}
+}
+
+// Parse 解析 名称=命令模板 形式的反编译器定义，命令模板按空白分隔参数，如
+// procyon=java -jar /opt/procyon.jar -o {output} {input}
+func Parse(spec string) (Decompiler, error) {
+	name, command, ok := strings.Cut(spec, "=")
+	name = strings.TrimSpace(name)
+	if !ok || name == "" {
+		return Decompiler{}, fmt.Errorf("反编译器定义 %q 应为 名称=命令模板", spec)
+	}
+	d := Decompiler{Name: name, Command: strings.Fields(command)}
+	if len(d.Command) == 0 {
+		return Decompiler{}, fmt.Errorf("反编译器 %s 的命令模板为空", name)
+	}
+	if !strings.Contains(command, PlaceholderInput) && !strings.Contains(command, PlaceholderClass) {
+		return Decompiler{}, fmt.Errorf("反编译器 %s 的命令模板缺少%s或%s", name, PlaceholderInput, PlaceholderClass)
+	}
+	return d, nil
+}
+
+// Check 检查反编译器能否执行：命令在PATH中存在，模板中引用的JAR包（不含占位符的.jar参数）存在
+func (d Decompiler) Check() error {
+	if len(d.Command) == 0 {
+		return errors.New("命令模板为空")
+	}
+	if _, err := exec.LookPath(d.Command[0]); err != nil {
+		return err
+	}
+	for _, arg := range d.Command[1:] {
+		if strings.HasSuffix(arg, ".jar") && !strings.Contains(arg, "{") {
+			if _, err := os.Stat(arg); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Select 按名称选出反编译器，custom中的定义优先于libDir下的默认模板
+func Select(names []string, libDir string, custom []Decompiler) ([]Decompiler, error) {
+	known := make(map[string]Decompiler)
+	for _, d := range Defaults(libDir) {
+		known[d.Name] = d
+	}
+	for _, d := range custom {
+		known[d.Name] = d
+	}
+	var selected []Decompiler
+	for _, name := range names {
+		d, ok := known[strings.TrimSpace(name)]
+		if !ok {
+			return nil, fmt.Errorf("未知的反编译器: %s", name)
+		}
+		selected = append(selected, d)
+	}
+	return selected, nil
+}
+
+// Class 待反编译的顶层类，内部类和匿名类的class文件一并提供，反编译器会把它们合并到顶层类的源码中
+type Class struct {
+	// Name 顶层类的内部名称，如 com/example/Foo
+	Name string
+	// Files 包内路径到class文件内容，如 com/example/Foo$Bar.class
+	Files map[string][]byte
+}
+
+// Result 反编译结果
+type Result struct {
+	// Decompiler 成功的反编译器名称
+	Decompiler string
+	// Source 反编译出的Java源码
+	Source []byte
+}
+
+// Decompile 依次尝试decompilers反编译class，返回第一个成功的结果。每个反编译器处理该类的时限为timeout（<=0不限制），
+// 失败、超时或没有生成源码时换下一个；全部失败时返回的错误包含每个反编译器的失败原因。
+// 类名和class文件路径来自JAR包，含..或为绝对路径时返回ErrUnsafePath
+func Decompile(ctx context.Context, decompilers []Decompiler, class Class, timeout time.Duration) (*Result, error) {
+	if len(decompilers) == 0 {
+		return nil, errors.New("未配置反编译器")
+	}
+	if !filepath.IsLocal(class.Name) {
+		return nil, fmt.Errorf("%w: %s", ErrUnsafePath, class.Name)
+	}
+	for name := range class.Files {
+		if !filepath.IsLocal(name) {
+			return nil, fmt.Errorf("%w: %s", ErrUnsafePath, name)
+		}
+	}
+	dir, err := os.MkdirTemp("", "lang-checker-decompile-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	classpath := filepath.Join(dir, "classes")
+	for name, data := range class.Files {
+		file := filepath.Join(classpath, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(file, data, 0o644); err != nil {
+			return nil, err
+		}
+	}
+
+	var errs []error
+	for i, d := range decompilers {
+		output := filepath.Join(dir, fmt.Sprintf("out%d", i))
+		if err := os.Mkdir(output, 0o755); err != nil {
+			return nil, err
+		}
+		src, err := run(ctx, d, class.Name, classpath, output, timeout)
+		if err == nil {
+			return &Result{Decompiler: d.Name, Source: src}, nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", d.Name, err))
+	}
+	return nil, errors.Join(errs...)
+}
+
+// run 执行一个反编译器并读取生成的源码
+func run(ctx context.Context, d Decompiler, name, classpath, output string, timeout time.Duration) ([]byte, error) {
+	if len(d.Command) == 0 {
+		return nil, errors.New("命令模板为空")
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	replacer := strings.NewReplacer(
+		PlaceholderInput, filepath.Join(classpath, filepath.FromSlash(name)+".class"),
+		PlaceholderOutput, output,
+		PlaceholderClasspath, classpath,
+		PlaceholderClass, strings.ReplaceAll(name, "/", "."),
+	)
+	args := make([]string, len(d.Command))
+	for i, arg := range d.Command {
+		args[i] = replacer.Replace(arg)
+	}
+
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Dir = classpath
+	stdout, stderr := &limitedBuffer{max: maxOutput}, &limitedBuffer{max: maxOutput}
+	cmd.Stdout, cmd.Stderr = stdout, stderr
+	// 被终止的反编译器可能留下仍占用输出管道的子进程，不无限等待
+	cmd.WaitDelay = time.Second
+
+	err := cmd.Run()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return nil, fmt.Errorf("%w（%s）", ErrTimeout, timeout)
+	}
+	if err != nil {
+		if msg := firstLine(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%w: %s", err, msg)
+		}
+		return nil, err
+	}
+
+	src, err := findSource(output, name)
+	if err != nil {
+		return nil, err
+	}
+	if src == nil && len(bytes.TrimSpace(stdout.Bytes())) > 0 {
+		// 没有写文件的反编译器（如CFR不带--outputdir）把源码输出到标准输出
+		src = stdout.Bytes()
+	}
+	if len(bytes.TrimSpace(src)) == 0 {
+		return nil, ErrNoOutput
+	}
+	return src, nil
+}
+
+// findSource 在输出目录中查找反编译出的源码：优先按包路径查找，其次是同名的.java文件，最后是任意.java文件
+func findSource(output, name string) ([]byte, error) {
+	expected := filepath.Join(output, filepath.FromSlash(name)+".java")
+	if data, err := os.ReadFile(expected); err == nil {
+		return data, nil
+	}
+
+	base := name[strings.LastIndexByte(name, '/')+1:] + ".java"
+	var sameName, first string
+	err := filepath.WalkDir(output, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(path, ".java") {
+			return err
+		}
+		if first == "" {
+			first = path
+		}
+		if sameName == "" && d.Name() == base {
+			sameName = path
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case sameName != "":
+		return os.ReadFile(sameName)
+	case first != "":
+		return os.ReadFile(first)
+	}
+	return nil, nil
+}
+
+func firstLine(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		s = s[:i]
+	}
+	return strings.TrimSpace(s)
+}
+
+// limitedBuffer 只保留前max字节的输出，其余丢弃，避免反编译器的大量输出占满内存
+type limitedBuffer struct {
+	bytes.Buffer
+	max int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := b.max - b.Len(); room > 0 {
+		b.Buffer.Write(p[:min(len(p), room)])
+	}
+	return len(p), nil
+}
@@ -0,0 +1,192 @@
+package decompiler
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// 桩反编译器：参数为 {output} {input} {class}，确认class文件存在后按包路径写出源码
+const okScript = `#!/bin/sh
+[ -f "$2" ] || { echo "missing $2" >&2; exit 3; }
+file="$1/$(echo "$3" | tr . /).java"
+mkdir -p "$(dirname "$file")"
+printf 'package com.example;\n\npublic class Foo {\n    public int answer() { return 42; }\n}\n' > "$file"
+`
+
+const failScript = `#!/bin/sh
+echo "boom: unsupported class version" >&2
+exit 1
+`
+
+const slowScript = `#!/bin/sh
+exec sleep 30
+`
+
+const emptyScript = `#!/bin/sh
+exit 0
+`
+
+const stdoutScript = `#!/bin/sh
+echo "public class Foo {}"
+`
+
+var testClass = Class{
+	Name: "com/example/Foo",
+	Files: map[string][]byte{
+		"com/example/Foo.class":     {0xCA, 0xFE, 0xBA, 0xBE},
+		"com/example/Foo$Bar.class": {0xCA, 0xFE, 0xBA, 0xBE},
+	},
+}
+
+func stub(t *testing.T, name, script string) Decompiler {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("桩反编译器是shell脚本")
+	}
+	path := filepath.Join(t.TempDir(), name+".sh")
+	if err := os.WriteFile(path, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return Decompiler{Name: name, Command: []string{path, PlaceholderOutput, PlaceholderInput, PlaceholderClass}}
+}
+
+func TestDecompile(t *testing.T) {
+	result, err := Decompile(context.Background(), []Decompiler{stub(t, "ok", okScript)}, testClass, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Decompiler != "ok" || !strings.Contains(string(result.Source), "public class Foo") {
+		t.Errorf("结果 = %s %q", result.Decompiler, result.Source)
+	}
+}
+
+func TestDecompileFallback(t *testing.T) {
+	decompilers := []Decompiler{stub(t, "fail", failScript), stub(t, "empty", emptyScript), stub(t, "ok", okScript)}
+	result, err := Decompile(context.Background(), decompilers, testClass, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Decompiler != "ok" {
+		t.Errorf("Decompiler = %s, want ok", result.Decompiler)
+	}
+}
+
+func TestDecompileTimeout(t *testing.T) {
+	decompilers := []Decompiler{stub(t, "slow", slowScript), stub(t, "ok", okScript)}
+	start := time.Now()
+	result, err := Decompile(context.Background(), decompilers, testClass, 200*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Decompiler != "ok" {
+		t.Errorf("Decompiler = %s, want ok", result.Decompiler)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("超时的反编译器未被终止，耗时%s", elapsed)
+	}
+}
+
+func TestDecompileAllFailed(t *testing.T) {
+	decompilers := []Decompiler{stub(t, "fail", failScript), stub(t, "slow", slowScript), stub(t, "empty", emptyScript)}
+	_, err := Decompile(context.Background(), decompilers, testClass, 200*time.Millisecond)
+	if err == nil {
+		t.Fatal("全部失败时应返回错误")
+	}
+	if !errors.Is(err, ErrTimeout) || !errors.Is(err, ErrNoOutput) {
+		t.Errorf("错误应包含每个反编译器的原因: %v", err)
+	}
+	if !strings.Contains(err.Error(), "boom") {
+		t.Errorf("错误应包含反编译器的错误输出: %v", err)
+	}
+}
+
+func TestDecompileUnsafePath(t *testing.T) {
+	target := filepath.Join(t.TempDir(), "Evil.class")
+	rel, err := filepath.Rel(os.TempDir(), target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 临时目录下的classes目录再向上两级才是os.TempDir()
+	name := filepath.ToSlash(filepath.Join("..", "..", rel))
+	for _, class := range []Class{
+		{Name: "com/example/Foo", Files: map[string][]byte{name: {0xCA, 0xFE, 0xBA, 0xBE}}},
+		{Name: "../../com/example/Foo", Files: map[string][]byte{"com/example/Foo.class": {0xCA, 0xFE, 0xBA, 0xBE}}},
+		{Name: "com/example/Foo", Files: map[string][]byte{target: {0xCA, 0xFE, 0xBA, 0xBE}}},
+	} {
+		_, err := Decompile(context.Background(), []Decompiler{stub(t, "ok", okScript)}, class, time.Minute)
+		if !errors.Is(err, ErrUnsafePath) {
+			t.Errorf("Decompile(%s) err = %v, want ErrUnsafePath", class.Name, err)
+		}
+	}
+	if _, err := os.Stat(target); !os.IsNotExist(err) {
+		t.Errorf("不应写出临时目录之外的文件: %v", err)
+	}
+}
+
+func TestDecompileStdout(t *testing.T) {
+	result, err := Decompile(context.Background(), []Decompiler{stub(t, "stdout", stdoutScript)}, testClass, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(string(result.Source)) != "public class Foo {}" {
+		t.Errorf("Source = %q", result.Source)
+	}
+}
+
+func TestDecompileCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+	_, err := Decompile(ctx, []Decompiler{stub(t, "slow", slowScript), stub(t, "ok", okScript)}, testClass, 0)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+}
+
+func TestParseAndSelect(t *testing.T) {
+	d, err := Parse("mine = java -jar /opt/mine.jar {input} -d {output}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Name != "mine" || len(d.Command) != 6 || d.Command[3] != PlaceholderInput {
+		t.Errorf("Parse = %+v", d)
+	}
+	for _, bad := range []string{"java -jar x.jar", "=java {input}", "x=", "x=java -jar x.jar"} {
+		if _, err := Parse(bad); err == nil {
+			t.Errorf("Parse(%q) 应返回错误", bad)
+		}
+	}
+
+	selected, err := Select([]string{"procyon", "mine", " cfr"}, "/opt/libs", []Decompiler{d})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(selected) != 3 || selected[0].Name != Procyon || selected[1].Name != "mine" || selected[2].Name != CFR {
+		t.Errorf("Select = %+v", selected)
+	}
+	if selected[0].Command[2] != filepath.Join("/opt/libs", "procyon-decompiler-0.6.0.jar") {
+		t.Errorf("默认模板应使用libs目录: %v", selected[0].Command)
+	}
+	if _, err := Select([]string{"fernflower"}, "libs", nil); err == nil {
+		t.Error("未知的反编译器应返回错误")
+	}
+}
+
+func TestCheck(t *testing.T) {
+	if err := stub(t, "ok", okScript).Check(); err != nil {
+		t.Errorf("Check() = %v", err)
+	}
+	if err := (Decompiler{Name: "x", Command: []string{"lang-checker-no-such-command"}}).Check(); err == nil {
+		t.Error("命令不存在时应返回错误")
+	}
+	sh := stub(t, "ok", okScript).Command[0]
+	missing := Decompiler{Name: "x", Command: []string{sh, "-jar", filepath.Join(t.TempDir(), "missing.jar"), PlaceholderInput}}
+	if err := missing.Check(); err == nil {
+		t.Error("JAR包不存在时应返回错误")
+	}
+}
@@ -0,0 +1,146 @@
+// Package depgraph 计算Go模块内包之间的依赖图与耦合度指标
+package depgraph
+
+import (
+	"go/types"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/liujinliang/lang-checker/internal/gopkg"
+	"github.com/liujinliang/lang-checker/internal/models"
+)
+
+// Build 根据已加载的包构建依赖报告，只统计模块内部包之间的依赖
+func Build(mod *gopkg.Module, pkgs []*gopkg.Package) *models.DependencyReport {
+	internal := make(map[string]*gopkg.Package, len(pkgs))
+	for _, pkg := range pkgs {
+		internal[pkg.ImportPath] = pkg
+	}
+
+	deps := make(map[string][]string, len(pkgs))
+	dependents := make(map[string][]string, len(pkgs))
+	for _, pkg := range pkgs {
+		for _, imp := range pkg.Imports {
+			if _, ok := internal[imp]; !ok {
+				continue
+			}
+			deps[pkg.ImportPath] = append(deps[pkg.ImportPath], imp)
+			dependents[imp] = append(dependents[imp], pkg.ImportPath)
+		}
+	}
+
+	report := &models.DependencyReport{Module: mod.Path}
+	for _, pkg := range pkgs {
+		path := pkg.ImportPath
+		sort.Strings(dependents[path])
+
+		m := models.PackageMetrics{
+			ImportPath:   path,
+			Dir:          pkg.Dir,
+			Afferent:     len(dependents[path]),
+			Efferent:     len(deps[path]),
+			Dependencies: deps[path],
+			Dependents:   dependents[path],
+		}
+		m.AbstractTypes, m.TotalTypes = countTypes(pkg.Types)
+		if total := m.Afferent + m.Efferent; total > 0 {
+			m.Instability = float64(m.Efferent) / float64(total)
+		}
+		if m.TotalTypes > 0 {
+			m.Abstractness = float64(m.AbstractTypes) / float64(m.TotalTypes)
+		}
+		m.Distance = math.Abs(m.Abstractness + m.Instability - 1)
+		report.Packages = append(report.Packages, m)
+	}
+	sort.Slice(report.Packages, func(i, j int) bool {
+		return report.Packages[i].ImportPath < report.Packages[j].ImportPath
+	})
+
+	report.Cycles = findCycles(report.Packages, deps)
+	return report
+}
+
+// countTypes 统计包级命名类型数量，接口类型视为抽象类型
+func countTypes(pkg *types.Package) (abstract, total int) {
+	if pkg == nil {
+		return 0, 0
+	}
+	scope := pkg.Scope()
+	for _, name := range scope.Names() {
+		tn, ok := scope.Lookup(name).(*types.TypeName)
+		if !ok || tn.IsAlias() {
+			continue
+		}
+		total++
+		if types.IsInterface(tn.Type()) {
+			abstract++
+		}
+	}
+	return abstract, total
+}
+
+// findCycles 使用Tarjan算法查找强连通分量，包含多个包或自依赖的分量即为导入环
+func findCycles(pkgs []models.PackageMetrics, deps map[string][]string) [][]string {
+	index := 0
+	indices := make(map[string]int)
+	lowlink := make(map[string]int)
+	onStack := make(map[string]bool)
+	var stack []string
+	var cycles [][]string
+
+	var strongConnect func(v string)
+	strongConnect = func(v string) {
+		indices[v] = index
+		lowlink[v] = index
+		index++
+		stack = append(stack, v)
+		onStack[v] = true
+
+		for _, w := range deps[v] {
+			if _, visited := indices[w]; !visited {
+				strongConnect(w)
+				lowlink[v] = min(lowlink[v], lowlink[w])
+			} else if onStack[w] {
+				lowlink[v] = min(lowlink[v], indices[w])
+			}
+		}
+
+		if lowlink[v] != indices[v] {
+			return
+		}
+		var component []string
+		for {
+			w := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[w] = false
+			component = append(component, w)
+			if w == v {
+				break
+			}
+		}
+		if len(component) > 1 || dependsOnSelf(v, deps) {
+			sort.Strings(component)
+			cycles = append(cycles, component)
+		}
+	}
+
+	for _, p := range pkgs {
+		if _, visited := indices[p.ImportPath]; !visited {
+			strongConnect(p.ImportPath)
+		}
+	}
+	sort.Slice(cycles, func(i, j int) bool {
+		return strings.Join(cycles[i], ",") < strings.Join(cycles[j], ",")
+	})
+	return cycles
+}
+
+func dependsOnSelf(v string, deps map[string][]string) bool {
+	for _, w := range deps[v] {
+		if w == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+package depgraph
+
+import (
+	"context"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/liujinliang/lang-checker/internal/gopkg"
+	"github.com/liujinliang/lang-checker/internal/models"
+)
+
+func build(t *testing.T, files map[string]string) *models.DependencyReport {
+	t.Helper()
+	dir := t.TempDir()
+	files["go.mod"] = "module example.com/m\n\ngo 1.21\n"
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	mod, pkgs, _, err := gopkg.Load(context.Background(), token.NewFileSet(), dir, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return Build(mod, pkgs)
+}
+
+func TestBuild(t *testing.T) {
+	report := build(t, map[string]string{
+		"api/api.go":   "package api\n\ntype Store interface{ Get() int }\n",
+		"impl/impl.go": "package impl\n\nimport (\n\t\"fmt\"\n\n\t\"example.com/m/api\"\n)\n\ntype S struct{}\n\nfunc (S) Get() int { fmt.Println(); return 1 }\n\nvar _ api.Store = S{}\n",
+		"app/app.go":   "package app\n\nimport (\n\t\"example.com/m/api\"\n\t\"example.com/m/impl\"\n)\n\nvar s api.Store = impl.S{}\n",
+	})
+	if report.Module != "example.com/m" || len(report.Cycles) != 0 {
+		t.Fatalf("report = %+v", report)
+	}
+	byPath := make(map[string]models.PackageMetrics)
+	for _, p := range report.Packages {
+		byPath[strings.TrimPrefix(p.ImportPath, "example.com/m/")] = p
+	}
+	tests := []struct {
+		pkg                string
+		afferent, efferent int
+		instability, abstr float64
+	}{
+		{"api", 2, 0, 0, 1},
+		{"impl", 1, 1, 0.5, 0},
+		{"app", 0, 2, 1, 0},
+	}
+	for _, tt := range tests {
+		p := byPath[tt.pkg]
+		if p.Afferent != tt.afferent || p.Efferent != tt.efferent || p.Instability != tt.instability || p.Abstractness != tt.abstr {
+			t.Errorf("%s: Ca=%d Ce=%d I=%v A=%v, want Ca=%d Ce=%d I=%v A=%v", tt.pkg,
+				p.Afferent, p.Efferent, p.Instability, p.Abstractness, tt.afferent, tt.efferent, tt.instability, tt.abstr)
+		}
+	}
+	// 标准库fmt不计入依赖
+	if deps := byPath["impl"].Dependencies; !reflect.DeepEqual(deps, []string{"example.com/m/api"}) {
+		t.Errorf("impl.Dependencies = %v", deps)
+	}
+}
+
+func TestBuildCycles(t *testing.T) {
+	report := build(t, map[string]string{
+		"a/a.go": "package a\n\nimport \"example.com/m/b\"\n\nvar A = b.B\n",
+		"b/b.go": "package b\n\nimport \"example.com/m/a\"\n\nvar B = a.A\n",
+		"c/c.go": "package c\n\nimport \"example.com/m/a\"\n\nvar C = a.A\n",
+	})
+	want := [][]string{{"example.com/m/a", "example.com/m/b"}}
+	if !reflect.DeepEqual(report.Cycles, want) {
+		t.Fatalf("Cycles = %v, want %v", report.Cycles, want)
+	}
+	dot := DOT(report)
+	if !strings.Contains(dot, "color=red") {
+		t.Errorf("DOT中环上的边应标红:\n%s", dot)
+	}
+}
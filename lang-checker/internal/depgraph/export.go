@@ -0,0 +1,92 @@
+package depgraph
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/liujinliang/lang-checker/internal/models"
+)
+
+// DOT 导出Graphviz DOT格式的依赖图，导入环中的边标红
+func DOT(report *models.DependencyReport) string {
+	inCycle := cycleEdges(report)
+
+	var b strings.Builder
+	b.WriteString("digraph dependencies {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=box];\n")
+	for _, p := range report.Packages {
+		fmt.Fprintf(&b, "  %q [label=\"%s\\nI=%.2f A=%.2f D=%.2f\"];\n", p.ImportPath, shortName(report.Module, p.ImportPath), p.Instability, p.Abstractness, p.Distance)
+	}
+	for _, p := range report.Packages {
+		for _, dep := range p.Dependencies {
+			if inCycle[p.ImportPath+"->"+dep] {
+				fmt.Fprintf(&b, "  %q -> %q [color=red];\n", p.ImportPath, dep)
+			} else {
+				fmt.Fprintf(&b, "  %q -> %q;\n", p.ImportPath, dep)
+			}
+		}
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// Mermaid 导出Mermaid flowchart格式的依赖图
+func Mermaid(report *models.DependencyReport) string {
+	ids := make(map[string]string, len(report.Packages))
+	for i, p := range report.Packages {
+		ids[p.ImportPath] = fmt.Sprintf("p%d", i)
+	}
+	inCycle := cycleEdges(report)
+
+	var b strings.Builder
+	b.WriteString("graph LR\n")
+	for _, p := range report.Packages {
+		fmt.Fprintf(&b, "  %s[\"%s\"]\n", ids[p.ImportPath], shortName(report.Module, p.ImportPath))
+	}
+	edge := 0
+	var cycleLinks []string
+	for _, p := range report.Packages {
+		for _, dep := range p.Dependencies {
+			fmt.Fprintf(&b, "  %s --> %s\n", ids[p.ImportPath], ids[dep])
+			if inCycle[p.ImportPath+"->"+dep] {
+				cycleLinks = append(cycleLinks, fmt.Sprint(edge))
+			}
+			edge++
+		}
+	}
+	if len(cycleLinks) > 0 {
+		fmt.Fprintf(&b, "  linkStyle %s stroke:red\n", strings.Join(cycleLinks, ","))
+	}
+	return b.String()
+}
+
+// cycleEdges 返回两端处于同一导入环中的边
+func cycleEdges(report *models.DependencyReport) map[string]bool {
+	edges := make(map[string]bool)
+	for _, cycle := range report.Cycles {
+		members := make(map[string]bool, len(cycle))
+		for _, p := range cycle {
+			members[p] = true
+		}
+		for _, p := range report.Packages {
+			if !members[p.ImportPath] {
+				continue
+			}
+			for _, dep := range p.Dependencies {
+				if members[dep] {
+					edges[p.ImportPath+"->"+dep] = true
+				}
+			}
+		}
+	}
+	return edges
+}
+
+// shortName 去掉模块路径前缀，使图中的节点更紧凑
+func shortName(module, importPath string) string {
+	if importPath == module {
+		return importPath
+	}
+	return strings.TrimPrefix(importPath, module+"/")
+}
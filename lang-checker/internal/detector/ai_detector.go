@@ -1,6 +1,7 @@
 package detector
 
 import (
+	"context"
 	"fmt"
 	"math"
 	"regexp"
@@ -49,8 +50,8 @@ func NewAIDetector() *AIDetector {
 	}
 }
 
-// DetectAI 检测代码是否由AI生成
-func (detector *AIDetector) DetectAI(content string) *models.AIDetectionResult {
+// DetectAI 检测代码是否由AI生成，ctx被取消时返回其错误
+func (detector *AIDetector) DetectAI(ctx context.Context, content string) (*models.AIDetectionResult, error) {
 	var indicators []string
 	totalScore := 0.0
 
@@ -59,6 +60,9 @@ func (detector *AIDetector) DetectAI(content string) *models.AIDetectionResult {
 
 	// 基于模式的检测
 	for _, pattern := range detector.patterns {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 		matches := pattern.Pattern.FindAllString(content, -1)
 		if len(matches) > 0 {
 			// 计算匹配密度
@@ -72,15 +76,21 @@ func (detector *AIDetector) DetectAI(content string) *models.AIDetectionResult {
 	}
 
 	// 语言特异性检测
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	totalScore += detector.detectLanguageSpecificAI(content, &indicators)
 
 	// 统计学特征检测
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	totalScore += detector.detectStatisticalFeatures(content, &indicators)
 
 	return &models.AIDetectionResult{
 		Score:      math.Min(totalScore, 100.0),
 		Indicators: indicators,
-	}
+	}, nil
 }
 
 // detectLanguageSpecificAI 检测特定语言的AI特征
@@ -0,0 +1,207 @@
+// Package duplicate 基于归一化词法单元和滚动哈希的跨文件重复代码检测
+package duplicate
+
+import (
+	"context"
+	"hash/fnv"
+	"sort"
+
+	"github.com/liujinliang/lang-checker/internal/models"
+)
+
+// Token 归一化后的词法单元
+type Token struct {
+	Value string
+	Line  int
+}
+
+// Source 参与检测的文件
+type Source struct {
+	Path   string
+	Tokens []Token
+}
+
+// Options 检测选项
+type Options struct {
+	// MinTokens 克隆的最小词法单元数
+	MinTokens int
+	// MinLines 克隆的最小行数
+	MinLines int
+}
+
+// DefaultOptions 返回默认检测选项
+func DefaultOptions() Options {
+	return Options{MinTokens: 70, MinLines: 5}
+}
+
+const hashBase = 1000003
+
+type location struct {
+	file int
+	pos  int
+}
+
+// Detect 检测sources中的重复代码，返回克隆组以及每个文件被重复覆盖的行数
+func Detect(ctx context.Context, sources []Source, opts Options) ([]models.CloneGroup, map[string]int, error) {
+	window := opts.MinTokens
+	if window <= 0 {
+		window = DefaultOptions().MinTokens
+	}
+
+	// 1. 计算每个窗口的滚动哈希
+	values := make([][]uint64, len(sources))
+	buckets := make(map[uint64][]location)
+	var order []uint64
+	pow := uint64(1)
+	for i := 1; i < window; i++ {
+		pow *= hashBase
+	}
+	for fi, src := range sources {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+		values[fi] = make([]uint64, len(src.Tokens))
+		for i, tok := range src.Tokens {
+			values[fi][i] = hashToken(tok.Value)
+		}
+		if len(src.Tokens) < window {
+			continue
+		}
+
+		var h uint64
+		for i := 0; i < window; i++ {
+			h = h*hashBase + values[fi][i]
+		}
+		for pos := 0; ; pos++ {
+			if _, ok := buckets[h]; !ok {
+				order = append(order, h)
+			}
+			buckets[h] = append(buckets[h], location{fi, pos})
+			if pos+window >= len(src.Tokens) {
+				break
+			}
+			h = (h-values[fi][pos]*pow)*hashBase + values[fi][pos+window]
+		}
+	}
+
+	// 2. 按首次出现顺序处理每个重复窗口，尽量向后扩展并标记已覆盖的位置
+	covered := make([]map[int]bool, len(sources))
+	for i := range covered {
+		covered[i] = make(map[int]bool)
+	}
+	var groups []models.CloneGroup
+	for _, h := range order {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+		locs := buckets[h]
+		if len(locs) < 2 || covered[locs[0].file][locs[0].pos] {
+			continue
+		}
+		locs = distinctMatches(sources, locs, window)
+		if len(locs) < 2 {
+			continue
+		}
+
+		length := extend(sources, locs, window)
+		for _, l := range locs {
+			for p := l.pos; p <= l.pos+length-window; p++ {
+				covered[l.file][p] = true
+			}
+		}
+
+		group := models.CloneGroup{Tokens: length}
+		for _, l := range locs {
+			toks := sources[l.file].Tokens
+			group.Locations = append(group.Locations, models.CloneLocation{
+				FilePath:  sources[l.file].Path,
+				StartLine: toks[l.pos].Line,
+				EndLine:   toks[l.pos+length-1].Line,
+			})
+		}
+		group.Lines = group.Locations[0].EndLine - group.Locations[0].StartLine + 1
+		if group.Lines < opts.MinLines {
+			continue
+		}
+		groups = append(groups, group)
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		return groups[i].Tokens > groups[j].Tokens
+	})
+	return groups, duplicatedLines(groups), nil
+}
+
+// distinctMatches 过滤哈希冲突，并去掉同一文件内与前一处重叠的位置
+func distinctMatches(sources []Source, locs []location, window int) []location {
+	first := locs[0]
+	result := []location{first}
+	for _, l := range locs[1:] {
+		prev := result[len(result)-1]
+		if l.file == prev.file && l.pos < prev.pos+window {
+			continue
+		}
+		if equalRange(sources, first, l, window) {
+			result = append(result, l)
+		}
+	}
+	return result
+}
+
+func equalRange(sources []Source, a, b location, n int) bool {
+	ta, tb := sources[a.file].Tokens, sources[b.file].Tokens
+	for i := 0; i < n; i++ {
+		if ta[a.pos+i].Value != tb[b.pos+i].Value {
+			return false
+		}
+	}
+	return true
+}
+
+// extend 在所有位置都相同的前提下向后扩展克隆长度，不与同文件中的下一处克隆重叠
+func extend(sources []Source, locs []location, window int) int {
+	length := window
+	for {
+		first := locs[0]
+		if first.pos+length >= len(sources[first.file].Tokens) {
+			return length
+		}
+		value := sources[first.file].Tokens[first.pos+length].Value
+		for i, l := range locs {
+			toks := sources[l.file].Tokens
+			if l.pos+length >= len(toks) || toks[l.pos+length].Value != value {
+				return length
+			}
+			if i+1 < len(locs) && locs[i+1].file == l.file && l.pos+length >= locs[i+1].pos {
+				return length
+			}
+		}
+		length++
+	}
+}
+
+// duplicatedLines 统计每个文件被克隆覆盖的不重复行数
+func duplicatedLines(groups []models.CloneGroup) map[string]int {
+	lines := make(map[string]map[int]bool)
+	for _, g := range groups {
+		for _, loc := range g.Locations {
+			if lines[loc.FilePath] == nil {
+				lines[loc.FilePath] = make(map[int]bool)
+			}
+			for l := loc.StartLine; l <= loc.EndLine; l++ {
+				lines[loc.FilePath][l] = true
+			}
+		}
+	}
+	result := make(map[string]int, len(lines))
+	for path, set := range lines {
+		result[path] = len(set)
+	}
+	return result
+}
+
+func hashToken(value string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(value))
+	return h.Sum64()
+}
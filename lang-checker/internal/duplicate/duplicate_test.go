@@ -0,0 +1,117 @@
+package duplicate
+
+import (
+	"context"
+	"testing"
+)
+
+const javaMethod = `    public int sum(int[] values, int limit) {
+        int total = 0;
+        for (int i = 0; i < values.length; i++) {
+            if (values[i] > limit) {
+                total += values[i] * 2;
+            } else {
+                total -= values[i] / 3;
+            }
+        }
+        return total;
+    }
+`
+
+// renamed 与javaMethod结构相同，只有变量名和常量不同
+const renamed = `    public int add(int[] xs, int max) {
+        int acc = 1;
+        for (int j = 0; j < xs.length; j++) {
+            if (xs[j] > max) {
+                acc += xs[j] * 5;
+            } else {
+                acc -= xs[j] / 7;
+            }
+        }
+        return acc;
+    }
+`
+
+const different = `    public String join(List<String> parts) {
+        StringBuilder b = new StringBuilder();
+        for (String p : parts) {
+            b.append(p).append(',');
+        }
+        return b.toString();
+    }
+`
+
+func javaClass(name string, bodies ...string) string {
+	src := "package com.example;\n\nimport java.util.List;\n\npublic class " + name + " {\n"
+	for _, body := range bodies {
+		src += body
+	}
+	return src + "}\n"
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		opts  Options
+		// groups 期望的克隆组数，locations 第一组的位置数
+		groups, locations int
+	}{
+		{"完全相同", map[string]string{"A.java": javaClass("A", javaMethod), "B.java": javaClass("B", javaMethod)},
+			Options{MinTokens: 50, MinLines: 5}, 1, 2},
+		{"仅变量名和常量不同", map[string]string{"A.java": javaClass("A", javaMethod), "B.java": javaClass("B", renamed)},
+			Options{MinTokens: 50, MinLines: 5}, 1, 2},
+		{"同一文件内重复", map[string]string{"A.java": javaClass("A", javaMethod, renamed)},
+			Options{MinTokens: 50, MinLines: 5}, 1, 2},
+		{"三处重复", map[string]string{"A.java": javaClass("A", javaMethod), "B.java": javaClass("B", javaMethod), "C.java": javaClass("C", renamed)},
+			Options{MinTokens: 50, MinLines: 5}, 1, 3},
+		{"结构不同", map[string]string{"A.java": javaClass("A", javaMethod), "B.java": javaClass("B", different)},
+			Options{MinTokens: 50, MinLines: 5}, 0, 0},
+		{"少于最小词法单元数", map[string]string{"A.java": javaClass("A", javaMethod), "B.java": javaClass("B", javaMethod)},
+			Options{MinTokens: 500, MinLines: 5}, 0, 0},
+		{"少于最小行数", map[string]string{"A.java": javaClass("A", javaMethod), "B.java": javaClass("B", javaMethod)},
+			Options{MinTokens: 50, MinLines: 50}, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sources []Source
+			for _, path := range []string{"A.java", "B.java", "C.java"} {
+				if src, ok := tt.files[path]; ok {
+					sources = append(sources, Source{Path: path, Tokens: JavaTokens(src)})
+				}
+			}
+			groups, lines, err := Detect(context.Background(), sources, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(groups) != tt.groups {
+				t.Fatalf("%d个克隆组, want %d: %+v", len(groups), tt.groups, groups)
+			}
+			if tt.groups == 0 {
+				if len(lines) != 0 {
+					t.Errorf("lines = %v, want empty", lines)
+				}
+				return
+			}
+			if n := len(groups[0].Locations); n != tt.locations {
+				t.Errorf("%d处位置, want %d: %+v", n, tt.locations, groups[0].Locations)
+			}
+			if lines["A.java"] < 5 {
+				t.Errorf("A.java重复行数 = %d", lines["A.java"])
+			}
+		})
+	}
+}
+
+func TestGoTokensSkipsImports(t *testing.T) {
+	a := GoTokens("package a\n\nimport \"fmt\"\n\nfunc f() { fmt.Println(1) }\n")
+	b := GoTokens("package b\n\nimport (\n\t\"fmt\"\n\t\"os\"\n)\n\n// 注释\nfunc g() { fmt.Println(2) }\n")
+	if len(a) != len(b) {
+		t.Fatalf("tokens = %v, %v", a, b)
+	}
+	for i := range a {
+		if a[i].Value != b[i].Value {
+			t.Errorf("token %d = %q, %q", i, a[i].Value, b[i].Value)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package duplicate
+
+import (
+	"go/scanner"
+	"go/token"
+
+	"github.com/liujinliang/lang-checker/internal/java"
+)
+
+// 归一化后的标识符和字面量，使仅变量名或常量不同的代码也能被识别为克隆
+const (
+	identValue   = "$id"
+	literalValue = "$lit"
+)
+
+// GoTokens 将Go源码转换为归一化词法单元，忽略注释以及package/import声明
+func GoTokens(src string) []Token {
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(src))
+	var s scanner.Scanner
+	s.Init(file, []byte(src), nil, 0)
+
+	var tokens []Token
+	skipping := false
+	depth := 0
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			return tokens
+		}
+		if tok == token.SEMICOLON && lit == "\n" {
+			if skipping && depth == 0 {
+				skipping = false
+			}
+			continue
+		}
+
+		// 跳过 package 子句和 import 声明
+		if tok == token.PACKAGE || tok == token.IMPORT {
+			skipping = true
+			continue
+		}
+		if skipping {
+			switch tok {
+			case token.LPAREN:
+				depth++
+			case token.RPAREN:
+				depth--
+			case token.SEMICOLON:
+				if depth == 0 {
+					skipping = false
+				}
+			}
+			continue
+		}
+
+		value := tok.String()
+		switch {
+		case tok == token.IDENT:
+			value = identValue
+		case tok.IsLiteral():
+			value = literalValue
+		}
+		tokens = append(tokens, Token{Value: value, Line: file.Line(pos)})
+	}
+}
+
+// JavaTokens 将Java源码转换为归一化词法单元，忽略注释以及package/import语句
+func JavaTokens(src string) []Token {
+	var tokens []Token
+	skipping := false
+	for _, tok := range java.Tokenize(src) {
+		if tok.Kind.IsComment() {
+			continue
+		}
+		if len(tokens) == 0 && (tok.Is("package") || tok.Is("import")) {
+			skipping = true
+		}
+		if skipping {
+			if tok.Is(";") {
+				skipping = false
+			}
+			continue
+		}
+
+		value := tok.Text
+		switch {
+		case tok.Kind == java.Ident:
+			value = identValue
+		case tok.Kind.IsLiteral():
+			value = literalValue
+		}
+		tokens = append(tokens, Token{Value: value, Line: tok.Pos.Line})
+	}
+	return tokens
+}
@@ -0,0 +1,130 @@
+// Package generated 根据文件名、文件头约定和自定义模式识别机器生成的源码
+package generated
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/liujinliang/lang-checker/internal/java"
+)
+
+// Pattern 生成代码的识别模式
+type Pattern struct {
+	// Reason 命中时报告的原因
+	Reason string
+	Regexp *regexp.Regexp
+	// HeaderOnly 仅匹配文件头：Go文件为package声明之前的部分，
+	// Java文件为首个顶层类型的类体之前的部分（含类型上的注解）
+	HeaderOnly bool
+}
+
+// fileSuffixes 按文件名识别的生成代码
+var fileSuffixes = []struct {
+	suffix string
+	reason string
+}{
+	{".pb.go", "protobuf生成代码"},
+	{".pb.gw.go", "grpc-gateway生成代码"},
+	{".pb.validate.go", "protoc-gen-validate生成代码"},
+}
+
+// BuiltinPatterns 内置的生成代码识别模式
+var BuiltinPatterns = []Pattern{
+	{Reason: "Go生成代码标记", Regexp: regexp.MustCompile(`(?m)^// Code generated .* DO NOT EDIT\.$`), HeaderOnly: true},
+	{Reason: "mockgen生成代码", Regexp: regexp.MustCompile(`(?m)^// Automatically generated by MockGen\b`), HeaderOnly: true},
+	{Reason: "protobuf生成代码", Regexp: regexp.MustCompile(`(?m)^// Generated by the protocol buffer compiler\.`)},
+	{Reason: "gRPC生成代码", Regexp: regexp.MustCompile(`@(io\.grpc\.stub\.annotations\.)?GrpcGenerated\b`)},
+	{Reason: "MyBatis Generator生成代码", Regexp: regexp.MustCompile(`(?i)@mbg\.?generated\b|generated by MyBatis Generator`)},
+	// Lombok、MapStruct等也会把这两个注解加在手写类的单个成员上，只在类型声明上出现时视为整个文件生成，
+	// 成员上的注解见IsGeneratedAnnotation
+	{Reason: "Lombok生成代码", Regexp: regexp.MustCompile(`@lombok\.Generated\b`), HeaderOnly: true},
+	{Reason: "@Generated注解", Regexp: regexp.MustCompile(`@((javax|jakarta)\.annotation\.(processing\.)?)?Generated\s*\(`), HeaderOnly: true},
+}
+
+// generatedAnnotations 标记生成代码的Java注解名
+var generatedAnnotations = map[string]bool{
+	"Generated":                               true,
+	"lombok.Generated":                        true,
+	"javax.annotation.Generated":              true,
+	"javax.annotation.processing.Generated":   true,
+	"jakarta.annotation.Generated":            true,
+	"jakarta.annotation.processing.Generated": true,
+}
+
+// IsGeneratedAnnotation 判断Java注解名（源码中的写法，可带包名）是否标记生成代码
+func IsGeneratedAnnotation(name string) bool {
+	return generatedAnnotations[name]
+}
+
+// Detector 生成代码识别器
+type Detector struct {
+	patterns []Pattern
+}
+
+// NewDetector 创建识别器，custom为额外的自定义内容模式
+func NewDetector(custom []*regexp.Regexp) *Detector {
+	patterns := append([]Pattern(nil), BuiltinPatterns...)
+	for _, re := range custom {
+		patterns = append(patterns, Pattern{Reason: "匹配自定义模式 " + re.String(), Regexp: re})
+	}
+	return &Detector{patterns: patterns}
+}
+
+// Detect 判断文件是否为生成代码，是则返回原因
+func (d *Detector) Detect(path string, content []byte) (string, bool) {
+	for _, f := range fileSuffixes {
+		if strings.HasSuffix(path, f.suffix) {
+			return f.reason, true
+		}
+	}
+
+	header := fileHeader(path, content)
+	for _, p := range d.patterns {
+		text := content
+		if p.HeaderOnly && header != nil {
+			text = header
+		}
+		if p.Regexp.Match(text) {
+			return p.Reason, true
+		}
+	}
+	return "", false
+}
+
+// fileHeader 返回HeaderOnly模式匹配的文件头，不区分文件头的语言返回nil
+func fileHeader(path string, content []byte) []byte {
+	if strings.HasSuffix(path, ".java") {
+		return javaHeader(content)
+	}
+	return goHeader(path, content)
+}
+
+// javaHeader 返回Java文件首个顶层类型的类体'{'之前的部分，注解参数中的'{'不计
+func javaHeader(content []byte) []byte {
+	lx := java.NewLexer(string(content))
+	depth := 0
+	for tok := lx.Next(); tok.Kind != java.EOF; tok = lx.Next() {
+		switch {
+		case tok.Is("("):
+			depth++
+		case tok.Is(")"):
+			depth--
+		case tok.Is("{") && depth == 0:
+			return content[:tok.Pos.Offset]
+		}
+	}
+	return content
+}
+
+var packageClause = regexp.MustCompile(`(?m)^package\s`)
+
+// goHeader 返回Go文件package声明之前的部分，非Go文件返回nil
+func goHeader(path string, content []byte) []byte {
+	if !strings.HasSuffix(path, ".go") {
+		return nil
+	}
+	if loc := packageClause.FindIndex(content); loc != nil {
+		return content[:loc[0]]
+	}
+	return content
+}
@@ -0,0 +1,36 @@
+package generated
+
+import "testing"
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		content string
+		want    bool
+	}{
+		{"protobuf文件名", "api/user.pb.go", "package api\n", true},
+		{"Go生成标记", "a.go", "// Code generated by stringer. DO NOT EDIT.\n\npackage a\n", true},
+		{"Go生成标记在package之后", "a.go", "package a\n\n// Code generated by stringer. DO NOT EDIT.\n", false},
+		{"手写Go文件", "a.go", "package a\n\nfunc f() {}\n", false},
+		{"类型上的@Generated", "Mapper.java",
+			"package a;\n\nimport javax.annotation.processing.Generated;\n\n@Generated(value = {\"org.mapstruct.ap.MappingProcessor\"})\npublic class MapperImpl {\n}\n", true},
+		{"类型上的@lombok.Generated", "User.java", "package a;\n\n@lombok.Generated\nclass User {\n}\n", true},
+		{"成员上的@lombok.Generated", "User.java",
+			"package a;\n\npublic class User {\n    @lombok.Generated\n    public String getName() { return name; }\n}\n", false},
+		{"成员上的@Generated", "User.java",
+			"package a;\n\npublic class User {\n    @javax.annotation.Generated(\"x\")\n    void f() {}\n}\n", false},
+		{"注释中的类体括号", "User.java", "/* { */\n@Generated(\"x\")\nclass User {\n}\n", true},
+		{"gRPC注解", "UserGrpc.java", "package a;\n\n@io.grpc.stub.annotations.GrpcGenerated\npublic final class UserGrpc {\n}\n", true},
+		{"手写Java文件", "User.java", "package a;\n\npublic class User {\n}\n", false},
+	}
+	d := NewDetector(nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reason, got := d.Detect(tt.path, []byte(tt.content))
+			if got != tt.want {
+				t.Errorf("Detect() = %v (%q), want %v", got, reason, tt.want)
+			}
+		})
+	}
+}
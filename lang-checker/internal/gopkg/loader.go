@@ -0,0 +1,329 @@
+// Package gopkg 加载并类型检查Go模块中的包，仅使用本地源码，不访问网络
+package gopkg
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/liujinliang/lang-checker/internal/source"
+)
+
+// Package 类型检查后的Go包
+type Package struct {
+	// ImportPath 包的导入路径
+	ImportPath string
+	// Name 包名
+	Name string
+	// Dir 包所在目录
+	Dir string
+	// FilePaths 与Files一一对应的文件路径
+	FilePaths []string
+	Files     []*ast.File
+	// Contents 与Files一一对应的源码，已转码为UTF-8
+	Contents []string
+	// Encodings 与Files一一对应的原始编码，见source.Decode
+	Encodings []string
+	// Imports 包直接导入的路径（去重排序）
+	Imports []string
+
+	Types      *types.Package
+	Info       *types.Info
+	TypeErrors []error
+}
+
+// SkippedFile 加载包时因无法读取或解析而跳过的文件，所在包的其余文件照常类型检查
+type SkippedFile struct {
+	Path string
+	Err  error
+}
+
+// Module Go模块信息
+type Module struct {
+	Path string
+	Dir  string
+}
+
+// FindModule 从dir向上查找go.mod
+func FindModule(dir string) (*Module, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	for d := abs; ; d = filepath.Dir(d) {
+		modPath, err := readModulePath(filepath.Join(d, "go.mod"))
+		if err == nil {
+			return &Module{Path: modPath, Dir: d}, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+		if filepath.Dir(d) == d {
+			return nil, fmt.Errorf("未找到go.mod: %s", dir)
+		}
+	}
+}
+
+func readModulePath(goMod string) (string, error) {
+	f, err := os.Open(goMod)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if rest, ok := strings.CutPrefix(line, "module"); ok && rest != "" && (rest[0] == ' ' || rest[0] == '\t') {
+			return strings.Trim(strings.TrimSpace(rest), `"`), nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	return "", fmt.Errorf("%s 中缺少module声明", goMod)
+}
+
+// Load 加载dir下（递归）属于同一模块的所有包并完成类型检查。
+// 模块内的依赖从源码加载，标准库通过源码导入器加载，其他第三方依赖以空包代替并记录类型错误。
+// 文件按source.ReadFile读取和转码，maxSize为单个文件的最大字节数（<=0不限制）；
+// 超限、二进制或有语法错误的文件不中断加载，按发现顺序返回
+func Load(ctx context.Context, fset *token.FileSet, dir string, maxSize int64) (*Module, []*Package, []SkippedFile, error) {
+	mod, err := FindModule(dir)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	root, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	l := &loader{
+		ctx:      ctx,
+		fset:     fset,
+		maxSize:  maxSize,
+		module:   mod,
+		root:     dir,
+		rootAbs:  root,
+		std:      importer.ForCompiler(fset, "source", nil),
+		packages: make(map[string]*Package),
+		loading:  make(map[string]bool),
+	}
+
+	var dirs []string
+	err = filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if p != root && skipDir(p, d.Name()) {
+			return filepath.SkipDir
+		}
+		dirs = append(dirs, p)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	var result []*Package
+	for _, d := range dirs {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, nil, err
+		}
+		pkg, err := l.loadDir(d)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if pkg != nil {
+			result = append(result, pkg)
+		}
+	}
+	return mod, result, l.skipped, nil
+}
+
+// skipDir 跳过vendor、testdata、隐藏目录以及嵌套模块
+func skipDir(p, name string) bool {
+	if name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") {
+		return true
+	}
+	_, err := os.Stat(filepath.Join(p, "go.mod"))
+	return err == nil
+}
+
+type loader struct {
+	ctx      context.Context
+	fset     *token.FileSet
+	maxSize  int64
+	module   *Module
+	root     string
+	rootAbs  string
+	std      types.Importer
+	packages map[string]*Package
+	loading  map[string]bool
+	// skipped 无法读取或解析的文件
+	skipped []SkippedFile
+}
+
+func (l *loader) importPath(dir string) (string, error) {
+	rel, err := filepath.Rel(l.module.Dir, dir)
+	if err != nil {
+		return "", err
+	}
+	if rel == "." {
+		return l.module.Path, nil
+	}
+	return path.Join(l.module.Path, filepath.ToSlash(rel)), nil
+}
+
+// displayPath 将分析根目录下的绝对路径还原为相对调用方传入路径的形式
+func (l *loader) displayPath(abs string) string {
+	rel, err := filepath.Rel(l.rootAbs, abs)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return abs
+	}
+	return filepath.Join(l.root, rel)
+}
+
+// loadDir 解析并类型检查目录中的非测试Go文件，没有Go文件时返回nil
+func (l *loader) loadDir(dir string) (*Package, error) {
+	importPath, err := l.importPath(dir)
+	if err != nil {
+		return nil, err
+	}
+	if pkg, ok := l.packages[importPath]; ok {
+		return pkg, nil
+	}
+	if l.loading[importPath] {
+		return nil, fmt.Errorf("包存在循环导入: %s", importPath)
+	}
+	l.loading[importPath] = true
+	defer delete(l.loading, importPath)
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	pkg := &Package{ImportPath: importPath, Dir: l.displayPath(dir)}
+	imports := make(map[string]bool)
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		if match, err := build.Default.MatchFile(dir, name); err != nil || !match {
+			continue
+		}
+
+		filePath := l.displayPath(filepath.Join(dir, name))
+		content, encoding, err := source.ReadFile(filePath, l.maxSize)
+		if err != nil {
+			l.skipped = append(l.skipped, SkippedFile{Path: filePath, Err: err})
+			continue
+		}
+		file, err := parser.ParseFile(l.fset, filePath, content, parser.ParseComments)
+		if err != nil {
+			l.skipped = append(l.skipped, SkippedFile{Path: filePath, Err: err})
+			continue
+		}
+		// 同一目录下的不同包（如package main的生成脚本）只保留第一个
+		if pkg.Name == "" {
+			pkg.Name = file.Name.Name
+		} else if file.Name.Name != pkg.Name {
+			continue
+		}
+
+		pkg.Files = append(pkg.Files, file)
+		pkg.FilePaths = append(pkg.FilePaths, filePath)
+		pkg.Contents = append(pkg.Contents, content)
+		pkg.Encodings = append(pkg.Encodings, encoding)
+		for _, spec := range file.Imports {
+			imports[strings.Trim(spec.Path.Value, `"`)] = true
+		}
+	}
+	if len(pkg.Files) == 0 {
+		// 记录为空包，被导入或遍历到时不再重复读取
+		l.packages[importPath] = nil
+		return nil, nil
+	}
+	for imp := range imports {
+		pkg.Imports = append(pkg.Imports, imp)
+	}
+	sort.Strings(pkg.Imports)
+
+	pkg.Info = &types.Info{
+		Types:      make(map[ast.Expr]types.TypeAndValue),
+		Defs:       make(map[*ast.Ident]types.Object),
+		Uses:       make(map[*ast.Ident]types.Object),
+		Implicits:  make(map[ast.Node]types.Object),
+		Selections: make(map[*ast.SelectorExpr]*types.Selection),
+		Scopes:     make(map[ast.Node]*types.Scope),
+	}
+	conf := types.Config{
+		Importer: l,
+		Error: func(err error) {
+			pkg.TypeErrors = append(pkg.TypeErrors, err)
+		},
+	}
+	// 类型错误通过conf.Error收集，不中断分析
+	pkg.Types, _ = conf.Check(importPath, l.fset, pkg.Files, pkg.Info)
+
+	l.packages[importPath] = pkg
+	return pkg, nil
+}
+
+// Import 实现types.Importer
+func (l *loader) Import(importPath string) (*types.Package, error) {
+	if err := l.ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if importPath == l.module.Path || strings.HasPrefix(importPath, l.module.Path+"/") {
+		rel := strings.TrimPrefix(strings.TrimPrefix(importPath, l.module.Path), "/")
+		pkg, err := l.loadDir(filepath.Join(l.module.Dir, filepath.FromSlash(rel)))
+		if err != nil {
+			return nil, err
+		}
+		if pkg == nil || pkg.Types == nil {
+			return nil, fmt.Errorf("找不到包: %s", importPath)
+		}
+		return pkg.Types, nil
+	}
+
+	if isStdlib(importPath) {
+		return l.std.Import(importPath)
+	}
+
+	// 第三方依赖不做网络或模块缓存解析，返回空包让类型检查继续
+	pkg := types.NewPackage(importPath, path.Base(importPath))
+	pkg.MarkComplete()
+	return pkg, nil
+}
+
+func isStdlib(importPath string) bool {
+	if importPath == "C" || importPath == "unsafe" {
+		return true
+	}
+	first, _, _ := strings.Cut(importPath, "/")
+	if strings.Contains(first, ".") {
+		return false
+	}
+	info, err := os.Stat(filepath.Join(build.Default.GOROOT, "src", filepath.FromSlash(importPath)))
+	return err == nil && info.IsDir()
+}
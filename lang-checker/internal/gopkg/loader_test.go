@@ -0,0 +1,69 @@
+package gopkg
+
+import (
+	"context"
+	"errors"
+	"go/scanner"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/liujinliang/lang-checker/internal/source"
+)
+
+func writeModule(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestLoadSkipsBrokenFiles(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		"go.mod":      "module example.com/m\n\ngo 1.21\n",
+		"a/a.go":      "package a\n\nimport \"example.com/m/b\"\n\nfunc A() int { return b.B() }\n",
+		"b/b.go":      "package b\n\nfunc B() int { return 1 }\n",
+		"b/broken.go": "package b\n\nfunc Broken( {\n",
+		"b/big.go":    "package b\n\n// " + strings.Repeat("x", 200) + "\n",
+		"c/bin.go":    "package c\n\nvar x = 1\n\x00\x01\x02",
+	})
+
+	_, pkgs, skipped, err := Load(context.Background(), token.NewFileSet(), dir, 100)
+	if err != nil {
+		t.Fatalf("单个文件出错不应中断加载: %v", err)
+	}
+	if len(pkgs) != 2 || pkgs[0].ImportPath != "example.com/m/a" || pkgs[1].ImportPath != "example.com/m/b" {
+		t.Fatalf("应加载a和b两个包: %d", len(pkgs))
+	}
+	if len(pkgs[0].TypeErrors) != 0 {
+		t.Errorf("b的其余文件应正常类型检查: %v", pkgs[0].TypeErrors)
+	}
+
+	got := make(map[string]error)
+	for _, f := range skipped {
+		got[filepath.Base(f.Path)] = f.Err
+	}
+	var list scanner.ErrorList
+	if !errors.As(got["broken.go"], &list) {
+		t.Errorf("broken.go应为语法错误: %v", got["broken.go"])
+	}
+	if !errors.Is(got["big.go"], source.ErrTooLarge) {
+		t.Errorf("big.go应超过大小限制: %v", got["big.go"])
+	}
+	if !errors.Is(got["bin.go"], source.ErrBinary) {
+		t.Errorf("bin.go应为二进制文件: %v", got["bin.go"])
+	}
+	if len(skipped) != 3 {
+		t.Errorf("每个跳过的文件只记录一次: %d", len(skipped))
+	}
+}
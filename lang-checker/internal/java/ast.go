@@ -0,0 +1,628 @@
+package java
+
+import "fmt"
+
+// Node AST节点
+type Node interface {
+	// Pos 节点第一个字符的位置
+	Pos() Pos
+	// End 节点最后一个字符之后的位置
+	End() Pos
+	// Span 节点覆盖的词法单元在File.Tokens中的下标范围[first, last]
+	Span() (first, last int)
+}
+
+// Decl 声明节点
+type Decl interface {
+	Node
+	declNode()
+}
+
+// Stmt 语句节点
+type Stmt interface {
+	Node
+	stmtNode()
+}
+
+// Expr 表达式节点（含case标签中的模式）
+type Expr interface {
+	Node
+	exprNode()
+}
+
+// Range 节点的源码范围，嵌入到所有节点中
+type Range struct {
+	Start, Stop Pos
+	First, Last int
+}
+
+func (r Range) Pos() Pos                { return r.Start }
+func (r Range) End() Pos                { return r.Stop }
+func (r Range) Span() (first, last int) { return r.First, r.Last }
+
+// Error 语法错误
+type Error struct {
+	Pos Pos
+	Msg string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%d:%d: %s", e.Pos.Line, e.Pos.Column, e.Msg)
+}
+
+// File 编译单元
+type File struct {
+	Range
+	Package string
+	Imports []*Import
+	Types   []*TypeDecl
+	// Tokens 不含注释的词法单元，节点的Span即为其中的下标
+	Tokens []Token
+	// Errors 语法错误，出错的成员或语句已跳过，其余部分仍可使用
+	Errors []*Error
+}
+
+// Import 导入声明
+type Import struct {
+	Range
+	Path     string
+	Static   bool
+	Wildcard bool
+}
+
+// Modifiers 修饰符和注解
+type Modifiers struct {
+	Keywords    []string
+	Annotations []*Annotation
+}
+
+// Has 判断是否含指定修饰符
+func (m Modifiers) Has(keyword string) bool {
+	for _, k := range m.Keywords {
+		if k == keyword {
+			return true
+		}
+	}
+	return false
+}
+
+// Annotation 注解，Args中的键值对为AssignExpr
+type Annotation struct {
+	Range
+	Name string
+	Args []Expr
+}
+
+// TypeKind 类型声明的种类
+type TypeKind int
+
+const (
+	ClassKind TypeKind = iota
+	InterfaceKind
+	EnumKind
+	RecordKind
+	AnnotationKind
+)
+
+func (k TypeKind) String() string {
+	switch k {
+	case InterfaceKind:
+		return "interface"
+	case EnumKind:
+		return "enum"
+	case RecordKind:
+		return "record"
+	case AnnotationKind:
+		return "@interface"
+	}
+	return "class"
+}
+
+// TypeDecl 类型声明，匿名类的Name为nil
+type TypeDecl struct {
+	Range
+	Doc *Token
+	Modifiers
+	Kind       TypeKind
+	Name       *Identifier
+	TypeParams []*TypeParam
+	Extends    []*Type
+	Implements []*Type
+	Permits    []*Type
+	// Components record的组成部分
+	Components []*Param
+	// Constants 枚举常量
+	Constants []*EnumConstant
+	Members   []Decl
+	// Outer 外层类型，顶层类型为nil
+	Outer *TypeDecl
+}
+
+// TypeName 返回类型名，匿名类返回空字符串
+func (t *TypeDecl) TypeName() string {
+	if t.Name == nil {
+		return ""
+	}
+	return t.Name.Name
+}
+
+// NestedName 返回带外层类型的类型名，如 Outer.Inner，匿名的外层类型不计入
+func (t *TypeDecl) NestedName() string {
+	name := t.TypeName()
+	for outer := t.Outer; outer != nil; outer = outer.Outer {
+		if outer.Name != nil {
+			name = outer.Name.Name + "." + name
+		}
+	}
+	return name
+}
+
+// TypeParam 类型参数
+type TypeParam struct {
+	Range
+	Name   string
+	Bounds []*Type
+}
+
+// Type 类型引用。Name为限定名（如java.util.Map.Entry），基本类型为关键字，通配符为"?"；
+// Args为最后一段的类型实参，Bound为通配符的上界或下界（Super为true时为下界）
+type Type struct {
+	Range
+	Annotations []*Annotation
+	Name        string
+	Args        []*Type
+	Dims        int
+	Bound       *Type
+	Super       bool
+}
+
+// MethodDecl 方法、构造器或注解元素声明，抽象方法的Body为nil
+type MethodDecl struct {
+	Range
+	Doc *Token
+	Modifiers
+	TypeParams []*TypeParam
+	// Result 返回类型，构造器为nil
+	Result *Type
+	Name   *Identifier
+	Params []*Param
+	// ParamsEnd 参数列表')'之后的位置，紧凑构造器为方法名之后的位置
+	ParamsEnd Pos
+	Throws    []*Type
+	Body      *Block
+	// Default 注解元素的默认值
+	Default     Expr
+	Constructor bool
+	// Compact record的紧凑构造器
+	Compact bool
+	// Owner 所属类型
+	Owner *TypeDecl
+}
+
+// Param 方法、lambda、catch或record的参数，未声明类型的lambda参数Type为nil
+type Param struct {
+	Range
+	Modifiers
+	Type    *Type
+	Name    *Identifier
+	Varargs bool
+}
+
+// FieldDecl 字段声明
+type FieldDecl struct {
+	Range
+	Doc *Token
+	Modifiers
+	Type *Type
+	Vars []*VarDecl
+}
+
+// VarDecl 变量声明符 name[] = init
+type VarDecl struct {
+	Range
+	Name *Identifier
+	Dims int
+	Init Expr
+}
+
+// Initializer 实例或静态初始化块
+type Initializer struct {
+	Range
+	Static bool
+	Body   *Block
+}
+
+// EnumConstant 枚举常量，带类体时Body为匿名类
+type EnumConstant struct {
+	Range
+	Doc         *Token
+	Annotations []*Annotation
+	Name        *Identifier
+	Args        []Expr
+	Body        *TypeDecl
+}
+
+// BadDecl 无法解析的成员
+type BadDecl struct{ Range }
+
+func (*TypeDecl) declNode()     {}
+func (*MethodDecl) declNode()   {}
+func (*FieldDecl) declNode()    {}
+func (*Initializer) declNode()  {}
+func (*EnumConstant) declNode() {}
+func (*BadDecl) declNode()      {}
+
+// 语句
+
+type (
+	// Block 语句块
+	Block struct {
+		Range
+		Stmts []Stmt
+	}
+
+	// LocalVarStmt 局部变量声明
+	LocalVarStmt struct {
+		Range
+		Modifiers
+		Type *Type
+		Vars []*VarDecl
+	}
+
+	// LocalTypeStmt 局部类型声明
+	LocalTypeStmt struct {
+		Range
+		Decl *TypeDecl
+	}
+
+	// ExprStmt 表达式语句
+	ExprStmt struct {
+		Range
+		X Expr
+	}
+
+	// IfStmt if语句
+	IfStmt struct {
+		Range
+		Cond Expr
+		Then Stmt
+		Else Stmt
+	}
+
+	// ForStmt 基本for语句
+	ForStmt struct {
+		Range
+		Init   []Stmt
+		Cond   Expr
+		Update []Expr
+		Body   Stmt
+	}
+
+	// ForEachStmt 增强for语句
+	ForEachStmt struct {
+		Range
+		Var  *Param
+		X    Expr
+		Body Stmt
+	}
+
+	// WhileStmt while语句
+	WhileStmt struct {
+		Range
+		Cond Expr
+		Body Stmt
+	}
+
+	// DoStmt do-while语句
+	DoStmt struct {
+		Range
+		Body Stmt
+		Cond Expr
+	}
+
+	// SwitchStmt switch语句
+	SwitchStmt struct {
+		Range
+		Selector Expr
+		Cases    []*SwitchCase
+	}
+
+	// SwitchCase case或default分支，箭头形式的分支体只有一条语句
+	SwitchCase struct {
+		Range
+		Labels  []Expr
+		Default bool
+		Guard   Expr
+		Arrow   bool
+		Body    []Stmt
+	}
+
+	// ReturnStmt return语句
+	ReturnStmt struct {
+		Range
+		X Expr
+	}
+
+	// BranchStmt break或continue语句
+	BranchStmt struct {
+		Range
+		Keyword string
+		Label   *Identifier
+	}
+
+	// ThrowStmt throw语句
+	ThrowStmt struct {
+		Range
+		X Expr
+	}
+
+	// YieldStmt switch表达式中的yield语句
+	YieldStmt struct {
+		Range
+		X Expr
+	}
+
+	// TryStmt try语句，Resources中为LocalVarStmt或表达式语句
+	TryStmt struct {
+		Range
+		Resources []Stmt
+		Body      *Block
+		Catches   []*CatchClause
+		Finally   *Block
+	}
+
+	// CatchClause catch子句，多重捕获时Types有多个
+	CatchClause struct {
+		Range
+		Modifiers
+		Types []*Type
+		Name  *Identifier
+		Body  *Block
+	}
+
+	// SyncStmt synchronized语句
+	SyncStmt struct {
+		Range
+		Lock Expr
+		Body *Block
+	}
+
+	// LabeledStmt 带标签的语句
+	LabeledStmt struct {
+		Range
+		Label *Identifier
+		Stmt  Stmt
+	}
+
+	// AssertStmt assert语句
+	AssertStmt struct {
+		Range
+		Cond Expr
+		Msg  Expr
+	}
+
+	// EmptyStmt 空语句
+	EmptyStmt struct{ Range }
+
+	// BadStmt 无法解析的语句
+	BadStmt struct{ Range }
+)
+
+func (*Block) stmtNode()         {}
+func (*LocalVarStmt) stmtNode()  {}
+func (*LocalTypeStmt) stmtNode() {}
+func (*ExprStmt) stmtNode()      {}
+func (*IfStmt) stmtNode()        {}
+func (*ForStmt) stmtNode()       {}
+func (*ForEachStmt) stmtNode()   {}
+func (*WhileStmt) stmtNode()     {}
+func (*DoStmt) stmtNode()        {}
+func (*SwitchStmt) stmtNode()    {}
+func (*ReturnStmt) stmtNode()    {}
+func (*BranchStmt) stmtNode()    {}
+func (*ThrowStmt) stmtNode()     {}
+func (*YieldStmt) stmtNode()     {}
+func (*TryStmt) stmtNode()       {}
+func (*SyncStmt) stmtNode()      {}
+func (*LabeledStmt) stmtNode()   {}
+func (*AssertStmt) stmtNode()    {}
+func (*EmptyStmt) stmtNode()     {}
+func (*BadStmt) stmtNode()       {}
+
+// 表达式
+
+type (
+	// Identifier 标识符
+	Identifier struct {
+		Range
+		Name string
+	}
+
+	// Literal 字面量，true/false/null的Kind为Keyword
+	Literal struct {
+		Range
+		Kind  TokenKind
+		Value string
+	}
+
+	// ThisExpr this或Outer.this
+	ThisExpr struct {
+		Range
+		Qualifier Expr
+	}
+
+	// SuperExpr super或Outer.super，只作为字段访问、方法调用或方法引用的接收者出现
+	SuperExpr struct {
+		Range
+		Qualifier Expr
+	}
+
+	// SelectorExpr 字段访问或限定名 X.Name
+	SelectorExpr struct {
+		Range
+		X    Expr
+		Name *Identifier
+	}
+
+	// CallExpr 方法调用，X为nil表示无接收者；构造器中的this(...)和super(...)的Name为this/super
+	CallExpr struct {
+		Range
+		X        Expr
+		TypeArgs []*Type
+		Name     *Identifier
+		Args     []Expr
+	}
+
+	// NewExpr 对象创建，带类体时Body为匿名类
+	NewExpr struct {
+		Range
+		Outer    Expr
+		TypeArgs []*Type
+		Type     *Type
+		Args     []Expr
+		Body     *TypeDecl
+	}
+
+	// NewArrayExpr 数组创建，Type为元素类型，Dims为给出长度的维度
+	NewArrayExpr struct {
+		Range
+		Type      *Type
+		Dims      []Expr
+		ExtraDims int
+		Init      *ArrayInit
+	}
+
+	// ArrayInit 数组初始化器
+	ArrayInit struct {
+		Range
+		Elems []Expr
+	}
+
+	// IndexExpr 数组下标访问
+	IndexExpr struct {
+		Range
+		X     Expr
+		Index Expr
+	}
+
+	// UnaryExpr 一元运算
+	UnaryExpr struct {
+		Range
+		Op      string
+		X       Expr
+		Postfix bool
+	}
+
+	// BinaryExpr 二元运算
+	BinaryExpr struct {
+		Range
+		Op   string
+		X, Y Expr
+	}
+
+	// AssignExpr 赋值（含复合赋值）
+	AssignExpr struct {
+		Range
+		Op   string
+		X, Y Expr
+	}
+
+	// CondExpr 三元条件运算
+	CondExpr struct {
+		Range
+		Cond, Then, Else Expr
+	}
+
+	// CastExpr 类型转换，交集类型时Types有多个
+	CastExpr struct {
+		Range
+		Types []*Type
+		X     Expr
+	}
+
+	// InstanceOfExpr instanceof运算，使用模式匹配时Pattern不为nil
+	InstanceOfExpr struct {
+		Range
+		X       Expr
+		Type    *Type
+		Pattern Expr
+	}
+
+	// LambdaExpr lambda表达式，Body为Expr或*Block
+	LambdaExpr struct {
+		Range
+		Params []*Param
+		Body   Node
+	}
+
+	// MethodRef 方法引用，X为Expr或*Type，构造器引用的Name为new
+	MethodRef struct {
+		Range
+		X        Node
+		TypeArgs []*Type
+		Name     *Identifier
+	}
+
+	// SwitchExpr switch表达式
+	SwitchExpr struct {
+		Range
+		Selector Expr
+		Cases    []*SwitchCase
+	}
+
+	// ParenExpr 括号表达式
+	ParenExpr struct {
+		Range
+		X Expr
+	}
+
+	// ClassLit 类字面量 Type.class
+	ClassLit struct {
+		Range
+		Type *Type
+	}
+
+	// TypePattern 类型模式 Type name
+	TypePattern struct {
+		Range
+		Modifiers
+		Type *Type
+		Name *Identifier
+	}
+
+	// RecordPattern record解构模式 Type(p1, p2)
+	RecordPattern struct {
+		Range
+		Type     *Type
+		Patterns []Expr
+	}
+
+	// BadExpr 无法解析的表达式
+	BadExpr struct{ Range }
+)
+
+func (*Identifier) exprNode()     {}
+func (*Literal) exprNode()        {}
+func (*ThisExpr) exprNode()       {}
+func (*SuperExpr) exprNode()      {}
+func (*SelectorExpr) exprNode()   {}
+func (*CallExpr) exprNode()       {}
+func (*NewExpr) exprNode()        {}
+func (*NewArrayExpr) exprNode()   {}
+func (*ArrayInit) exprNode()      {}
+func (*IndexExpr) exprNode()      {}
+func (*UnaryExpr) exprNode()      {}
+func (*BinaryExpr) exprNode()     {}
+func (*AssignExpr) exprNode()     {}
+func (*CondExpr) exprNode()       {}
+func (*CastExpr) exprNode()       {}
+func (*InstanceOfExpr) exprNode() {}
+func (*LambdaExpr) exprNode()     {}
+func (*MethodRef) exprNode()      {}
+func (*SwitchExpr) exprNode()     {}
+func (*ParenExpr) exprNode()      {}
+func (*ClassLit) exprNode()       {}
+func (*TypePattern) exprNode()    {}
+func (*RecordPattern) exprNode()  {}
+func (*Annotation) exprNode()     {}
+func (*BadExpr) exprNode()        {}
@@ -0,0 +1,361 @@
+// Package java 提供纯Go实现的Java词法分析
+package java
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// TokenKind 词法单元类型
+type TokenKind int
+
+const (
+	EOF TokenKind = iota
+	Ident
+	Keyword
+	IntLiteral
+	FloatLiteral
+	CharLiteral
+	StringLiteral
+	TextBlock
+	Operator
+	LineComment
+	BlockComment
+	DocComment
+	Illegal
+)
+
+var kindNames = [...]string{
+	EOF:           "EOF",
+	Ident:         "Ident",
+	Keyword:       "Keyword",
+	IntLiteral:    "IntLiteral",
+	FloatLiteral:  "FloatLiteral",
+	CharLiteral:   "CharLiteral",
+	StringLiteral: "StringLiteral",
+	TextBlock:     "TextBlock",
+	Operator:      "Operator",
+	LineComment:   "LineComment",
+	BlockComment:  "BlockComment",
+	DocComment:    "DocComment",
+	Illegal:       "Illegal",
+}
+
+func (k TokenKind) String() string {
+	if int(k) < len(kindNames) {
+		return kindNames[k]
+	}
+	return "Unknown"
+}
+
+// IsComment 判断是否为注释
+func (k TokenKind) IsComment() bool {
+	return k == LineComment || k == BlockComment || k == DocComment
+}
+
+// IsLiteral 判断是否为字面量（不含true/false/null关键字）
+func (k TokenKind) IsLiteral() bool {
+	return k >= IntLiteral && k <= TextBlock
+}
+
+// Pos 源码位置，Line和Column从1开始，Column按字节计
+type Pos struct {
+	Offset int
+	Line   int
+	Column int
+}
+
+// Token 词法单元
+type Token struct {
+	Kind TokenKind
+	Text string
+	Pos  Pos
+	// End 词法单元最后一个字符之后的位置
+	End Pos
+}
+
+// Is 判断是否为指定文本的运算符或关键字
+func (t Token) Is(text string) bool {
+	return (t.Kind == Operator || t.Kind == Keyword) && t.Text == text
+}
+
+var keywords = map[string]bool{
+	"abstract": true, "assert": true, "boolean": true, "break": true, "byte": true,
+	"case": true, "catch": true, "char": true, "class": true, "const": true,
+	"continue": true, "default": true, "do": true, "double": true, "else": true,
+	"enum": true, "extends": true, "final": true, "finally": true, "float": true,
+	"for": true, "goto": true, "if": true, "implements": true, "import": true,
+	"instanceof": true, "int": true, "interface": true, "long": true, "native": true,
+	"new": true, "package": true, "private": true, "protected": true, "public": true,
+	"return": true, "short": true, "static": true, "strictfp": true, "super": true,
+	"switch": true, "synchronized": true, "this": true, "throw": true, "throws": true,
+	"transient": true, "try": true, "void": true, "volatile": true, "while": true,
+	"true": true, "false": true, "null": true,
+}
+
+// IsKeyword 判断是否为Java保留字（含true/false/null字面量）
+func IsKeyword(s string) bool {
+	return keywords[s]
+}
+
+// 按长度从长到短排列，保证最长匹配。'>'始终单独输出，由语法分析器合并为移位运算符，以便处理泛型中的'>>'
+var operators = []string{
+	">>>=", "<<=", ">>=", "...", "->", "::", "++", "--", "&&", "||",
+	"==", "!=", "<=", ">=", "+=", "-=", "*=", "/=", "&=", "|=", "^=", "%=", "<<",
+	"(", ")", "{", "}", "[", "]", ";", ",", ".", "@", "=", ">", "<", "!", "~",
+	"?", ":", "+", "-", "*", "/", "&", "|", "^", "%",
+}
+
+// Lexer Java词法分析器
+type Lexer struct {
+	src  string
+	off  int
+	line int
+	col  int
+}
+
+// NewLexer 创建词法分析器
+func NewLexer(src string) *Lexer {
+	return &Lexer{src: src, line: 1, col: 1}
+}
+
+// Tokenize 将源码切分为词法单元（包含注释），结尾不含EOF
+func Tokenize(src string) []Token {
+	lx := NewLexer(src)
+	var tokens []Token
+	for {
+		tok := lx.Next()
+		if tok.Kind == EOF {
+			return tokens
+		}
+		tokens = append(tokens, tok)
+	}
+}
+
+func (lx *Lexer) pos() Pos {
+	return Pos{Offset: lx.off, Line: lx.line, Column: lx.col}
+}
+
+func (lx *Lexer) peek(n int) byte {
+	if lx.off+n < len(lx.src) {
+		return lx.src[lx.off+n]
+	}
+	return 0
+}
+
+func (lx *Lexer) advance(n int) {
+	for i := 0; i < n && lx.off < len(lx.src); i++ {
+		if lx.src[lx.off] == '\n' {
+			lx.line++
+			lx.col = 1
+		} else {
+			lx.col++
+		}
+		lx.off++
+	}
+}
+
+// Next 返回下一个词法单元
+func (lx *Lexer) Next() Token {
+	lx.skipSpace()
+	start := lx.pos()
+	if lx.off >= len(lx.src) {
+		return Token{Kind: EOF, Pos: start, End: start}
+	}
+
+	kind := lx.scan()
+	return Token{Kind: kind, Text: lx.src[start.Offset:lx.off], Pos: start, End: lx.pos()}
+}
+
+func (lx *Lexer) skipSpace() {
+	for lx.off < len(lx.src) {
+		switch lx.src[lx.off] {
+		case ' ', '\t', '\n', '\r', '\f':
+			lx.advance(1)
+		default:
+			// 处理全角空格等Unicode空白
+			r, size := utf8.DecodeRuneInString(lx.src[lx.off:])
+			if r != utf8.RuneError && unicode.IsSpace(r) {
+				lx.off += size
+				lx.col += size
+				continue
+			}
+			return
+		}
+	}
+}
+
+func (lx *Lexer) scan() TokenKind {
+	c := lx.src[lx.off]
+	switch {
+	case c == '/' && lx.peek(1) == '/':
+		for lx.off < len(lx.src) && lx.src[lx.off] != '\n' {
+			lx.advance(1)
+		}
+		return LineComment
+	case c == '/' && lx.peek(1) == '*':
+		kind := BlockComment
+		if lx.peek(2) == '*' && lx.peek(3) != '/' {
+			kind = DocComment
+		}
+		end := strings.Index(lx.src[lx.off+2:], "*/")
+		if end < 0 {
+			lx.advance(len(lx.src) - lx.off)
+			return Illegal
+		}
+		lx.advance(end + 4)
+		return kind
+	case c == '"' && strings.HasPrefix(lx.src[lx.off:], `"""`):
+		lx.advance(3)
+		for lx.off < len(lx.src) {
+			if lx.src[lx.off] == '\\' {
+				lx.advance(2)
+				continue
+			}
+			if strings.HasPrefix(lx.src[lx.off:], `"""`) {
+				lx.advance(3)
+				return TextBlock
+			}
+			lx.advance(1)
+		}
+		return Illegal
+	case c == '"' || c == '\'':
+		return lx.scanQuoted(c)
+	case isDigit(c) || (c == '.' && isDigit(lx.peek(1))):
+		return lx.scanNumber()
+	case isIdentStart(c):
+		start := lx.off
+		lx.scanIdent()
+		if keywords[lx.src[start:lx.off]] {
+			return Keyword
+		}
+		return Ident
+	case c >= utf8.RuneSelf:
+		r, _ := utf8.DecodeRuneInString(lx.src[lx.off:])
+		if unicode.IsLetter(r) {
+			lx.scanIdent()
+			return Ident
+		}
+	}
+
+	for _, op := range operators {
+		if strings.HasPrefix(lx.src[lx.off:], op) {
+			lx.advance(len(op))
+			return Operator
+		}
+	}
+	_, size := utf8.DecodeRuneInString(lx.src[lx.off:])
+	lx.advance(size)
+	return Illegal
+}
+
+func (lx *Lexer) scanQuoted(quote byte) TokenKind {
+	kind := StringLiteral
+	if quote == '\'' {
+		kind = CharLiteral
+	}
+	lx.advance(1)
+	for lx.off < len(lx.src) {
+		switch lx.src[lx.off] {
+		case '\\':
+			lx.advance(2)
+		case quote:
+			lx.advance(1)
+			return kind
+		case '\n':
+			return Illegal
+		default:
+			lx.advance(1)
+		}
+	}
+	return Illegal
+}
+
+func (lx *Lexer) scanNumber() TokenKind {
+	kind := IntLiteral
+	if lx.src[lx.off] == '0' && (lx.peek(1) == 'x' || lx.peek(1) == 'X') {
+		lx.advance(2)
+		for isHexDigit(lx.peek(0)) || lx.peek(0) == '_' || lx.peek(0) == '.' {
+			if lx.peek(0) == '.' {
+				kind = FloatLiteral
+			}
+			lx.advance(1)
+		}
+		if c := lx.peek(0); c == 'p' || c == 'P' {
+			kind = FloatLiteral
+			lx.advance(1)
+			lx.scanExponentDigits()
+		}
+	} else if lx.src[lx.off] == '0' && (lx.peek(1) == 'b' || lx.peek(1) == 'B') {
+		lx.advance(2)
+		for c := lx.peek(0); c == '0' || c == '1' || c == '_'; c = lx.peek(0) {
+			lx.advance(1)
+		}
+	} else {
+		for isDigit(lx.peek(0)) || lx.peek(0) == '_' {
+			lx.advance(1)
+		}
+		if lx.peek(0) == '.' && isDigit(lx.peek(1)) || lx.peek(0) == '.' && !isIdentStart(lx.peek(1)) && lx.peek(1) != '.' {
+			kind = FloatLiteral
+			lx.advance(1)
+			for isDigit(lx.peek(0)) || lx.peek(0) == '_' {
+				lx.advance(1)
+			}
+		}
+		if c := lx.peek(0); c == 'e' || c == 'E' {
+			kind = FloatLiteral
+			lx.advance(1)
+			lx.scanExponentDigits()
+		}
+	}
+
+	switch lx.peek(0) {
+	case 'l', 'L':
+		lx.advance(1)
+	case 'f', 'F', 'd', 'D':
+		kind = FloatLiteral
+		lx.advance(1)
+	}
+	return kind
+}
+
+func (lx *Lexer) scanExponentDigits() {
+	if c := lx.peek(0); c == '+' || c == '-' {
+		lx.advance(1)
+	}
+	for isDigit(lx.peek(0)) || lx.peek(0) == '_' {
+		lx.advance(1)
+	}
+}
+
+func (lx *Lexer) scanIdent() {
+	for lx.off < len(lx.src) {
+		c := lx.src[lx.off]
+		if c < utf8.RuneSelf {
+			if !isIdentStart(c) && !isDigit(c) {
+				return
+			}
+			lx.advance(1)
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(lx.src[lx.off:])
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return
+		}
+		lx.off += size
+		lx.col += size
+	}
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isHexDigit(c byte) bool {
+	return isDigit(c) || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+func isIdentStart(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c == '_' || c == '$'
+}
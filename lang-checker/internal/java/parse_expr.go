@@ -0,0 +1,486 @@
+package java
+
+var assignOps = map[string]bool{
+	"=": true, "+=": true, "-=": true, "*=": true, "/=": true, "%=": true,
+	"&=": true, "|=": true, "^=": true, "<<=": true, ">>=": true, ">>>=": true,
+}
+
+// binaryPrec 二元运算符优先级，数值越大结合越紧
+var binaryPrec = map[string]int{
+	"||": 1,
+	"&&": 2,
+	"|":  3,
+	"^":  4,
+	"&":  5,
+	"==": 6, "!=": 6,
+	"<": 7, ">": 7, "<=": 7, ">=": 7, "instanceof": 7,
+	"<<": 8, ">>": 8, ">>>": 8,
+	"+": 9, "-": 9,
+	"*": 10, "/": 10, "%": 10,
+}
+
+// parseExpr 解析表达式（含赋值和lambda）
+func (p *parser) parseExpr() Expr {
+	p.enter()
+	defer p.leave()
+	if p.lambdaAhead() {
+		return p.parseLambda()
+	}
+	x := p.parseCond()
+	if tok := p.tok(); tok.Kind == Operator && assignOps[tok.Text] {
+		p.next()
+		y := p.parseExpr()
+		return &AssignExpr{Range: between(x, y), Op: tok.Text, X: x, Y: y}
+	}
+	return x
+}
+
+// parseCond 解析条件表达式，顶层不识别lambda（用于case标签和注解参数）
+func (p *parser) parseCond() Expr {
+	x := p.parseBinary(1)
+	if !p.got("?") {
+		return x
+	}
+	then := p.parseExpr()
+	p.expect(":")
+	var els Expr
+	if p.lambdaAhead() {
+		els = p.parseLambda()
+	} else {
+		els = p.parseCond()
+	}
+	return &CondExpr{Range: between(x, els), Cond: x, Then: then, Else: els}
+}
+
+// binaryOp 返回当前位置的二元运算符及其占用的词法单元数。
+// 词法分析器把'>'逐个输出，相邻的'>'在这里合并为移位运算符
+func (p *parser) binaryOp() (string, int) {
+	tok := p.tok()
+	if tok.Is("instanceof") {
+		return "instanceof", 1
+	}
+	if tok.Kind != Operator {
+		return "", 0
+	}
+	if tok.Text == ">" {
+		n := 1
+		for n < 3 && p.peek(n).Is(">") && p.peek(n).Pos.Offset == p.peek(n-1).End.Offset {
+			n++
+		}
+		switch n {
+		case 2:
+			return ">>", 2
+		case 3:
+			return ">>>", 3
+		}
+	}
+	if _, ok := binaryPrec[tok.Text]; ok {
+		return tok.Text, 1
+	}
+	return "", 0
+}
+
+// parseBinary 按优先级爬升解析二元运算
+func (p *parser) parseBinary(minPrec int) Expr {
+	x := p.parseUnary()
+	for {
+		op, n := p.binaryOp()
+		prec, ok := binaryPrec[op]
+		if !ok || prec < minPrec {
+			return x
+		}
+		p.pos += n
+		if op == "instanceof" {
+			x = p.parseInstanceOf(x)
+			continue
+		}
+		y := p.parseBinary(prec + 1)
+		x = &BinaryExpr{Range: between(x, y), Op: op, X: x, Y: y}
+	}
+}
+
+func (p *parser) parseInstanceOf(x Expr) Expr {
+	e := &InstanceOfExpr{X: x}
+	var pattern Expr
+	if p.try(func() { pattern = p.parsePattern() }) {
+		e.Pattern = pattern
+		switch pt := pattern.(type) {
+		case *TypePattern:
+			e.Type = pt.Type
+		case *RecordPattern:
+			e.Type = pt.Type
+		}
+	} else {
+		p.got("final")
+		e.Type = p.parseType()
+	}
+	first, _ := x.Span()
+	e.Range = p.rangeFrom(first)
+	return e
+}
+
+func (p *parser) parseUnary() Expr {
+	p.enter()
+	defer p.leave()
+	first := p.pos
+	tok := p.tok()
+	switch {
+	case tok.Is("+"), tok.Is("-"), tok.Is("++"), tok.Is("--"), tok.Is("!"), tok.Is("~"):
+		p.next()
+		x := p.parseUnary()
+		return &UnaryExpr{Range: p.rangeFrom(first), Op: tok.Text, X: x}
+	case tok.Is("("):
+		if cast := p.tryCast(); cast != nil {
+			return cast
+		}
+	}
+	x := p.parsePostfix()
+	for p.is("++") || p.is("--") {
+		op := p.next().Text
+		x = &UnaryExpr{Range: p.rangeFrom(first), Op: op, X: x, Postfix: true}
+	}
+	return x
+}
+
+// tryCast 尝试解析类型转换 (Type) x；括号后的内容不能作为转换的操作数时返回nil
+func (p *parser) tryCast() Expr {
+	first := p.pos
+	var types []*Type
+	primitive := false
+	ok := p.try(func() {
+		p.expect("(")
+		primitive = p.isPrimitive()
+		types = append(types, p.parseType())
+		for p.got("&") {
+			types = append(types, p.parseType())
+		}
+		p.expect(")")
+	})
+	if !ok {
+		return nil
+	}
+	if primitive && types[0].Dims == 0 && len(types) == 1 {
+		// 基本类型转换的操作数可以是任意一元表达式，如 (int) -x
+		if !p.startsUnary() {
+			p.pos = first
+			return nil
+		}
+	} else if !p.startsCastOperand() {
+		p.pos = first
+		return nil
+	}
+
+	var x Expr
+	if p.lambdaAhead() {
+		x = p.parseLambda()
+	} else {
+		x = p.parseUnary()
+	}
+	return &CastExpr{Range: p.rangeFrom(first), Types: types, X: x}
+}
+
+func (p *parser) startsUnary() bool {
+	tok := p.tok()
+	return p.startsCastOperand() || tok.Is("+") || tok.Is("-") || tok.Is("++") || tok.Is("--")
+}
+
+// startsCastOperand 判断当前位置能否作为引用类型转换的操作数（不能以+、-开头）
+func (p *parser) startsCastOperand() bool {
+	tok := p.tok()
+	switch tok.Kind {
+	case Ident, IntLiteral, FloatLiteral, CharLiteral, StringLiteral, TextBlock:
+		return true
+	case Keyword:
+		switch tok.Text {
+		case "this", "super", "new", "true", "false", "null", "switch":
+			return true
+		}
+		return primitiveTypes[tok.Text]
+	}
+	return tok.Is("(") || tok.Is("!") || tok.Is("~")
+}
+
+// lambdaAhead 判断当前位置是否为lambda表达式：x ->、(...) ->
+func (p *parser) lambdaAhead() bool {
+	switch {
+	case p.isIdent(""):
+		return p.peek(1).Is("->")
+	case p.is("("):
+		end := int(p.parens[p.pos])
+		return end > 0 && end+1 < len(p.toks) && p.toks[end+1].Is("->")
+	}
+	return false
+}
+
+func (p *parser) parseLambda() Expr {
+	first := p.pos
+	lambda := &LambdaExpr{}
+	if p.isIdent("") {
+		name := p.parseIdent()
+		lambda.Params = []*Param{{Range: name.Range, Name: name}}
+	} else {
+		p.expect("(")
+		for !p.is(")") {
+			paramFirst := p.pos
+			if p.isIdent("") && (p.peek(1).Is(",") || p.peek(1).Is(")")) {
+				name := p.parseIdent()
+				lambda.Params = append(lambda.Params, &Param{Range: p.rangeFrom(paramFirst), Name: name})
+			} else {
+				lambda.Params = append(lambda.Params, p.parseParam())
+			}
+			if !p.got(",") {
+				break
+			}
+		}
+		p.expect(")")
+	}
+	p.expect("->")
+	if p.is("{") {
+		lambda.Body = p.parseBlock()
+	} else {
+		lambda.Body = p.parseExpr()
+	}
+	lambda.Range = p.rangeFrom(first)
+	return lambda
+}
+
+// parsePostfix 解析基本表达式及其后的成员访问、调用、下标和方法引用
+func (p *parser) parsePostfix() Expr {
+	first := p.pos
+	x := p.parsePrimary()
+	for {
+		switch {
+		case p.is("."):
+			x = p.parseSelector(first, x)
+		case p.is("[") && p.peek(1).Is("]"):
+			// Type[].class 或 Type[]::new
+			t := p.exprToType(x)
+			t.Dims = p.parseDims()
+			t.Range = p.rangeFrom(first)
+			return p.parseTypeSuffix(first, t)
+		case p.is("["):
+			p.next()
+			index := p.parseExpr()
+			p.expect("]")
+			x = &IndexExpr{Range: p.rangeFrom(first), X: x, Index: index}
+		case p.is("::"):
+			x = p.parseMethodRef(first, x)
+		default:
+			return x
+		}
+	}
+}
+
+func (p *parser) parseSelector(first int, x Expr) Expr {
+	p.expect(".")
+	switch {
+	case p.is("new"):
+		return p.parseNew(first, x)
+	case p.is("this"):
+		p.next()
+		return &ThisExpr{Range: p.rangeFrom(first), Qualifier: x}
+	case p.is("super"):
+		p.next()
+		if p.is("(") {
+			name := &Identifier{Range: p.rangeFrom(p.pos - 1), Name: "super"}
+			return &CallExpr{X: x, Name: name, Args: p.parseArgs(), Range: p.rangeFrom(first)}
+		}
+		return &SuperExpr{Range: p.rangeFrom(first), Qualifier: x}
+	case p.is("class"):
+		p.next()
+		return &ClassLit{Range: p.rangeFrom(first), Type: p.exprToType(x)}
+	}
+
+	call := &CallExpr{X: x}
+	if p.is("<") {
+		call.TypeArgs = p.parseTypeArgs()
+	}
+	name := p.parseIdent()
+	if !p.is("(") {
+		if call.TypeArgs != nil {
+			p.fail("期望'('")
+		}
+		return &SelectorExpr{Range: p.rangeFrom(first), X: x, Name: name}
+	}
+	call.Name = name
+	call.Args = p.parseArgs()
+	call.Range = p.rangeFrom(first)
+	return call
+}
+
+func (p *parser) parseMethodRef(first int, x Node) Expr {
+	p.expect("::")
+	ref := &MethodRef{X: x}
+	if p.is("<") {
+		ref.TypeArgs = p.parseTypeArgs()
+	}
+	if p.is("new") {
+		p.next()
+		ref.Name = &Identifier{Range: p.rangeFrom(p.pos - 1), Name: "new"}
+	} else {
+		ref.Name = p.parseIdent()
+	}
+	ref.Range = p.rangeFrom(first)
+	return ref
+}
+
+// parseTypeSuffix 解析类型之后的 .class 或 ::
+func (p *parser) parseTypeSuffix(first int, t *Type) Expr {
+	if p.is("::") {
+		return p.parseMethodRef(first, t)
+	}
+	p.expect(".")
+	p.expect("class")
+	return &ClassLit{Range: p.rangeFrom(first), Type: t}
+}
+
+// exprToType 把限定名表达式转换为类型，用于 a.b.C.class 等
+func (p *parser) exprToType(x Expr) *Type {
+	var name string
+	var collect func(e Expr) bool
+	collect = func(e Expr) bool {
+		switch e := e.(type) {
+		case *Identifier:
+			name = e.Name
+			return true
+		case *SelectorExpr:
+			if !collect(e.X) {
+				return false
+			}
+			name += "." + e.Name.Name
+			return true
+		}
+		return false
+	}
+	if !collect(x) {
+		p.fail("期望类型名")
+	}
+	first, last := x.Span()
+	return &Type{Range: Range{Start: x.Pos(), Stop: x.End(), First: first, Last: last}, Name: name}
+}
+
+func (p *parser) parseArgs() []Expr {
+	p.expect("(")
+	var args []Expr
+	for !p.is(")") {
+		args = append(args, p.parseExpr())
+		if !p.got(",") {
+			break
+		}
+	}
+	p.expect(")")
+	return args
+}
+
+func (p *parser) parsePrimary() Expr {
+	first := p.pos
+	tok := p.tok()
+	switch {
+	case tok.Kind.IsLiteral(), tok.Is("true"), tok.Is("false"), tok.Is("null"):
+		p.next()
+		return &Literal{Range: p.rangeFrom(first), Kind: tok.Kind, Value: tok.Text}
+	case tok.Is("("):
+		p.next()
+		x := p.parseExpr()
+		p.expect(")")
+		return &ParenExpr{Range: p.rangeFrom(first), X: x}
+	case tok.Is("this"), tok.Is("super"):
+		p.next()
+		if p.is("(") {
+			name := &Identifier{Range: p.rangeFrom(first), Name: tok.Text}
+			return &CallExpr{Name: name, Args: p.parseArgs(), Range: p.rangeFrom(first)}
+		}
+		if tok.Text == "this" {
+			return &ThisExpr{Range: p.rangeFrom(first)}
+		}
+		return &SuperExpr{Range: p.rangeFrom(first)}
+	case tok.Is("new"):
+		return p.parseNew(first, nil)
+	case tok.Is("switch"):
+		p.next()
+		s := &SwitchExpr{Selector: p.parseParenExpr()}
+		s.Cases = p.parseSwitchBody()
+		s.Range = p.rangeFrom(first)
+		return s
+	case p.isPrimitive():
+		return p.parseTypeSuffix(first, p.parseType())
+	case tok.Kind == Ident:
+		if p.peek(1).Is("<") {
+			// 泛型类型的方法引用 List<String>::size
+			var t *Type
+			if p.try(func() {
+				t = p.parseType()
+				if !p.is("::") {
+					p.fail("期望'::'")
+				}
+			}) {
+				return p.parseMethodRef(first, t)
+			}
+		}
+		name := p.parseIdent()
+		if p.is("(") {
+			return &CallExpr{Name: name, Args: p.parseArgs(), Range: p.rangeFrom(first)}
+		}
+		return name
+	}
+	p.fail("期望表达式")
+	return nil
+}
+
+// parseNew 解析对象或数组创建，outer为限定创建 outer.new Inner() 的外部实例
+func (p *parser) parseNew(first int, outer Expr) Expr {
+	p.expect("new")
+	var typeArgs []*Type
+	if p.is("<") {
+		typeArgs = p.parseTypeArgs()
+	}
+
+	typeFirst := p.pos
+	t := &Type{Annotations: p.parseModifiers().Annotations}
+	if p.isPrimitive() {
+		t.Name = p.next().Text
+	} else {
+		t.Name = p.parseIdent().Name
+		if p.is("<") {
+			t.Args = p.parseTypeArgs()
+		}
+		for p.is(".") {
+			p.next()
+			p.parseModifiers()
+			t.Name += "." + p.parseIdent().Name
+			t.Args = nil
+			if p.is("<") {
+				t.Args = p.parseTypeArgs()
+			}
+		}
+	}
+	t.Range = p.rangeFrom(typeFirst)
+
+	if p.is("[") {
+		arr := &NewArrayExpr{Type: t}
+		for p.is("[") {
+			p.next()
+			if p.got("]") {
+				arr.ExtraDims++
+				continue
+			}
+			arr.Dims = append(arr.Dims, p.parseExpr())
+			p.expect("]")
+		}
+		if p.is("{") {
+			arr.Init = p.parseElementValue().(*ArrayInit)
+		}
+		arr.Range = p.rangeFrom(first)
+		return arr
+	}
+
+	e := &NewExpr{Outer: outer, TypeArgs: typeArgs, Type: t, Args: p.parseArgs()}
+	if p.is("{") {
+		bodyFirst := p.pos
+		e.Body = &TypeDecl{Kind: ClassKind, Outer: p.owner}
+		p.parseClassBody(e.Body)
+		e.Body.Range = p.rangeFrom(bodyFirst)
+	}
+	e.Range = p.rangeFrom(first)
+	return e
+}
@@ -0,0 +1,395 @@
+package java
+
+// parseBlock 解析语句块，语句解析出错时跳过该语句
+func (p *parser) parseBlock() *Block {
+	first := p.pos
+	p.expect("{")
+	block := &Block{}
+	for !p.is("}") && p.tok().Kind != EOF {
+		block.Stmts = append(block.Stmts, p.parseStmtGuarded())
+	}
+	p.closeBrace()
+	block.Range = p.rangeFrom(first)
+	return block
+}
+
+// closeBrace 读取块结尾的'}'，文件提前结束时只记录错误，保留已解析的部分
+func (p *parser) closeBrace() {
+	if p.tok().Kind == EOF {
+		// 外层的块也会缺少'}'，只报告一次
+		if n := len(p.errors); n == 0 || p.errors[n-1].Pos != p.tok().Pos {
+			p.errors = append(p.errors, &Error{Pos: p.tok().Pos, Msg: "缺少'}'，文件提前结束"})
+		}
+		return
+	}
+	p.expect("}")
+}
+
+func (p *parser) parseStmtGuarded() Stmt {
+	start := p.pos
+	var stmt Stmt
+	if p.guard(start, func() { stmt = p.parseStmt() }) {
+		if p.pos == start {
+			p.next()
+		}
+		return &BadStmt{Range: p.rangeFrom(start)}
+	}
+	return stmt
+}
+
+// parseStmt 解析块中的语句（含局部变量和局部类型声明）
+func (p *parser) parseStmt() Stmt {
+	p.enter()
+	defer p.leave()
+	first := p.pos
+	tok := p.tok()
+	switch {
+	case tok.Is("{"):
+		return p.parseBlock()
+	case tok.Is(";"):
+		p.next()
+		return &EmptyStmt{Range: p.rangeFrom(first)}
+	case tok.Kind == Ident && p.peek(1).Is(":"):
+		label := p.parseIdent()
+		p.next()
+		s := &LabeledStmt{Label: label, Stmt: p.parseStmt()}
+		s.Range = p.rangeFrom(first)
+		return s
+	case tok.Is("if"):
+		return p.parseIf()
+	case tok.Is("for"):
+		return p.parseFor()
+	case tok.Is("while"):
+		p.next()
+		s := &WhileStmt{Cond: p.parseParenExpr()}
+		s.Body = p.parseStmt()
+		s.Range = p.rangeFrom(first)
+		return s
+	case tok.Is("do"):
+		p.next()
+		s := &DoStmt{Body: p.parseStmt()}
+		p.expect("while")
+		s.Cond = p.parseParenExpr()
+		p.expect(";")
+		s.Range = p.rangeFrom(first)
+		return s
+	case tok.Is("switch"):
+		p.next()
+		s := &SwitchStmt{Selector: p.parseParenExpr()}
+		s.Cases = p.parseSwitchBody()
+		s.Range = p.rangeFrom(first)
+		return s
+	case tok.Is("return"):
+		p.next()
+		s := &ReturnStmt{}
+		if !p.is(";") {
+			s.X = p.parseExpr()
+		}
+		p.expect(";")
+		s.Range = p.rangeFrom(first)
+		return s
+	case tok.Is("break"), tok.Is("continue"):
+		p.next()
+		s := &BranchStmt{Keyword: tok.Text}
+		if p.isIdent("") {
+			s.Label = p.parseIdent()
+		}
+		p.expect(";")
+		s.Range = p.rangeFrom(first)
+		return s
+	case tok.Is("throw"):
+		p.next()
+		s := &ThrowStmt{X: p.parseExpr()}
+		p.expect(";")
+		s.Range = p.rangeFrom(first)
+		return s
+	case tok.Kind == Ident && tok.Text == "yield" && p.isYield():
+		p.next()
+		s := &YieldStmt{X: p.parseExpr()}
+		p.expect(";")
+		s.Range = p.rangeFrom(first)
+		return s
+	case tok.Is("try"):
+		return p.parseTry()
+	case tok.Is("synchronized") && p.peek(1).Is("("):
+		p.next()
+		s := &SyncStmt{Lock: p.parseParenExpr(), Body: p.parseBlock()}
+		s.Range = p.rangeFrom(first)
+		return s
+	case tok.Is("assert"):
+		p.next()
+		s := &AssertStmt{Cond: p.parseExpr()}
+		if p.got(":") {
+			s.Msg = p.parseExpr()
+		}
+		p.expect(";")
+		s.Range = p.rangeFrom(first)
+		return s
+	}
+
+	if p.localDeclAhead() {
+		mods := p.parseModifiers()
+		if p.isTypeDeclStart() {
+			s := &LocalTypeStmt{Decl: p.parseTypeDecl(first, mods)}
+			s.Range = p.rangeFrom(first)
+			return s
+		}
+		s := p.parseLocalVarRest(first, mods)
+		p.expect(";")
+		s.Range = p.rangeFrom(first)
+		return s
+	}
+
+	s := &ExprStmt{X: p.parseExpr()}
+	p.expect(";")
+	s.Range = p.rangeFrom(first)
+	return s
+}
+
+// isYield 判断yield是否为yield语句而非名为yield的变量或方法
+func (p *parser) isYield() bool {
+	next := p.peek(1)
+	if next.Kind != Operator {
+		return next.Kind != EOF
+	}
+	switch next.Text {
+	case "(", "!", "~", "+", "-", "++", "--":
+		return true
+	}
+	return false
+}
+
+// localDeclAhead 判断当前位置是否为局部变量或局部类型声明：[修饰符] 类型 名称 (= , ; : [)
+func (p *parser) localDeclAhead() bool {
+	tok := p.tok()
+	if !(tok.Kind == Ident || tok.Is("@") || tok.Is("final") || tok.Is("abstract") ||
+		tok.Is("static") || tok.Is("class") || tok.Is("interface") || tok.Is("enum") || p.isPrimitive()) {
+		return false
+	}
+	decl := false
+	p.try(func() {
+		p.parseModifiers()
+		if p.isTypeDeclStart() {
+			decl = true
+		} else {
+			p.parseType()
+			p.parseIdent()
+			next := p.tok()
+			decl = next.Is("=") || next.Is(";") || next.Is(",") || next.Is(":") || next.Is("[")
+		}
+		p.fail("回退")
+	})
+	return decl
+}
+
+func (p *parser) parseLocalVarRest(first int, mods Modifiers) *LocalVarStmt {
+	s := &LocalVarStmt{Modifiers: mods, Type: p.parseType(), Vars: p.parseVarDecls()}
+	s.Range = p.rangeFrom(first)
+	return s
+}
+
+func (p *parser) parseParenExpr() Expr {
+	p.expect("(")
+	x := p.parseExpr()
+	p.expect(")")
+	return x
+}
+
+func (p *parser) parseIf() *IfStmt {
+	first := p.pos
+	p.expect("if")
+	s := &IfStmt{Cond: p.parseParenExpr(), Then: p.parseStmt()}
+	if p.got("else") {
+		s.Else = p.parseStmt()
+	}
+	s.Range = p.rangeFrom(first)
+	return s
+}
+
+func (p *parser) parseFor() Stmt {
+	first := p.pos
+	p.expect("for")
+	p.expect("(")
+
+	var init []Stmt
+	if !p.is(";") {
+		if p.localDeclAhead() {
+			declFirst := p.pos
+			mods := p.parseModifiers()
+			typ := p.parseType()
+			if p.isIdent("") && p.peek(1).Is(":") {
+				v := &Param{Modifiers: mods, Type: typ, Name: p.parseIdent()}
+				v.Range = p.rangeFrom(declFirst)
+				p.next()
+				s := &ForEachStmt{Var: v, X: p.parseExpr()}
+				p.expect(")")
+				s.Body = p.parseStmt()
+				s.Range = p.rangeFrom(first)
+				return s
+			}
+			decl := &LocalVarStmt{Modifiers: mods, Type: typ, Vars: p.parseVarDecls()}
+			decl.Range = p.rangeFrom(declFirst)
+			init = append(init, decl)
+		} else {
+			for _, x := range p.parseExprList() {
+				init = append(init, &ExprStmt{Range: between(x, x), X: x})
+			}
+		}
+	}
+	p.expect(";")
+
+	s := &ForStmt{Init: init}
+	if !p.is(";") {
+		s.Cond = p.parseExpr()
+	}
+	p.expect(";")
+	if !p.is(")") {
+		s.Update = p.parseExprList()
+	}
+	p.expect(")")
+	s.Body = p.parseStmt()
+	s.Range = p.rangeFrom(first)
+	return s
+}
+
+func (p *parser) parseExprList() []Expr {
+	list := []Expr{p.parseExpr()}
+	for p.got(",") {
+		list = append(list, p.parseExpr())
+	}
+	return list
+}
+
+func (p *parser) parseTry() *TryStmt {
+	first := p.pos
+	p.expect("try")
+	s := &TryStmt{}
+	if p.got("(") {
+		for !p.is(")") {
+			resFirst := p.pos
+			if p.localDeclAhead() {
+				s.Resources = append(s.Resources, p.parseLocalVarRest(resFirst, p.parseModifiers()))
+			} else {
+				x := p.parseExpr()
+				s.Resources = append(s.Resources, &ExprStmt{Range: between(x, x), X: x})
+			}
+			if !p.got(";") {
+				break
+			}
+		}
+		p.expect(")")
+	}
+	s.Body = p.parseBlock()
+	for p.is("catch") {
+		catchFirst := p.pos
+		p.next()
+		p.expect("(")
+		c := &CatchClause{Modifiers: p.parseModifiers(), Types: []*Type{p.parseType()}}
+		for p.got("|") {
+			c.Types = append(c.Types, p.parseType())
+		}
+		c.Name = p.parseIdent()
+		p.expect(")")
+		c.Body = p.parseBlock()
+		c.Range = p.rangeFrom(catchFirst)
+		s.Catches = append(s.Catches, c)
+	}
+	if p.got("finally") {
+		s.Finally = p.parseBlock()
+	}
+	if s.Catches == nil && s.Finally == nil && s.Resources == nil {
+		p.fail("try缺少catch或finally")
+	}
+	s.Range = p.rangeFrom(first)
+	return s
+}
+
+// parseSwitchBody 解析switch语句或表达式的分支
+func (p *parser) parseSwitchBody() []*SwitchCase {
+	p.expect("{")
+	var cases []*SwitchCase
+	for !p.is("}") && p.tok().Kind != EOF {
+		cases = append(cases, p.parseSwitchCase())
+	}
+	p.closeBrace()
+	return cases
+}
+
+func (p *parser) parseSwitchCase() *SwitchCase {
+	first := p.pos
+	c := &SwitchCase{}
+	if p.got("default") {
+		c.Default = true
+	} else {
+		p.expect("case")
+		for {
+			if p.got("default") {
+				c.Default = true
+			} else {
+				c.Labels = append(c.Labels, p.parseCaseLabel())
+			}
+			if !p.got(",") {
+				break
+			}
+		}
+		if p.isIdent("when") {
+			p.next()
+			c.Guard = p.parseCond()
+		}
+	}
+
+	if p.got("->") {
+		c.Arrow = true
+		switch {
+		case p.is("{"):
+			c.Body = []Stmt{p.parseBlock()}
+		case p.is("throw"):
+			c.Body = []Stmt{p.parseStmt()}
+		default:
+			bodyFirst := p.pos
+			x := p.parseExpr()
+			p.expect(";")
+			c.Body = []Stmt{&ExprStmt{Range: p.rangeFrom(bodyFirst), X: x}}
+		}
+	} else {
+		p.expect(":")
+		for !p.is("case") && !p.is("default") && !p.is("}") && p.tok().Kind != EOF {
+			c.Body = append(c.Body, p.parseStmtGuarded())
+		}
+	}
+	c.Range = p.rangeFrom(first)
+	return c
+}
+
+// parseCaseLabel 解析case标签：类型模式、record模式或常量表达式，
+// 常量表达式不按lambda解析，以免把 case A -> 误认为lambda
+func (p *parser) parseCaseLabel() Expr {
+	var pattern Expr
+	if p.try(func() { pattern = p.parsePattern() }) {
+		return pattern
+	}
+	return p.parseCond()
+}
+
+// parsePattern 解析类型模式 Type name 或record模式 Type(...)
+func (p *parser) parsePattern() Expr {
+	first := p.pos
+	mods := p.parseModifiers()
+	typ := p.parseType()
+	if p.is("(") {
+		rp := &RecordPattern{Type: typ}
+		p.next()
+		for !p.is(")") {
+			rp.Patterns = append(rp.Patterns, p.parsePattern())
+			if !p.got(",") {
+				break
+			}
+		}
+		p.expect(")")
+		rp.Range = p.rangeFrom(first)
+		return rp
+	}
+	tp := &TypePattern{Modifiers: mods, Type: typ, Name: p.parseIdent()}
+	tp.Range = p.rangeFrom(first)
+	return tp
+}
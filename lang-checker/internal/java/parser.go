@@ -0,0 +1,774 @@
+package java
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Parse 解析Java源码，语法错误记录在File.Errors中，不会中断解析
+func Parse(src string) *File {
+	return ParseTokens(Tokenize(src))
+}
+
+// ParseTokens 解析Tokenize的结果（含注释），注释用于关联声明的Javadoc
+func ParseTokens(tokens []Token) *File {
+	file, _ := ParseTokensContext(context.Background(), tokens)
+	return file
+}
+
+// ParseTokensContext 与ParseTokens相同，ctx取消时停止解析并返回其错误
+func ParseTokensContext(ctx context.Context, tokens []Token) (file *File, err error) {
+	p := newParser(tokens)
+	p.ctx = ctx
+	defer func() {
+		if r := recover(); r != nil {
+			if _, isCanceled := r.(canceled); !isCanceled {
+				panic(r)
+			}
+			file, err = nil, ctx.Err()
+		}
+	}()
+	file = p.parseFile()
+	file.Tokens = p.toks[:len(p.toks)-1]
+	file.Errors = p.errors
+	return file, nil
+}
+
+// bailout 语法错误时用于退出当前成员或语句的panic值
+type bailout struct{}
+
+// canceled ctx取消时用于退出整个解析的panic值，try和guard不会拦截
+type canceled struct{}
+
+// cancelCheckInterval 每读取多少个词法单元检查一次ctx，回溯时重复读取的也计入
+const cancelCheckInterval = 4096
+
+// maxNesting 表达式和语句的最大嵌套层数，超过时记录语法错误并跳过所在语句，避免病态输入耗尽时间和栈空间
+const maxNesting = 1000
+
+type parser struct {
+	// toks 不含注释的词法单元，末尾追加EOF
+	toks []Token
+	// docs 每个词法单元之前紧邻的Javadoc注释
+	docs []*Token
+	// parens '('对应的')'的下标，两者之间有';'、'{'或'}'时为0，用于lambda的前瞻判断
+	parens []int32
+	pos    int
+	errors []*Error
+	// owner 当前所在的类型声明
+	owner *TypeDecl
+	// depth 当前的表达式和语句嵌套层数
+	depth int
+
+	ctx context.Context
+	// steps 已读取的词法单元数，用于定期检查ctx
+	steps int
+}
+
+func newParser(tokens []Token) *parser {
+	p := &parser{toks: make([]Token, 0, len(tokens)+1), docs: make([]*Token, 0, len(tokens)+1)}
+	var doc *Token
+	for i := range tokens {
+		tok := &tokens[i]
+		switch tok.Kind {
+		case DocComment:
+			doc = tok
+		case LineComment, BlockComment:
+		default:
+			p.toks = append(p.toks, *tok)
+			p.docs = append(p.docs, doc)
+			doc = nil
+		}
+	}
+	end := Pos{Line: 1, Column: 1}
+	if n := len(tokens); n > 0 {
+		end = tokens[n-1].End
+	}
+	p.toks = append(p.toks, Token{Kind: EOF, Pos: end, End: end})
+	p.docs = append(p.docs, nil)
+	p.parens = matchParens(p.toks)
+	return p
+}
+
+// matchParens 一次性计算每个'('对应的')'，避免逐层嵌套的括号各自向后扫描
+func matchParens(toks []Token) []int32 {
+	parens := make([]int32, len(toks))
+	var open []int32
+	for i, tok := range toks {
+		switch {
+		case tok.Is("("):
+			open = append(open, int32(i))
+		case tok.Is(")"):
+			if n := len(open); n > 0 {
+				parens[open[n-1]] = int32(i)
+				open = open[:n-1]
+			}
+		case tok.Is(";"), tok.Is("{"), tok.Is("}"):
+			// 与lambdaAhead一致：括号内出现语句或代码块时不视为lambda参数列表
+			open = open[:0]
+		}
+	}
+	return parens
+}
+
+// enter 进入一层嵌套，超过maxNesting时报告语法错误；调用方需defer p.leave()
+func (p *parser) enter() {
+	if p.depth >= maxNesting {
+		p.fail("嵌套超过%d层", maxNesting)
+	}
+	p.depth++
+}
+
+func (p *parser) leave() {
+	p.depth--
+}
+
+// 词法单元访问
+
+func (p *parser) tok() Token {
+	return p.toks[p.pos]
+}
+
+func (p *parser) peek(n int) Token {
+	if i := p.pos + n; i < len(p.toks) {
+		return p.toks[i]
+	}
+	return p.toks[len(p.toks)-1]
+}
+
+func (p *parser) next() Token {
+	tok := p.toks[p.pos]
+	if tok.Kind != EOF {
+		p.pos++
+	}
+	if p.steps++; p.steps%cancelCheckInterval == 0 && p.ctx != nil && p.ctx.Err() != nil {
+		panic(canceled{})
+	}
+	return tok
+}
+
+// is 判断当前词法单元是否为指定的运算符或关键字
+func (p *parser) is(text string) bool {
+	return p.toks[p.pos].Is(text)
+}
+
+// isIdent 判断当前词法单元是否为标识符，text非空时还要求文本相同（用于record、sealed等上下文关键字）
+func (p *parser) isIdent(text string) bool {
+	tok := p.toks[p.pos]
+	return tok.Kind == Ident && (text == "" || tok.Text == text)
+}
+
+func (p *parser) got(text string) bool {
+	if p.is(text) {
+		p.next()
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(text string) Token {
+	if !p.is(text) {
+		p.fail("期望%q", text)
+	}
+	return p.next()
+}
+
+func (p *parser) fail(format string, args ...any) {
+	tok := p.tok()
+	found := tok.Text
+	if tok.Kind == EOF {
+		found = "文件结尾"
+	}
+	p.errors = append(p.errors, &Error{Pos: tok.Pos, Msg: fmt.Sprintf(format, args...) + "，实际为" + found})
+	panic(bailout{})
+}
+
+// rangeFrom 返回从first到上一个已读词法单元的范围
+func (p *parser) rangeFrom(first int) Range {
+	last := max(p.pos-1, first)
+	return Range{Start: p.toks[first].Pos, Stop: p.toks[last].End, First: first, Last: last}
+}
+
+// between 返回两个节点之间（含）的范围
+func between(from, to Node) Range {
+	first, _ := from.Span()
+	_, last := to.Span()
+	return Range{Start: from.Pos(), Stop: to.End(), First: first, Last: last}
+}
+
+// try 尝试按f解析，失败时回退到起始位置并丢弃f产生的错误
+func (p *parser) try(f func()) (ok bool) {
+	pos, nerr, owner := p.pos, len(p.errors), p.owner
+	defer func() {
+		if r := recover(); r != nil {
+			if _, isBailout := r.(bailout); !isBailout {
+				panic(r)
+			}
+			p.pos, p.errors, p.owner = pos, p.errors[:nerr], owner
+			ok = false
+		}
+	}()
+	f()
+	return true
+}
+
+// guard 解析f，出错时从start开始跳过一个语句或成员，返回是否出错
+func (p *parser) guard(start int, f func()) (failed bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			if _, isBailout := r.(bailout); !isBailout {
+				panic(r)
+			}
+			p.pos = start
+			p.skipUnit()
+			failed = true
+		}
+	}()
+	f()
+	return false
+}
+
+// skipUnit 跳过到下一个';'之后，或跳过一个完整的花括号块；不会越过外层的'}'。
+// 出错的语句常有不配对的括号，因此只按花括号判断边界
+func (p *parser) skipUnit() {
+	for p.tok().Kind != EOF {
+		switch {
+		case p.is("{"):
+			p.skipBraces()
+			// 匿名类或lambda体之后还有同一语句的剩余部分
+			if !p.is(".") && !p.is(")") && !p.is(",") && !p.is(";") {
+				return
+			}
+			continue
+		case p.is("}"):
+			return
+		case p.is(";"):
+			p.next()
+			return
+		}
+		p.next()
+	}
+}
+
+// skipBraces 跳过从当前'{'开始的平衡花括号块
+func (p *parser) skipBraces() {
+	depth := 0
+	for p.tok().Kind != EOF {
+		switch {
+		case p.is("{"):
+			depth++
+		case p.is("}"):
+			depth--
+			if depth == 0 {
+				p.next()
+				return
+			}
+		}
+		p.next()
+	}
+}
+
+// 编译单元和声明
+
+func (p *parser) parseFile() *File {
+	file := &File{}
+	for p.tok().Kind != EOF {
+		start := p.pos
+		if p.guard(start, func() { p.parseTopLevel(file) }) && p.pos == start {
+			p.next()
+		}
+	}
+	file.Range = Range{Start: p.toks[0].Pos, Stop: p.toks[len(p.toks)-1].End, Last: max(len(p.toks)-2, 0)}
+	return file
+}
+
+func (p *parser) parseTopLevel(file *File) {
+	switch {
+	case p.got(";"):
+		return
+	case p.is("import"):
+		file.Imports = append(file.Imports, p.parseImport())
+		return
+	case (p.isIdent("module") || p.isIdent("open")) && len(file.Types) == 0:
+		// module-info.java 不含需要分析的代码
+		for p.tok().Kind != EOF {
+			p.next()
+		}
+		return
+	}
+
+	first := p.pos
+	mods := p.parseModifiers()
+	if p.is("package") {
+		p.next()
+		file.Package = p.parseQualifiedName()
+		p.expect(";")
+		return
+	}
+	file.Types = append(file.Types, p.parseTypeDecl(first, mods))
+}
+
+func (p *parser) parseImport() *Import {
+	first := p.pos
+	p.expect("import")
+	imp := &Import{Static: p.got("static")}
+	var name strings.Builder
+	name.WriteString(p.parseIdent().Name)
+	for p.got(".") {
+		if p.got("*") {
+			imp.Wildcard = true
+			break
+		}
+		name.WriteString("." + p.parseIdent().Name)
+	}
+	p.expect(";")
+	imp.Path = name.String()
+	imp.Range = p.rangeFrom(first)
+	return imp
+}
+
+func (p *parser) parseIdent() *Identifier {
+	tok := p.tok()
+	if tok.Kind != Ident {
+		p.fail("期望标识符")
+	}
+	p.next()
+	return &Identifier{Range: p.rangeFrom(p.pos - 1), Name: tok.Text}
+}
+
+func (p *parser) parseQualifiedName() string {
+	var name strings.Builder
+	name.WriteString(p.parseIdent().Name)
+	for p.is(".") && p.peek(1).Kind == Ident {
+		p.next()
+		name.WriteString("." + p.parseIdent().Name)
+	}
+	return name.String()
+}
+
+// parseModifiers 解析修饰符和注解，sealed和non-sealed为上下文关键字
+func (p *parser) parseModifiers() Modifiers {
+	var mods Modifiers
+	for {
+		tok := p.tok()
+		switch {
+		case tok.Is("@") && !p.peek(1).Is("interface"):
+			mods.Annotations = append(mods.Annotations, p.parseAnnotation())
+		case tok.Kind == Keyword && modifiers[tok.Text]:
+			p.next()
+			mods.Keywords = append(mods.Keywords, tok.Text)
+		case tok.Kind == Ident && tok.Text == "sealed" && p.startsTypeDecl(1):
+			p.next()
+			mods.Keywords = append(mods.Keywords, "sealed")
+		case tok.Kind == Ident && tok.Text == "non" && p.peek(1).Is("-") && p.peek(2).Kind == Ident && p.peek(2).Text == "sealed":
+			p.pos += 3
+			mods.Keywords = append(mods.Keywords, "non-sealed")
+		default:
+			return mods
+		}
+	}
+}
+
+// startsTypeDecl 判断peek(n)处是否为修饰符或类型声明关键字
+func (p *parser) startsTypeDecl(n int) bool {
+	tok := p.peek(n)
+	return tok.Is("class") || tok.Is("interface") || tok.Is("@") ||
+		(tok.Kind == Keyword && modifiers[tok.Text]) ||
+		(tok.Kind == Ident && (tok.Text == "record" || tok.Text == "sealed" || tok.Text == "non"))
+}
+
+func (p *parser) parseAnnotation() *Annotation {
+	first := p.pos
+	p.expect("@")
+	a := &Annotation{Name: p.parseQualifiedName()}
+	if p.got("(") {
+		for !p.is(")") {
+			if p.isIdent("") && p.peek(1).Is("=") {
+				name := p.parseIdent()
+				p.next()
+				value := p.parseElementValue()
+				a.Args = append(a.Args, &AssignExpr{Range: between(name, value), Op: "=", X: name, Y: value})
+			} else {
+				a.Args = append(a.Args, p.parseElementValue())
+			}
+			if !p.got(",") {
+				break
+			}
+		}
+		p.expect(")")
+	}
+	a.Range = p.rangeFrom(first)
+	return a
+}
+
+// parseElementValue 解析注解参数值：注解、数组初始化器或条件表达式
+func (p *parser) parseElementValue() Expr {
+	switch {
+	case p.is("@"):
+		return p.parseAnnotation()
+	case p.is("{"):
+		first := p.pos
+		p.next()
+		init := &ArrayInit{}
+		for !p.is("}") {
+			init.Elems = append(init.Elems, p.parseElementValue())
+			if !p.got(",") {
+				break
+			}
+		}
+		p.expect("}")
+		init.Range = p.rangeFrom(first)
+		return init
+	}
+	return p.parseCond()
+}
+
+// isTypeDeclStart 判断当前位置（修饰符之后）是否为类型声明
+func (p *parser) isTypeDeclStart() bool {
+	switch {
+	case p.is("class"), p.is("interface"), p.is("enum"):
+		return true
+	case p.is("@"):
+		return p.peek(1).Is("interface")
+	case p.isIdent("record"):
+		return p.peek(1).Kind == Ident && (p.peek(2).Is("(") || p.peek(2).Is("<"))
+	}
+	return false
+}
+
+func (p *parser) parseTypeDecl(first int, mods Modifiers) *TypeDecl {
+	td := &TypeDecl{Doc: p.docs[first], Modifiers: mods, Outer: p.owner}
+	switch {
+	case p.got("class"):
+		td.Kind = ClassKind
+	case p.got("interface"):
+		td.Kind = InterfaceKind
+	case p.got("enum"):
+		td.Kind = EnumKind
+	case p.isIdent("record"):
+		p.next()
+		td.Kind = RecordKind
+	case p.is("@") && p.peek(1).Is("interface"):
+		p.pos += 2
+		td.Kind = AnnotationKind
+	default:
+		p.fail("期望类型声明")
+	}
+	td.Name = p.parseIdent()
+	if p.is("<") {
+		td.TypeParams = p.parseTypeParams()
+	}
+	if td.Kind == RecordKind {
+		td.Components = p.parseParams()
+	}
+	if p.got("extends") {
+		td.Extends = p.parseTypeList()
+	}
+	if p.got("implements") {
+		td.Implements = p.parseTypeList()
+	}
+	if p.isIdent("permits") {
+		p.next()
+		td.Permits = p.parseTypeList()
+	}
+	p.parseClassBody(td)
+	td.Range = p.rangeFrom(first)
+	return td
+}
+
+func (p *parser) parseTypeList() []*Type {
+	list := []*Type{p.parseType()}
+	for p.got(",") {
+		list = append(list, p.parseType())
+	}
+	return list
+}
+
+func (p *parser) parseTypeParams() []*TypeParam {
+	p.expect("<")
+	var params []*TypeParam
+	for {
+		first := p.pos
+		p.parseModifiers()
+		tp := &TypeParam{Name: p.parseIdent().Name}
+		if p.got("extends") {
+			tp.Bounds = append(tp.Bounds, p.parseType())
+			for p.got("&") {
+				tp.Bounds = append(tp.Bounds, p.parseType())
+			}
+		}
+		tp.Range = p.rangeFrom(first)
+		params = append(params, tp)
+		if !p.got(",") {
+			break
+		}
+	}
+	p.expect(">")
+	return params
+}
+
+// parseClassBody 解析类体（含枚举常量），成员解析出错时跳过该成员
+func (p *parser) parseClassBody(td *TypeDecl) {
+	outer := p.owner
+	p.owner = td
+	defer func() { p.owner = outer }()
+
+	p.expect("{")
+	if td.Kind == EnumKind {
+		p.parseEnumConstants(td)
+	}
+	for !p.is("}") && p.tok().Kind != EOF {
+		start := p.pos
+		var member Decl
+		if p.guard(start, func() { member = p.parseMember(td) }) {
+			if p.pos == start {
+				p.next()
+			}
+			member = &BadDecl{Range: p.rangeFrom(start)}
+		}
+		if member != nil {
+			td.Members = append(td.Members, member)
+		}
+	}
+	p.closeBrace()
+}
+
+func (p *parser) parseEnumConstants(td *TypeDecl) {
+	for p.is("@") || p.isIdent("") {
+		first := p.pos
+		c := &EnumConstant{Doc: p.docs[first], Annotations: p.parseModifiers().Annotations, Name: p.parseIdent()}
+		if p.is("(") {
+			c.Args = p.parseArgs()
+		}
+		if p.is("{") {
+			bodyFirst := p.pos
+			c.Body = &TypeDecl{Kind: ClassKind, Outer: td}
+			p.parseClassBody(c.Body)
+			c.Body.Range = p.rangeFrom(bodyFirst)
+		}
+		c.Range = p.rangeFrom(first)
+		td.Constants = append(td.Constants, c)
+		if !p.got(",") {
+			break
+		}
+	}
+	if !p.got(";") && !p.is("}") {
+		p.fail("期望枚举常量")
+	}
+}
+
+// parseMember 解析类成员，多余的';'返回nil
+func (p *parser) parseMember(td *TypeDecl) Decl {
+	first := p.pos
+	switch {
+	case p.got(";"):
+		return nil
+	case p.is("{"), p.is("static") && p.peek(1).Is("{"):
+		init := &Initializer{Static: p.got("static"), Body: p.parseBlock()}
+		init.Range = p.rangeFrom(first)
+		return init
+	}
+
+	mods := p.parseModifiers()
+	if p.isTypeDeclStart() {
+		return p.parseTypeDecl(first, mods)
+	}
+
+	m := &MethodDecl{Doc: p.docs[first], Modifiers: mods, Owner: td}
+	if p.is("<") {
+		m.TypeParams = p.parseTypeParams()
+	}
+	switch {
+	case p.isIdent("") && p.peek(1).Is("("):
+		m.Constructor = true
+	case p.isIdent("") && p.peek(1).Is("{") && td.Kind == RecordKind:
+		m.Constructor, m.Compact = true, true
+	default:
+		m.Result = p.parseType()
+		if m.TypeParams == nil && !p.peek(1).Is("(") {
+			return p.parseFieldRest(first, mods, m.Result)
+		}
+	}
+
+	m.Name = p.parseIdent()
+	m.ParamsEnd = m.Name.End()
+	if !m.Compact {
+		m.Params = p.parseParams()
+		m.ParamsEnd = p.toks[p.pos-1].End
+		for p.is("[") && p.peek(1).Is("]") {
+			p.pos += 2
+			m.Result.Dims++
+		}
+		if p.got("throws") {
+			m.Throws = p.parseTypeList()
+		}
+	}
+	switch {
+	case p.is("{"):
+		m.Body = p.parseBlock()
+	case p.got("default"):
+		m.Default = p.parseElementValue()
+		p.expect(";")
+	default:
+		p.expect(";")
+	}
+	m.Range = p.rangeFrom(first)
+	return m
+}
+
+func (p *parser) parseFieldRest(first int, mods Modifiers, typ *Type) *FieldDecl {
+	f := &FieldDecl{Doc: p.docs[first], Modifiers: mods, Type: typ, Vars: p.parseVarDecls()}
+	p.expect(";")
+	f.Range = p.rangeFrom(first)
+	return f
+}
+
+// parseVarDecls 解析以逗号分隔的变量声明符
+func (p *parser) parseVarDecls() []*VarDecl {
+	var vars []*VarDecl
+	for {
+		first := p.pos
+		v := &VarDecl{Name: p.parseIdent(), Dims: p.parseDims()}
+		if p.got("=") {
+			if p.is("{") {
+				v.Init = p.parseElementValue()
+			} else {
+				v.Init = p.parseExpr()
+			}
+		}
+		v.Range = p.rangeFrom(first)
+		vars = append(vars, v)
+		if !p.got(",") {
+			return vars
+		}
+	}
+}
+
+// parseDims 解析变量名或类型之后的[]（可带类型注解），返回维数
+func (p *parser) parseDims() int {
+	dims := 0
+	for {
+		start := p.pos
+		for p.is("@") {
+			p.parseAnnotation()
+		}
+		if !p.is("[") || !p.peek(1).Is("]") {
+			p.pos = start
+			return dims
+		}
+		p.pos += 2
+		dims++
+	}
+}
+
+// parseParams 解析形参列表（含record组成部分和接收者参数）
+func (p *parser) parseParams() []*Param {
+	p.expect("(")
+	var params []*Param
+	for !p.is(")") {
+		params = append(params, p.parseParam())
+		if !p.got(",") {
+			break
+		}
+	}
+	p.expect(")")
+	return params
+}
+
+func (p *parser) parseParam() *Param {
+	first := p.pos
+	param := &Param{Modifiers: p.parseModifiers(), Type: p.parseType()}
+	p.parseModifiers()
+	param.Varargs = p.got("...")
+	if p.is("this") {
+		// 接收者参数 Foo this
+		p.next()
+		param.Name = &Identifier{Range: p.rangeFrom(p.pos - 1), Name: "this"}
+	} else {
+		param.Name = p.parseIdent()
+		param.Type.Dims += p.parseDims()
+	}
+	param.Range = p.rangeFrom(first)
+	return param
+}
+
+// 类型
+
+var primitiveTypes = map[string]bool{
+	"boolean": true, "byte": true, "char": true, "short": true,
+	"int": true, "long": true, "float": true, "double": true, "void": true,
+}
+
+// isPrimitive 判断当前词法单元是否为基本类型或void
+func (p *parser) isPrimitive() bool {
+	tok := p.tok()
+	return tok.Kind == Keyword && primitiveTypes[tok.Text]
+}
+
+// parseType 解析类型引用（含类型实参和数组维度）
+func (p *parser) parseType() *Type {
+	first := p.pos
+	t := &Type{Annotations: p.parseModifiers().Annotations}
+	if p.isPrimitive() {
+		t.Name = p.next().Text
+	} else {
+		var name strings.Builder
+		for {
+			name.WriteString(p.parseIdent().Name)
+			if p.is("<") {
+				t.Args = p.parseTypeArgs()
+			}
+			if !p.is(".") || (p.peek(1).Kind != Ident && !p.peek(1).Is("@")) {
+				break
+			}
+			p.next()
+			p.parseModifiers()
+			name.WriteByte('.')
+			t.Args = nil
+		}
+		t.Name = name.String()
+	}
+	t.Dims = p.parseDims()
+	t.Range = p.rangeFrom(first)
+	return t
+}
+
+// parseTypeArgs 解析类型实参，菱形<>返回空切片
+func (p *parser) parseTypeArgs() []*Type {
+	p.expect("<")
+	args := []*Type{}
+	for !p.is(">") {
+		if p.is("?") || (p.is("@") && p.wildcardAfterAnnotations()) {
+			first := p.pos
+			w := &Type{Annotations: p.parseModifiers().Annotations}
+			p.expect("?")
+			w.Name = "?"
+			switch {
+			case p.got("extends"):
+				w.Bound = p.parseType()
+			case p.got("super"):
+				w.Bound, w.Super = p.parseType(), true
+			}
+			w.Range = p.rangeFrom(first)
+			args = append(args, w)
+		} else {
+			args = append(args, p.parseType())
+		}
+		if !p.got(",") {
+			break
+		}
+	}
+	p.expect(">")
+	return args
+}
+
+func (p *parser) wildcardAfterAnnotations() bool {
+	isWildcard := false
+	p.try(func() {
+		p.parseModifiers()
+		isWildcard = p.is("?")
+		p.fail("回退")
+	})
+	return isWildcard
+}
@@ -0,0 +1,208 @@
+package java
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseDeepNesting(t *testing.T) {
+	const n = 100000
+	tests := map[string]string{
+		"parens": "return " + strings.Repeat("(", n) + "1" + strings.Repeat(")", n) + ";",
+		"casts":  "Object o = " + strings.Repeat("(Object) ", n) + "x;",
+		"lambda": "Runnable r = " + strings.Repeat("() -> ", n) + "x;",
+		"blocks": strings.Repeat("{", n) + strings.Repeat("}", n),
+	}
+	for name, body := range tests {
+		t.Run(name, func(t *testing.T) {
+			src := "class A {\n    int f() {\n        " + body + "\n    }\n    int g() { return 2; }\n}\n"
+			start := time.Now()
+			file := Parse(src)
+			if d := time.Since(start); d > 5*time.Second {
+				t.Errorf("解析耗时%s，应与输入长度成线性关系", d)
+			}
+			if len(file.Errors) == 0 || !strings.Contains(file.Errors[0].Msg, "嵌套超过") {
+				t.Errorf("应报告嵌套过深的语法错误: %v", file.Errors)
+			}
+			if methods := Methods(file); len(methods) != 2 || methods[1].Name.Name != "g" {
+				t.Errorf("出错之后的成员应继续解析: %d个方法", len(methods))
+			}
+		})
+	}
+}
+
+// has 返回判断语法树中是否存在满足pred的节点的函数
+func has(pred func(Node) bool) func(*File) bool {
+	return func(file *File) bool {
+		found := false
+		Inspect(file, func(n Node) bool {
+			found = found || pred(n)
+			return !found
+		})
+		return found
+	}
+}
+
+func TestParseModernSyntax(t *testing.T) {
+	tests := []struct {
+		name  string
+		src   string
+		check func(*File) bool
+	}{
+		{"record", `public record Point(int x, int y) implements Shape {
+    public Point {
+        if (x < 0) throw new IllegalArgumentException();
+    }
+    static Point origin() { return new Point(0, 0); }
+}`, func(f *File) bool {
+			r := f.Types[0]
+			return r.Kind == RecordKind && len(r.Components) == 2 && len(Methods(f)) == 2 && Methods(f)[0].Compact
+		}},
+		{"sealed和non-sealed", `sealed interface Shape permits Circle, Square {}
+final class Circle implements Shape {}
+non-sealed class Square implements Shape {}`, func(f *File) bool {
+			return len(f.Types) == 3 && len(f.Types[0].Permits) == 2 && f.Types[2].Has("non-sealed")
+		}},
+		{"switch表达式和yield", `class A {
+    int f(Day d) {
+        int n = switch (d) {
+            case MONDAY, FRIDAY -> 6;
+            case TUESDAY -> { yield 7; }
+            default -> throw new IllegalStateException();
+        };
+        return n;
+    }
+}`, has(func(n Node) bool {
+			s, ok := n.(*SwitchExpr)
+			return ok && len(s.Cases) == 3 && s.Cases[0].Arrow && len(s.Cases[0].Labels) == 2 && s.Cases[2].Default
+		})},
+		{"instanceof模式", `class A {
+    String f(Object o) {
+        if (o instanceof String s && !s.isEmpty()) return s;
+        return "";
+    }
+}`, has(func(n Node) bool {
+			x, ok := n.(*InstanceOfExpr)
+			return ok && x.Pattern != nil
+		})},
+		{"record模式和when", `class A {
+    int f(Object o) {
+        return switch (o) {
+            case Point(int x, int y) when x > 0 -> x + y;
+            case String s -> s.length();
+            default -> 0;
+        };
+    }
+}`, has(func(n Node) bool {
+			c, ok := n.(*SwitchCase)
+			if !ok || len(c.Labels) == 0 {
+				return false
+			}
+			_, record := c.Labels[0].(*RecordPattern)
+			return record && c.Guard != nil
+		})},
+		{"文本块", "class A {\n    String s = \"\"\"\n        {\"a\": 1}\n        \"\"\";\n}", has(func(n Node) bool {
+			l, ok := n.(*Literal)
+			return ok && l.Kind == TextBlock
+		})},
+		{"lambda和方法引用", `class A {
+    void f(List<String> list) {
+        list.stream().map(String::trim).filter(s -> !s.isEmpty()).forEach((var s) -> System.out.println(s));
+        Supplier<List<String>> make = ArrayList::new;
+    }
+}`, func(f *File) bool {
+			lambdas, refs := 0, 0
+			Inspect(f, func(n Node) bool {
+				switch n.(type) {
+				case *LambdaExpr:
+					lambdas++
+				case *MethodRef:
+					refs++
+				}
+				return true
+			})
+			return lambdas == 2 && refs == 2
+		}},
+		{"var和泛型", `class A<T extends Comparable<? super T>> {
+    <K, V extends List<? extends K>> Map<K, V> f() {
+        var map = new HashMap<K, V>();
+        for (var e : map.entrySet()) {}
+        return map;
+    }
+}`, func(f *File) bool {
+			return len(f.Types[0].TypeParams) == 1 && len(Methods(f)[0].TypeParams) == 2
+		}},
+		{"try-with-resources", `class A {
+    void f(InputStream in) throws IOException {
+        try (in; var out = new FileOutputStream("x")) {
+            out.write(in.read());
+        } catch (IOException | RuntimeException e) {
+            throw e;
+        }
+    }
+}`, has(func(n Node) bool {
+			s, ok := n.(*TryStmt)
+			return ok && len(s.Resources) == 2 && len(s.Catches) == 1 && len(s.Catches[0].Types) == 2
+		})},
+		{"注解和枚举", `@SuppressWarnings({"unchecked", "rawtypes"})
+public enum Color {
+    RED("r") { @Override String code() { return "R"; } },
+    GREEN("g");
+    private final String c;
+    Color(String c) { this.c = c; }
+    String code() { return c; }
+}`, func(f *File) bool {
+			e := f.Types[0]
+			return e.Kind == EnumKind && len(e.Constants) == 2 && e.Constants[0].Body != nil && len(e.Annotations) == 1
+		}},
+		{"注解类型", `@interface Config {
+    String value() default "";
+    int[] ports() default {80, 443};
+}`, func(f *File) bool {
+			return f.Types[0].Kind == AnnotationKind && len(Methods(f)) == 2
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := Parse(tt.src)
+			if len(file.Errors) > 0 {
+				t.Fatalf("语法错误: %v", file.Errors)
+			}
+			if !tt.check(file) {
+				t.Error("语法树与预期不符")
+			}
+		})
+	}
+}
+
+func TestParseMalformed(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		// methods 出错后仍应解析出的方法
+		methods []string
+	}{
+		{"语句缺少分号", "class A {\n    void f() {\n        int x = 1\n        x++;\n    }\n    void g() {}\n}", []string{"f", "g"}},
+		{"非法表达式", "class A {\n    void f() {\n        int x = * 2;\n    }\n    void g() {}\n}", []string{"f", "g"}},
+		{"非法成员", "class A {\n    void f() {}\n    123 abc;\n    void g() {}\n}", []string{"f", "g"}},
+		{"文件提前结束", "class A {\n    void f() {\n        if (x) {\n", []string{"f"}},
+		{"try缺少catch", "class A {\n    void f() {\n        try { g(); }\n    }\n    void g() {}\n}", []string{"f", "g"}},
+		{"未闭合的字符串", "class A {\n    void f() {\n        String s = \"abc;\n    }\n    void g() {}\n}", []string{"f", "g"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := Parse(tt.src)
+			if len(file.Errors) == 0 {
+				t.Error("应报告语法错误")
+			}
+			var names []string
+			for _, m := range Methods(file) {
+				names = append(names, m.Name.Name)
+			}
+			if strings.Join(names, ",") != strings.Join(tt.methods, ",") {
+				t.Errorf("methods = %v, want %v", names, tt.methods)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package java
+
+import "strings"
+
+var modifiers = map[string]bool{
+	"public": true, "protected": true, "private": true, "static": true,
+	"final": true, "abstract": true, "synchronized": true, "native": true,
+	"transient": true, "volatile": true, "strictfp": true, "default": true,
+	"sealed": true, "non-sealed": true,
+}
+
+// IsModifier 判断是否为声明修饰符
+func IsModifier(s string) bool {
+	return modifiers[s]
+}
+
+// IsPrimitive 判断类型名是否为基本类型或void
+func IsPrimitive(name string) bool {
+	return primitiveTypes[name]
+}
+
+// DocCoverage 统计public声明（类型、方法、构造器、字段）的数量及其中带Javadoc注释的数量，
+// 接口和注解类型中未声明为private的成员隐式为public
+func DocCoverage(file *File) (public, documented int) {
+	count := func(doc *Token) {
+		public++
+		if doc != nil {
+			documented++
+		}
+	}
+	Inspect(file, func(n Node) bool {
+		t, ok := n.(*TypeDecl)
+		if !ok {
+			return true
+		}
+		if t.Name != nil && isPublic(t.Modifiers, t.Outer) {
+			count(t.Doc)
+		}
+		for _, member := range t.Members {
+			switch d := member.(type) {
+			case *MethodDecl:
+				if isPublic(d.Modifiers, t) {
+					count(d.Doc)
+				}
+			case *FieldDecl:
+				if isPublic(d.Modifiers, t) {
+					count(d.Doc)
+				}
+			}
+		}
+		return true
+	})
+	return public, documented
+}
+
+// isPublic 判断owner中声明的成员是否为public，顶层类型的owner为nil
+func isPublic(mods Modifiers, owner *TypeDecl) bool {
+	if mods.Has("public") {
+		return true
+	}
+	return owner != nil && (owner.Kind == InterfaceKind || owner.Kind == AnnotationKind) && !mods.Has("private")
+}
+
+// LineSpans 返回词法单元覆盖的行区间，用于区分代码行和注释行
+func LineSpans(tokens []Token) (code, comment [][2]int) {
+	for _, tok := range tokens {
+		span := [2]int{tok.Pos.Line, tok.Pos.Line + strings.Count(tok.Text, "\n")}
+		if tok.Kind.IsComment() {
+			comment = append(comment, span)
+		} else {
+			code = append(code, span)
+		}
+	}
+	return code, comment
+}
@@ -0,0 +1,52 @@
+package java
+
+import "testing"
+
+func TestDocCoverage(t *testing.T) {
+	tests := []struct {
+		name               string
+		src                string
+		public, documented int
+	}{
+		{"类的public成员", `
+/** 类 */
+public class A {
+    /** 字段 */
+    public int x;
+    private int y;
+    void f() {}
+    public void g() {}
+}`, 3, 2},
+		{"接口成员隐式为public", `
+public interface Service {
+    int LIMIT = 10;
+    /** 方法 */
+    void run();
+    default void stop() {}
+    private void helper() {}
+    static Service create() { return null; }
+}`, 5, 1},
+		{"注解类型成员隐式为public", `
+/** 注解 */
+@interface Marker {
+    /** 值 */
+    String value();
+    int order() default 0;
+}`, 2, 1},
+		{"嵌套在类中的接口", `
+class Outer {
+    interface Callback {
+        void done();
+    }
+    void f() {}
+}`, 1, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			public, documented := DocCoverage(Parse(tt.src))
+			if public != tt.public || documented != tt.documented {
+				t.Errorf("DocCoverage() = %d, %d, want %d, %d", public, documented, tt.public, tt.documented)
+			}
+		})
+	}
+}
@@ -0,0 +1,333 @@
+package java
+
+// Visitor 遍历AST时对每个节点调用Visit，返回nil时不再遍历该节点的子节点，
+// 否则用返回的Visitor遍历子节点，子节点遍历完后调用Visit(nil)
+type Visitor interface {
+	Visit(node Node) Visitor
+}
+
+// Walk 按源码顺序深度优先遍历AST
+func Walk(v Visitor, node Node) {
+	if v = v.Visit(node); v == nil {
+		return
+	}
+
+	switch n := node.(type) {
+	case *File:
+		for _, imp := range n.Imports {
+			Walk(v, imp)
+		}
+		for _, t := range n.Types {
+			Walk(v, t)
+		}
+	case *Annotation:
+		walkExprs(v, n.Args)
+	case *TypeDecl:
+		walkAnnotations(v, n.Annotations)
+		for _, tp := range n.TypeParams {
+			Walk(v, tp)
+		}
+		walkTypes(v, n.Extends)
+		walkTypes(v, n.Implements)
+		walkTypes(v, n.Permits)
+		for _, c := range n.Components {
+			Walk(v, c)
+		}
+		for _, c := range n.Constants {
+			Walk(v, c)
+		}
+		for _, m := range n.Members {
+			Walk(v, m)
+		}
+	case *TypeParam:
+		walkTypes(v, n.Bounds)
+	case *Type:
+		walkAnnotations(v, n.Annotations)
+		walkTypes(v, n.Args)
+		if n.Bound != nil {
+			Walk(v, n.Bound)
+		}
+	case *MethodDecl:
+		walkAnnotations(v, n.Annotations)
+		for _, tp := range n.TypeParams {
+			Walk(v, tp)
+		}
+		if n.Result != nil {
+			Walk(v, n.Result)
+		}
+		Walk(v, n.Name)
+		for _, param := range n.Params {
+			Walk(v, param)
+		}
+		walkTypes(v, n.Throws)
+		if n.Body != nil {
+			Walk(v, n.Body)
+		}
+		if n.Default != nil {
+			Walk(v, n.Default)
+		}
+	case *Param:
+		walkAnnotations(v, n.Annotations)
+		if n.Type != nil {
+			Walk(v, n.Type)
+		}
+		Walk(v, n.Name)
+	case *FieldDecl:
+		walkAnnotations(v, n.Annotations)
+		Walk(v, n.Type)
+		for _, vd := range n.Vars {
+			Walk(v, vd)
+		}
+	case *VarDecl:
+		Walk(v, n.Name)
+		if n.Init != nil {
+			Walk(v, n.Init)
+		}
+	case *Initializer:
+		Walk(v, n.Body)
+	case *EnumConstant:
+		walkAnnotations(v, n.Annotations)
+		Walk(v, n.Name)
+		walkExprs(v, n.Args)
+		if n.Body != nil {
+			Walk(v, n.Body)
+		}
+
+	// 语句
+	case *Block:
+		walkStmts(v, n.Stmts)
+	case *LocalVarStmt:
+		walkAnnotations(v, n.Annotations)
+		Walk(v, n.Type)
+		for _, vd := range n.Vars {
+			Walk(v, vd)
+		}
+	case *LocalTypeStmt:
+		Walk(v, n.Decl)
+	case *ExprStmt:
+		Walk(v, n.X)
+	case *IfStmt:
+		Walk(v, n.Cond)
+		Walk(v, n.Then)
+		if n.Else != nil {
+			Walk(v, n.Else)
+		}
+	case *ForStmt:
+		walkStmts(v, n.Init)
+		if n.Cond != nil {
+			Walk(v, n.Cond)
+		}
+		walkExprs(v, n.Update)
+		Walk(v, n.Body)
+	case *ForEachStmt:
+		Walk(v, n.Var)
+		Walk(v, n.X)
+		Walk(v, n.Body)
+	case *WhileStmt:
+		Walk(v, n.Cond)
+		Walk(v, n.Body)
+	case *DoStmt:
+		Walk(v, n.Body)
+		Walk(v, n.Cond)
+	case *SwitchStmt:
+		Walk(v, n.Selector)
+		for _, c := range n.Cases {
+			Walk(v, c)
+		}
+	case *SwitchCase:
+		walkExprs(v, n.Labels)
+		if n.Guard != nil {
+			Walk(v, n.Guard)
+		}
+		walkStmts(v, n.Body)
+	case *ReturnStmt:
+		if n.X != nil {
+			Walk(v, n.X)
+		}
+	case *BranchStmt:
+		if n.Label != nil {
+			Walk(v, n.Label)
+		}
+	case *ThrowStmt:
+		Walk(v, n.X)
+	case *YieldStmt:
+		Walk(v, n.X)
+	case *TryStmt:
+		walkStmts(v, n.Resources)
+		Walk(v, n.Body)
+		for _, c := range n.Catches {
+			Walk(v, c)
+		}
+		if n.Finally != nil {
+			Walk(v, n.Finally)
+		}
+	case *CatchClause:
+		walkAnnotations(v, n.Annotations)
+		walkTypes(v, n.Types)
+		Walk(v, n.Name)
+		Walk(v, n.Body)
+	case *SyncStmt:
+		Walk(v, n.Lock)
+		Walk(v, n.Body)
+	case *LabeledStmt:
+		Walk(v, n.Label)
+		Walk(v, n.Stmt)
+	case *AssertStmt:
+		Walk(v, n.Cond)
+		if n.Msg != nil {
+			Walk(v, n.Msg)
+		}
+
+	// 表达式
+	case *ThisExpr:
+		if n.Qualifier != nil {
+			Walk(v, n.Qualifier)
+		}
+	case *SuperExpr:
+		if n.Qualifier != nil {
+			Walk(v, n.Qualifier)
+		}
+	case *SelectorExpr:
+		Walk(v, n.X)
+		Walk(v, n.Name)
+	case *CallExpr:
+		if n.X != nil {
+			Walk(v, n.X)
+		}
+		walkTypes(v, n.TypeArgs)
+		Walk(v, n.Name)
+		walkExprs(v, n.Args)
+	case *NewExpr:
+		if n.Outer != nil {
+			Walk(v, n.Outer)
+		}
+		walkTypes(v, n.TypeArgs)
+		Walk(v, n.Type)
+		walkExprs(v, n.Args)
+		if n.Body != nil {
+			Walk(v, n.Body)
+		}
+	case *NewArrayExpr:
+		Walk(v, n.Type)
+		walkExprs(v, n.Dims)
+		if n.Init != nil {
+			Walk(v, n.Init)
+		}
+	case *ArrayInit:
+		walkExprs(v, n.Elems)
+	case *IndexExpr:
+		Walk(v, n.X)
+		Walk(v, n.Index)
+	case *UnaryExpr:
+		Walk(v, n.X)
+	case *BinaryExpr:
+		Walk(v, n.X)
+		Walk(v, n.Y)
+	case *AssignExpr:
+		Walk(v, n.X)
+		Walk(v, n.Y)
+	case *CondExpr:
+		Walk(v, n.Cond)
+		Walk(v, n.Then)
+		Walk(v, n.Else)
+	case *CastExpr:
+		walkTypes(v, n.Types)
+		Walk(v, n.X)
+	case *InstanceOfExpr:
+		Walk(v, n.X)
+		if n.Pattern != nil {
+			Walk(v, n.Pattern)
+		} else {
+			Walk(v, n.Type)
+		}
+	case *LambdaExpr:
+		for _, param := range n.Params {
+			Walk(v, param)
+		}
+		Walk(v, n.Body)
+	case *MethodRef:
+		Walk(v, n.X)
+		walkTypes(v, n.TypeArgs)
+		Walk(v, n.Name)
+	case *SwitchExpr:
+		Walk(v, n.Selector)
+		for _, c := range n.Cases {
+			Walk(v, c)
+		}
+	case *ParenExpr:
+		Walk(v, n.X)
+	case *ClassLit:
+		Walk(v, n.Type)
+	case *TypePattern:
+		walkAnnotations(v, n.Annotations)
+		Walk(v, n.Type)
+		Walk(v, n.Name)
+	case *RecordPattern:
+		Walk(v, n.Type)
+		walkExprs(v, n.Patterns)
+	}
+
+	v.Visit(nil)
+}
+
+func walkAnnotations(v Visitor, list []*Annotation) {
+	for _, a := range list {
+		Walk(v, a)
+	}
+}
+
+func walkTypes(v Visitor, list []*Type) {
+	for _, t := range list {
+		Walk(v, t)
+	}
+}
+
+func walkStmts(v Visitor, list []Stmt) {
+	for _, s := range list {
+		Walk(v, s)
+	}
+}
+
+func walkExprs(v Visitor, list []Expr) {
+	for _, x := range list {
+		Walk(v, x)
+	}
+}
+
+type inspector func(Node) bool
+
+func (f inspector) Visit(node Node) Visitor {
+	if f(node) {
+		return f
+	}
+	return nil
+}
+
+// Inspect 按源码顺序遍历AST，f返回false时不再遍历该节点的子节点，子节点遍历完后调用f(nil)
+func Inspect(node Node, f func(Node) bool) {
+	Walk(inspector(f), node)
+}
+
+// Methods 返回文件中所有的方法和构造器（含内部类、局部类和匿名类中的方法），按源码顺序排列
+func Methods(file *File) []*MethodDecl {
+	var methods []*MethodDecl
+	Inspect(file, func(n Node) bool {
+		if m, ok := n.(*MethodDecl); ok {
+			methods = append(methods, m)
+		}
+		return true
+	})
+	return methods
+}
+
+// TypeDecls 返回文件中所有具名类型声明（含内部类和局部类，不含匿名类），按源码顺序排列
+func TypeDecls(file *File) []*TypeDecl {
+	var decls []*TypeDecl
+	Inspect(file, func(n Node) bool {
+		if t, ok := n.(*TypeDecl); ok && t.Name != nil {
+			decls = append(decls, t)
+		}
+		return true
+	})
+	return decls
+}
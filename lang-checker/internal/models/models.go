@@ -8,14 +8,29 @@ const (
 	Java Language = "Java"
 )
 
+// 问题严重级别
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+	SeverityInfo    = "info"
+)
+
 // Issue 代码问题
 type Issue struct {
-	Line        int    `json:"line"`
-	Column      int    `json:"column"`
-	Message     string `json:"message"`
-	Severity    string `json:"severity"`
-	RuleID      string `json:"ruleId"`
-	Suggestion  string `json:"suggestion,omitempty"`
+	FilePath string `json:"filePath,omitempty"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+	// EndLine/EndColumn 问题范围的结束位置，指向范围后的第一个字符，Column均按字节计
+	EndLine    int    `json:"endLine,omitempty"`
+	EndColumn  int    `json:"endColumn,omitempty"`
+	Message    string `json:"message"`
+	Severity   string `json:"severity"`
+	RuleID     string `json:"ruleId"`
+	Category   string `json:"category,omitempty"`
+	Suggestion string `json:"suggestion,omitempty"`
+	// DocURL 规则对应的规范文档
+	DocURL string `json:"docUrl,omitempty"`
+	// CodeSnippet 问题范围及前后若干行源码，带行号
 	CodeSnippet string `json:"codeSnippet,omitempty"`
 }
 
@@ -31,6 +46,7 @@ type QualityMetrics struct {
 	Language             Language `json:"language"`
 	Issues               []Issue  `json:"issues"`
 	CyclomaticComplexity int      `json:"cyclomaticComplexity"`
+	CognitiveComplexity  int      `json:"cognitiveComplexity"`
 	CommentRatio         float64  `json:"commentRatio"`
 	LongFunctions        int      `json:"longFunctions"`
 	DeepNesting          int      `json:"deepNesting"`
@@ -39,4 +55,219 @@ type QualityMetrics struct {
 	AIIndicators         []string `json:"aiIndicators"`
 	Score                float64  `json:"score"`
 	FunctionCount        int      `json:"functionCount"`
+
+	// 行数统计，含行尾注释的行同时计入代码行和注释行
+	TotalLines   int `json:"totalLines"`
+	CodeLines    int `json:"codeLines"`
+	CommentLines int `json:"commentLines"`
+	BlankLines   int `json:"blankLines"`
+
+	// 文档覆盖率：Go的导出声明 / Java的public声明中带文档注释的比例
+	PublicDecls     int     `json:"publicDecls"`
+	DocumentedDecls int     `json:"documentedDecls"`
+	DocCoverage     float64 `json:"docCoverage"`
+
+	// Halstead 整个文件的Halstead度量
+	Halstead Halstead `json:"halstead"`
+	// MaintainabilityIndex 经典可维护性指数，按文件内函数的平均值计算，>=85易维护，<65难维护
+	MaintainabilityIndex float64 `json:"maintainabilityIndex"`
+
+	// Encoding 源文件的原始编码，UTF-8时为空，分析前已统一转为UTF-8
+	Encoding string `json:"encoding,omitempty"`
+	// Content 解码后的源码，供目录分析的重复代码检测等跨文件步骤复用，不必重新读取文件；生成代码为空
+	Content string `json:"-"`
+	// SyntaxErrors Java源码的语法错误（行:列: 描述），出错的语句或成员已跳过，相关指标可能偏低
+	SyntaxErrors []string `json:"syntaxErrors,omitempty"`
+	// Package Go为文件所在目录，Java为package声明的包名
+	Package string `json:"package,omitempty"`
+	// Test 是否为测试代码，测试代码使用单独的阈值和规则集
+	Test bool `json:"test,omitempty"`
+
+	// Bytecode 是否为编译后的class文件（含JAR包中的），只有方法、类和依赖指标，不检查规则、不做AI检测和评分
+	Bytecode bool `json:"bytecode,omitempty"`
+	// Decompiler 分析JAR包时由哪个反编译器还原出源码，行号和代码片段对应反编译结果而不是原始源码
+	Decompiler string `json:"decompiler,omitempty"`
+
+	// Generated 是否为生成代码，生成代码不检查规则、不做AI检测和评分
+	Generated       bool   `json:"generated,omitempty"`
+	GeneratedReason string `json:"generatedReason,omitempty"`
+
+	// Functions 文件中每个函数/方法的指标
+	Functions []FunctionMetrics `json:"functions,omitempty"`
+	// Classes Java文件中每个具名类型（含内部类和局部类）的CK指标
+	Classes []ClassMetrics `json:"classes,omitempty"`
+}
+
+// Halstead Halstead软件科学度量
+type Halstead struct {
+	// DistinctOperators/DistinctOperands 不同操作符数n1 / 不同操作数数n2
+	DistinctOperators int `json:"distinctOperators"`
+	DistinctOperands  int `json:"distinctOperands"`
+	// TotalOperators/TotalOperands 操作符总数N1 / 操作数总数N2
+	TotalOperators int `json:"totalOperators"`
+	TotalOperands  int `json:"totalOperands"`
+	// Vocabulary 词汇量 n = n1 + n2
+	Vocabulary int `json:"vocabulary"`
+	// Length 长度 N = N1 + N2
+	Length int `json:"length"`
+	// Volume 体积 V = N·log2(n)
+	Volume float64 `json:"volume"`
+	// Difficulty 难度 D = n1/2 · N2/n2
+	Difficulty float64 `json:"difficulty"`
+	// Effort 工作量 E = D·V
+	Effort float64 `json:"effort"`
+}
+
+// FunctionMetrics 单个函数或方法的指标
+type FunctionMetrics struct {
+	Name string `json:"name"`
+	// Receiver Go方法的接收者类型或Java方法所在的类名
+	Receiver   string `json:"receiver,omitempty"`
+	StartLine  int    `json:"startLine"`
+	EndLine    int    `json:"endLine"`
+	Lines      int    `json:"lines"`
+	Parameters int    `json:"parameters"`
+	// CyclomaticComplexity 圈复杂度，1 + 分支数
+	CyclomaticComplexity int `json:"cyclomaticComplexity"`
+	// CognitiveComplexity 认知复杂度，嵌套越深的分支权重越高
+	CognitiveComplexity int `json:"cognitiveComplexity"`
+	// MaxNesting 控制结构的最大嵌套深度
+	MaxNesting int `json:"maxNesting"`
+	// Exported Go的导出函数/方法或Java的public方法
+	Exported bool `json:"exported,omitempty"`
+	// CommentLines 函数范围内的注释行数
+	CommentLines         int      `json:"commentLines"`
+	Halstead             Halstead `json:"halstead"`
+	MaintainabilityIndex float64  `json:"maintainabilityIndex"`
+	// BytecodeSize 字节码分析时方法体的字节数，可作为方法规模的近似
+	BytecodeSize int `json:"bytecodeSize,omitempty"`
+}
+
+// ClassMetrics Java类型的CK（Chidamber & Kemerer）指标
+type ClassMetrics struct {
+	// Name 类型名，内部类为 Outer.Inner
+	Name string `json:"name"`
+	// Kind class、interface、enum、record或@interface
+	Kind      string `json:"kind"`
+	StartLine int    `json:"startLine"`
+	EndLine   int    `json:"endLine"`
+	// Methods 方法和构造器个数，Fields 字段个数（record含组件）
+	Methods int `json:"methods"`
+	Fields  int `json:"fields"`
+	// Superclass 类extends的父类，按导入和包名补全为全限定名
+	Superclass string `json:"superclass,omitempty"`
+	// Interfaces 类implements或接口extends的接口，按导入和包名补全为全限定名
+	Interfaces []string `json:"interfaces,omitempty"`
+
+	// WMC 方法加权和，各方法圈复杂度之和，抽象方法按1计
+	WMC int `json:"wmc"`
+	// DIT 继承树深度，只统计分析范围内能找到的祖先类
+	DIT int `json:"dit"`
+	// NOC 分析范围内直接继承该类或实现该接口的类型数
+	NOC int `json:"noc"`
+	// CBO 对象间耦合度，引用的其他类型数，不含基本类型、java.lang常用类型和类型参数
+	CBO int `json:"cbo"`
+	// Dependencies 引用的其他类型的全限定名，排序
+	Dependencies []string `json:"dependencies,omitempty"`
+	// RFC 响应集大小，自身方法数 + 调用的不同外部方法和构造器数
+	RFC int `json:"rfc"`
+	// LCOM 方法内聚缺乏度（LCOM4），通过共享实例字段或相互调用连通的实例方法组数，
+	// 不含构造器和简单的getter/setter，大于1说明类可以拆分
+	LCOM int `json:"lcom"`
+}
+
+// 诊断类型
+const (
+	DiagnosticTimeout   = "timeout"
+	DiagnosticTypeCheck = "typecheck"
+	DiagnosticGenerated = "generated"
+	DiagnosticBinary    = "binary"
+	DiagnosticTooLarge  = "too-large"
+	DiagnosticSyntax    = "syntax"
+	DiagnosticClassFile = "classfile"
+	DiagnosticDecompile = "decompile"
+)
+
+// Diagnostic 分析过程中的诊断信息，如超时或被跳过的文件
+type Diagnostic struct {
+	FilePath string `json:"filePath"`
+	Kind     string `json:"kind"`
+	Message  string `json:"message"`
+}
+
+// Report 一次分析运行的完整结果
+type Report struct {
+	Files       []*QualityMetrics `json:"files"`
+	Diagnostics []Diagnostic      `json:"diagnostics,omitempty"`
+	// Clones 跨文件的重复代码组
+	Clones []CloneGroup `json:"clones,omitempty"`
+	// Tests 按包统计的测试代码指标，分析范围内没有测试文件时为空
+	Tests []PackageTestMetrics `json:"tests,omitempty"`
+}
+
+// PackageTestMetrics 包的测试代码与生产代码对比
+type PackageTestMetrics struct {
+	Package  string   `json:"package"`
+	Language Language `json:"language"`
+	// ProductionLines/TestLines 生产代码和测试代码的代码行数
+	ProductionLines int `json:"productionLines"`
+	TestLines       int `json:"testLines"`
+	// TestRatio 测试代码行数 / 生产代码行数
+	TestRatio float64 `json:"testRatio"`
+	// PublicAPI 导出函数或public方法的数量
+	PublicAPI int `json:"publicApi"`
+	// Untested 没有被任何测试按名称引用或调用的公开API
+	Untested []UntestedAPI `json:"untested,omitempty"`
+}
+
+// UntestedAPI 未被测试引用的公开函数或方法
+type UntestedAPI struct {
+	FilePath string `json:"filePath"`
+	Line     int    `json:"line"`
+	// Name 函数名，方法为 类型.方法名
+	Name string `json:"name"`
+}
+
+// PackageMetrics Go包的耦合度指标
+type PackageMetrics struct {
+	ImportPath string `json:"importPath"`
+	Dir        string `json:"dir"`
+	// Afferent 传入耦合Ca：依赖本包的模块内包数量
+	Afferent int `json:"afferent"`
+	// Efferent 传出耦合Ce：本包依赖的模块内包数量
+	Efferent int `json:"efferent"`
+	// Instability 不稳定性 I = Ce / (Ca + Ce)
+	Instability float64 `json:"instability"`
+	// Abstractness 抽象度 A = 接口类型数 / 命名类型总数
+	Abstractness float64 `json:"abstractness"`
+	// Distance 与主序列的距离 D = |A + I - 1|
+	Distance      float64  `json:"distance"`
+	AbstractTypes int      `json:"abstractTypes"`
+	TotalTypes    int      `json:"totalTypes"`
+	Dependencies  []string `json:"dependencies,omitempty"`
+	Dependents    []string `json:"dependents,omitempty"`
+}
+
+// DependencyReport Go模块的包依赖报告
+type DependencyReport struct {
+	Module   string           `json:"module"`
+	Packages []PackageMetrics `json:"packages"`
+	// Cycles 导入环，每个元素为环中的包（排序后）
+	Cycles [][]string `json:"cycles,omitempty"`
+}
+
+// CloneLocation 重复代码片段的位置
+type CloneLocation struct {
+	FilePath  string `json:"filePath"`
+	StartLine int    `json:"startLine"`
+	EndLine   int    `json:"endLine"`
+}
+
+// CloneGroup 一组互为重复的代码片段
+type CloneGroup struct {
+	// Tokens 片段包含的词法单元数
+	Tokens int `json:"tokens"`
+	// Lines 片段行数（按第一处位置计算）
+	Lines     int             `json:"lines"`
+	Locations []CloneLocation `json:"locations"`
 }
@@ -1,33 +1,34 @@
 package rules
 
 import (
+	"context"
+	"fmt"
 	"go/ast"
-	"go/token"
 
+	"github.com/liujinliang/lang-checker/internal/complexity"
 	"github.com/liujinliang/lang-checker/internal/models"
 )
 
-// GoRule Go语言规则接口
-type GoRule interface {
-	Check(node ast.Node, fset *token.FileSet) []models.Issue
-	Name() string
-}
-
 // FunctionLengthRule 函数长度规则
-type FunctionLengthRule struct{}
+type FunctionLengthRule struct {
+	MaxLines int
+}
 
-func (r *FunctionLengthRule) Check(node ast.Node, fset *token.FileSet) []models.Issue {
+func (r *FunctionLengthRule) Check(ctx context.Context, file *SourceFile) []models.Issue {
 	var issues []models.Issue
-	ast.Inspect(node, func(n ast.Node) bool {
+	fset := file.Fset
+	maxLines := orDefault(r.MaxLines, DefaultThresholds().FunctionLength)
+	ast.Inspect(file.AST, func(n ast.Node) bool {
+		if ctx.Err() != nil {
+			return false
+		}
 		if fn, ok := n.(*ast.FuncDecl); ok {
 			start := fset.Position(fn.Pos())
 			end := fset.Position(fn.End())
-			if end.Line-start.Line > 50 {
-				issues = append(issues, models.Issue{
-					Line:     start.Line,
-					Message:  "函数过长，建议拆分",
-					Severity: "warning",
-				})
+			if end.Line-start.Line > maxLines {
+				issue := goIssueAt(fset, fn.Pos(), fn.Type.End())
+				issue.Message = "函数过长，建议拆分"
+				issues = append(issues, issue)
 			}
 		}
 		return true
@@ -35,25 +36,37 @@ func (r *FunctionLengthRule) Check(node ast.Node, fset *token.FileSet) []models.
 	return issues
 }
 
-func (r *FunctionLengthRule) Name() string {
-	return "FunctionLength"
+func (r *FunctionLengthRule) Meta() Metadata {
+	return Metadata{
+		ID:          "go/function-length",
+		Name:        "FunctionLength",
+		Category:    CategorySize,
+		Severity:    models.SeverityWarning,
+		Languages:   []models.Language{models.Go},
+		Tags:        []string{"maintainability"},
+		Description: "函数体超过阈值行数（默认50行）时报告，过长的函数难以理解和测试",
+	}
 }
 
 // CyclomaticComplexityRule 圈复杂度规则
-type CyclomaticComplexityRule struct{}
+type CyclomaticComplexityRule struct {
+	MaxComplexity int
+}
 
-func (r *CyclomaticComplexityRule) Check(node ast.Node, fset *token.FileSet) []models.Issue {
+func (r *CyclomaticComplexityRule) Check(ctx context.Context, file *SourceFile) []models.Issue {
 	var issues []models.Issue
-	ast.Inspect(node, func(n ast.Node) bool {
+	fset := file.Fset
+	maxComplexity := orDefault(r.MaxComplexity, DefaultThresholds().CyclomaticComplexity)
+	ast.Inspect(file.AST, func(n ast.Node) bool {
+		if ctx.Err() != nil {
+			return false
+		}
 		if fn, ok := n.(*ast.FuncDecl); ok {
 			complexity := calculateComplexity(fn)
-			if complexity > 10 {
-				start := fset.Position(fn.Pos())
-				issues = append(issues, models.Issue{
-					Line:     start.Line,
-					Message:  "函数圈复杂度过高，建议重构",
-					Severity: "warning",
-				})
+			if complexity > maxComplexity {
+				issue := goIssueAt(fset, fn.Pos(), fn.Type.End())
+				issue.Message = "函数圈复杂度过高，建议重构"
+				issues = append(issues, issue)
 			}
 		}
 		return true
@@ -61,25 +74,72 @@ func (r *CyclomaticComplexityRule) Check(node ast.Node, fset *token.FileSet) []m
 	return issues
 }
 
-func (r *CyclomaticComplexityRule) Name() string {
-	return "CyclomaticComplexity"
+func (r *CyclomaticComplexityRule) Meta() Metadata {
+	return Metadata{
+		ID:          "go/cyclomatic-complexity",
+		Name:        "CyclomaticComplexity",
+		Category:    CategoryComplexity,
+		Severity:    models.SeverityWarning,
+		Languages:   []models.Language{models.Go},
+		Tags:        []string{"maintainability"},
+		Description: "函数圈复杂度超过阈值（默认10）时报告",
+	}
+}
+
+// CognitiveComplexityRule 认知复杂度规则
+type CognitiveComplexityRule struct {
+	MaxComplexity int
+}
+
+func (r *CognitiveComplexityRule) Check(ctx context.Context, file *SourceFile) []models.Issue {
+	var issues []models.Issue
+	maxComplexity := orDefault(r.MaxComplexity, DefaultThresholds().CognitiveComplexity)
+	for _, decl := range file.AST.Decls {
+		if ctx.Err() != nil {
+			return issues
+		}
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Body == nil {
+			continue
+		}
+		if c := complexity.GoCognitiveComplexity(fn.Body); c > maxComplexity {
+			issue := goIssueAt(file.Fset, fn.Pos(), fn.Type.End())
+			issue.Message = fmt.Sprintf("函数%s的认知复杂度为%d，超过阈值%d", fn.Name.Name, c, maxComplexity)
+			issue.Suggestion = "减少嵌套层级，提前返回或将分支提取为独立函数"
+			issues = append(issues, issue)
+		}
+	}
+	return issues
+}
+
+func (r *CognitiveComplexityRule) Meta() Metadata {
+	return Metadata{
+		ID:          "go/cognitive-complexity",
+		Name:        "CognitiveComplexity",
+		Category:    CategoryComplexity,
+		Severity:    models.SeverityWarning,
+		Languages:   []models.Language{models.Go},
+		Tags:        []string{"maintainability"},
+		Description: "函数认知复杂度超过阈值（默认15）时报告，嵌套的分支和混合的逻辑运算符会增加阅读难度",
+	}
 }
 
 // NamingConventionRule 命名规范规则
 type NamingConventionRule struct{}
 
-func (r *NamingConventionRule) Check(node ast.Node, fset *token.FileSet) []models.Issue {
+func (r *NamingConventionRule) Check(ctx context.Context, file *SourceFile) []models.Issue {
 	var issues []models.Issue
-	ast.Inspect(node, func(n ast.Node) bool {
+	fset := file.Fset
+	ast.Inspect(file.AST, func(n ast.Node) bool {
+		if ctx.Err() != nil {
+			return false
+		}
 		switch x := n.(type) {
 		case *ast.FuncDecl:
 			if !checkFuncName(x.Name.Name) {
-				pos := fset.Position(x.Pos())
-				issues = append(issues, models.Issue{
-					Line:     pos.Line,
-					Message:  "函数命名不符合规范",
-					Severity: "warning",
-				})
+				issue := goIssueAt(fset, x.Name.Pos(), x.Name.End())
+				issue.Message = "函数命名不符合规范"
+				issues = append(issues, issue)
 			}
 		}
 		return true
@@ -87,8 +147,16 @@ func (r *NamingConventionRule) Check(node ast.Node, fset *token.FileSet) []model
 	return issues
 }
 
-func (r *NamingConventionRule) Name() string {
-	return "NamingConvention"
+func (r *NamingConventionRule) Meta() Metadata {
+	return Metadata{
+		ID:          "go/naming-convention",
+		Name:        "NamingConvention",
+		Category:    CategoryNaming,
+		Severity:    models.SeverityWarning,
+		Languages:   []models.Language{models.Go},
+		Tags:        []string{"style"},
+		Description: "检查函数命名是否符合规范",
+	}
 }
 
 // 辅助函数
@@ -104,6 +172,13 @@ func calculateComplexity(fn *ast.FuncDecl) int {
 	return complexity
 }
 
+func orDefault(value, def int) int {
+	if value <= 0 {
+		return def
+	}
+	return value
+}
+
 func checkFuncName(name string) bool {
 	// 检查函数名是否符合驼峰命名规范
 	if len(name) == 0 || !ast.IsExported(name) {
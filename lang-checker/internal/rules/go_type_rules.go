@@ -0,0 +1,99 @@
+package rules
+
+import (
+	"context"
+	"go/ast"
+	"go/types"
+
+	"github.com/liujinliang/lang-checker/internal/models"
+)
+
+// UncheckedErrorRule 忽略错误返回值规则，依赖包模式下的类型信息
+type UncheckedErrorRule struct{}
+
+// 惯例上可以忽略错误的调用
+var ignoredErrorCalls = map[string]bool{
+	"fmt.Print":                      true,
+	"fmt.Printf":                     true,
+	"fmt.Println":                    true,
+	"(*strings.Builder).Write":       true,
+	"(*strings.Builder).WriteString": true,
+	"(*strings.Builder).WriteByte":   true,
+	"(*strings.Builder).WriteRune":   true,
+	"(*bytes.Buffer).Write":          true,
+	"(*bytes.Buffer).WriteString":    true,
+	"(*bytes.Buffer).WriteByte":      true,
+	"(*bytes.Buffer).WriteRune":      true,
+}
+
+func (r *UncheckedErrorRule) Check(ctx context.Context, file *SourceFile) []models.Issue {
+	if file.TypesInfo == nil {
+		return nil
+	}
+
+	var issues []models.Issue
+	errorType := types.Universe.Lookup("error").Type()
+	ast.Inspect(file.AST, func(n ast.Node) bool {
+		if ctx.Err() != nil {
+			return false
+		}
+		stmt, ok := n.(*ast.ExprStmt)
+		if !ok {
+			return true
+		}
+		call, ok := stmt.X.(*ast.CallExpr)
+		if !ok || !returnsError(file.TypesInfo, call, errorType) || ignoredErrorCalls[calleeName(file.TypesInfo, call)] {
+			return true
+		}
+
+		issue := goIssueAt(file.Fset, call.Pos(), call.End())
+		issue.Message = "调用返回的error未被处理"
+		issue.Suggestion = "检查错误，或显式赋值给 _ 并说明原因"
+		issues = append(issues, issue)
+		return true
+	})
+	return issues
+}
+
+func (r *UncheckedErrorRule) Meta() Metadata {
+	return Metadata{
+		ID:          "go/unchecked-error",
+		Name:        "UncheckedError",
+		Category:    CategoryErrorHandling,
+		Severity:    models.SeverityWarning,
+		Languages:   []models.Language{models.Go},
+		Tags:        []string{"reliability", "types"},
+		Description: "调用结果包含error但作为语句直接丢弃，仅在包模式（类型检查）下生效",
+	}
+}
+
+// returnsError 判断调用的最后一个返回值是否为error
+func returnsError(info *types.Info, call *ast.CallExpr, errorType types.Type) bool {
+	tv, ok := info.Types[call]
+	if !ok || tv.Type == nil {
+		return false
+	}
+	switch t := tv.Type.(type) {
+	case *types.Tuple:
+		return t.Len() > 0 && types.Identical(t.At(t.Len()-1).Type(), errorType)
+	default:
+		return types.Identical(t, errorType)
+	}
+}
+
+// calleeName 返回被调用函数的全名，如 fmt.Println 或 (*bytes.Buffer).Write
+func calleeName(info *types.Info, call *ast.CallExpr) string {
+	var ident *ast.Ident
+	switch fun := ast.Unparen(call.Fun).(type) {
+	case *ast.Ident:
+		ident = fun
+	case *ast.SelectorExpr:
+		ident = fun.Sel
+	default:
+		return ""
+	}
+	if fn, ok := info.Uses[ident].(*types.Func); ok {
+		return fn.FullName()
+	}
+	return ""
+}
@@ -0,0 +1,101 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/liujinliang/lang-checker/internal/complexity"
+	"github.com/liujinliang/lang-checker/internal/java"
+	"github.com/liujinliang/lang-checker/internal/models"
+)
+
+// JavaGodClassRule 上帝类规则：类的方法加权和（WMC）与耦合度（CBO）同时超过阈值
+type JavaGodClassRule struct {
+	MaxWMC int
+	MaxCBO int
+}
+
+func (r *JavaGodClassRule) Check(ctx context.Context, file *SourceFile) []models.Issue {
+	var issues []models.Issue
+	maxWMC := orDefault(r.MaxWMC, DefaultThresholds().GodClassWMC)
+	maxCBO := orDefault(r.MaxCBO, DefaultThresholds().GodClassCBO)
+	ast := javaFile(file)
+	decls := java.TypeDecls(ast)
+	classes := javaClassMetrics(file, ast, decls)
+	for i, decl := range decls {
+		if ctx.Err() != nil {
+			return issues
+		}
+		cm := classes[i]
+		if cm.WMC > maxWMC && cm.CBO > maxCBO {
+			issue := javaIssueAt(decl.Name.Pos(), decl.Name.End())
+			issue.Message = fmt.Sprintf("类%s的方法加权和为%d、耦合%d个其他类型，分别超过阈值%d和%d，承担了过多职责",
+				cm.Name, cm.WMC, cm.CBO, maxWMC, maxCBO)
+			issue.Suggestion = "按职责拆分为多个类，把相关的字段和方法一起移出"
+			issues = append(issues, issue)
+		}
+	}
+	return issues
+}
+
+func (r *JavaGodClassRule) Meta() Metadata {
+	return Metadata{
+		ID:          "java/god-class",
+		Name:        "JavaGodClass",
+		Category:    CategoryDesign,
+		Severity:    models.SeverityWarning,
+		Languages:   []models.Language{models.Java},
+		Tags:        []string{"maintainability", "design"},
+		Description: "类的方法圈复杂度之和（WMC，默认47）和引用的其他类型数（CBO，默认14）同时超过阈值时报告，这类类通常集中了过多逻辑和依赖",
+	}
+}
+
+// JavaLowCohesionRule 低内聚类规则：类的LCOM4超过阈值
+type JavaLowCohesionRule struct {
+	MaxLCOM int
+}
+
+func (r *JavaLowCohesionRule) Check(ctx context.Context, file *SourceFile) []models.Issue {
+	var issues []models.Issue
+	maxLCOM := orDefault(r.MaxLCOM, DefaultThresholds().MaxLCOM)
+	ast := javaFile(file)
+	decls := java.TypeDecls(ast)
+	classes := javaClassMetrics(file, ast, decls)
+	for i, decl := range decls {
+		if ctx.Err() != nil {
+			return issues
+		}
+		cm := classes[i]
+		if cm.LCOM > maxLCOM {
+			issue := javaIssueAt(decl.Name.Pos(), decl.Name.End())
+			issue.Message = fmt.Sprintf("类%s的方法分为%d组互不相关的字段和调用（LCOM4），超过阈值%d，内聚度低",
+				cm.Name, cm.LCOM, maxLCOM)
+			issue.Suggestion = "把不共享字段的方法组拆分到各自的类中"
+			issues = append(issues, issue)
+		}
+	}
+	return issues
+}
+
+func (r *JavaLowCohesionRule) Meta() Metadata {
+	return Metadata{
+		ID:          "java/low-cohesion",
+		Name:        "JavaLowCohesion",
+		Category:    CategoryDesign,
+		Severity:    models.SeverityInfo,
+		Languages:   []models.Language{models.Java},
+		Tags:        []string{"maintainability", "design"},
+		Description: "类的实例方法按共享字段和相互调用分成的组数（LCOM4）超过阈值（默认2）时报告，构造器、静态方法和简单的getter/setter不参与计算",
+	}
+}
+
+// javaClassMetrics 返回decls中每个类型声明的CK指标，优先使用分析器已计算的结果，否则计算后缓存到file供其他类级规则复用
+func javaClassMetrics(file *SourceFile, ast *java.File, decls []*java.TypeDecl) []models.ClassMetrics {
+	if len(file.JavaClasses) != len(decls) {
+		file.JavaClasses = make([]models.ClassMetrics, len(decls))
+		for i, decl := range decls {
+			file.JavaClasses[i] = complexity.JavaClass(ast, decl)
+		}
+	}
+	return file.JavaClasses
+}
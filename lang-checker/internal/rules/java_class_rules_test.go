@@ -0,0 +1,31 @@
+package rules
+
+import "testing"
+
+func TestJavaClassRules(t *testing.T) {
+	godClass := `class Hub {
+    private A a; private B b; private C c;
+    int f(int x) { if (x > 0) { return 1; } if (x < 0) { return -1; } return 0; }
+    int g(int x) { while (x > 0) { x--; } return x; }
+}`
+	cohesive := `class Counter {
+    private int count;
+    private int total;
+    void add(int n) { count++; total += n; }
+    double average() { return (double) total / count; }
+}`
+	split := `class Mixed {
+    private int count;
+    private String name;
+    void add() { count++; }
+    int size() { return count * 2; }
+    void rename(String n) { name = n.trim(); }
+    String label() { return name.toUpperCase(); }
+}`
+	runJavaCases(t, []ruleCase{
+		{name: "上帝类", rule: &JavaGodClassRule{MaxWMC: 3, MaxCBO: 2}, src: godClass, want: 1},
+		{name: "耦合未超阈值", rule: &JavaGodClassRule{MaxWMC: 3, MaxCBO: 5}, src: godClass, want: 0},
+		{name: "低内聚", rule: &JavaLowCohesionRule{MaxLCOM: 1}, src: split, want: 1},
+		{name: "方法共享字段", rule: &JavaLowCohesionRule{MaxLCOM: 1}, src: cohesive, want: 0},
+	})
+}
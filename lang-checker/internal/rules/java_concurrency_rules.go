@@ -0,0 +1,615 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/liujinliang/lang-checker/internal/java"
+	"github.com/liujinliang/lang-checker/internal/models"
+)
+
+// 并发检查清单
+const (
+	concurrencyChecklistDoc = "reviews/java-code-review-chapters/4.4-thread-safety-concurrency-check.md"
+	correctnessChecklistDoc = "reviews/java-code-review-chapters/4.7-business-correctness-check.md"
+)
+
+// unsafeFormatTypes 非线程安全的格式化类型
+var unsafeFormatTypes = map[string]bool{
+	"SimpleDateFormat": true,
+	"DateFormat":       true,
+	"DecimalFormat":    true,
+	"NumberFormat":     true,
+}
+
+// unsafeCollectionTypes 非线程安全的集合类型
+var unsafeCollectionTypes = map[string]bool{
+	"HashMap":       true,
+	"LinkedHashMap": true,
+	"TreeMap":       true,
+	"ArrayList":     true,
+	"LinkedList":    true,
+	"HashSet":       true,
+	"LinkedHashSet": true,
+	"TreeSet":       true,
+}
+
+// boxedTypes String和基本类型的包装类，相同值的实例可能被缓存复用
+var boxedTypes = map[string]bool{
+	"String":    true,
+	"Integer":   true,
+	"Long":      true,
+	"Short":     true,
+	"Byte":      true,
+	"Character": true,
+	"Boolean":   true,
+	"Double":    true,
+	"Float":     true,
+}
+
+// concurrentMapTypes 并发Map类型
+var concurrentMapTypes = map[string]bool{
+	"ConcurrentHashMap":     true,
+	"ConcurrentMap":         true,
+	"ConcurrentSkipListMap": true,
+}
+
+// JavaStaticFormatRule 静态的SimpleDateFormat/DecimalFormat字段规则
+type JavaStaticFormatRule struct{}
+
+func (r *JavaStaticFormatRule) Check(ctx context.Context, file *SourceFile) []models.Issue {
+	var issues []models.Issue
+	for _, decl := range java.TypeDecls(javaFile(file)) {
+		if ctx.Err() != nil {
+			return issues
+		}
+		for _, member := range decl.Members {
+			f, ok := member.(*java.FieldDecl)
+			if !ok || !f.Has("static") {
+				continue
+			}
+			for _, d := range f.Vars {
+				v := javaVar{Modifiers: f.Modifiers, Type: f.Type, Decl: d}
+				if typ := v.typeName(); unsafeFormatTypes[typ] {
+					issue := javaIssueAt(d.Pos(), d.End())
+					issue.Message = fmt.Sprintf("静态字段%s的类型%s不是线程安全的，多线程共享时会得到错误的结果或抛出异常（检查清单4.4.3.1）", d.Name.Name, typ)
+					issue.Suggestion = "日期使用不可变的DateTimeFormatter；数字格式化在方法内创建，或用ThreadLocal.withInitial包装"
+					issues = append(issues, issue)
+				}
+			}
+		}
+	}
+	return issues
+}
+
+func (r *JavaStaticFormatRule) Meta() Metadata {
+	return Metadata{
+		ID:          "java/static-format",
+		Name:        "JavaStaticFormat",
+		Category:    CategoryDesign,
+		Severity:    models.SeverityError,
+		Languages:   []models.Language{models.Java},
+		Tags:        []string{"concurrency"},
+		Description: "static字段的类型（或初始值）为SimpleDateFormat、DateFormat、DecimalFormat、NumberFormat时报告",
+		DocURL:      concurrencyChecklistDoc,
+	}
+}
+
+// JavaDoubleCheckedLockingRule 双重检查锁定缺少volatile规则
+type JavaDoubleCheckedLockingRule struct{}
+
+func (r *JavaDoubleCheckedLockingRule) Check(ctx context.Context, file *SourceFile) []models.Issue {
+	var issues []models.Issue
+	for _, m := range java.Methods(javaFile(file)) {
+		if ctx.Err() != nil {
+			return issues
+		}
+		if m.Body == nil || m.Owner == nil {
+			continue
+		}
+		fields := javaFields(file, m.Owner)
+		java.Inspect(m.Body, func(n java.Node) bool {
+			outer, ok := n.(*java.IfStmt)
+			if !ok {
+				return true
+			}
+			name := javaNullCheck(outer.Cond)
+			field, ok := fields[name]
+			if !ok || field.Has("volatile") || !javaDoubleChecked(outer.Then, name) {
+				return true
+			}
+			issue := javaIssueAt(outer.Pos(), outer.Cond.End())
+			issue.Message = fmt.Sprintf("双重检查锁定的字段%s没有声明为volatile，其他线程可能看到未初始化完成的对象（检查清单4.7.3.3）", name)
+			issue.Suggestion = fmt.Sprintf("把%s声明为volatile，或改用静态内部类持有者、枚举单例", name)
+			issues = append(issues, issue)
+			return false
+		})
+	}
+	return issues
+}
+
+func (r *JavaDoubleCheckedLockingRule) Meta() Metadata {
+	return Metadata{
+		ID:          "java/double-checked-locking",
+		Name:        "JavaDoubleCheckedLocking",
+		Category:    CategoryDesign,
+		Severity:    models.SeverityError,
+		Languages:   []models.Language{models.Java},
+		Tags:        []string{"concurrency"},
+		Description: "if (x == null) { synchronized (...) { if (x == null) ... } } 中的x为非volatile字段时报告",
+		DocURL:      correctnessChecklistDoc,
+	}
+}
+
+// JavaStaticCollectionMutationRule 实例方法修改静态的非线程安全集合规则
+type JavaStaticCollectionMutationRule struct{}
+
+func (r *JavaStaticCollectionMutationRule) Check(ctx context.Context, file *SourceFile) []models.Issue {
+	var issues []models.Issue
+	for _, decl := range java.TypeDecls(javaFile(file)) {
+		if ctx.Err() != nil {
+			return issues
+		}
+		fields := javaFields(file, decl)
+		reported := make(map[string]bool)
+		for _, m := range javaOwnMethods(decl) {
+			if m.Body == nil || m.Has("static") {
+				continue
+			}
+			locals := javaLocals(m)
+			javaInspectUnsynchronized(m.Body, func(call *java.CallExpr) {
+				name := javaFieldTarget(call.X, locals)
+				field, ok := fields[name]
+				if !ok || reported[name] || !field.Has("static") || !unsafeCollectionTypes[field.typeName()] || !isMutatingCall(call.Name.Name) {
+					return
+				}
+				reported[name] = true
+				issue := javaIssueAt(call.Pos(), call.End())
+				issue.Message = fmt.Sprintf("实例方法%s修改了静态字段%s（%s），该集合不是线程安全的，所有实例和线程共享同一份数据（检查清单4.4.3.1）",
+					m.Name.Name, name, field.typeName())
+				issue.Suggestion = "改用ConcurrentHashMap、CopyOnWriteArrayList等并发集合，或在同一把锁下访问"
+				issues = append(issues, issue)
+			})
+		}
+	}
+	return issues
+}
+
+func (r *JavaStaticCollectionMutationRule) Meta() Metadata {
+	return Metadata{
+		ID:          "java/static-collection-mutation",
+		Name:        "JavaStaticCollectionMutation",
+		Category:    CategoryDesign,
+		Severity:    models.SeverityWarning,
+		Languages:   []models.Language{models.Java},
+		Tags:        []string{"concurrency"},
+		Description: "HashMap、ArrayList等非线程安全集合的static字段在实例方法（含构造器）中被put/add/remove等修改、且不在synchronized块内时报告，每个字段报告一次",
+		DocURL:      concurrencyChecklistDoc,
+	}
+}
+
+// JavaSyncOnBoxedRule 对字符串字面量或包装类型加锁规则
+type JavaSyncOnBoxedRule struct{}
+
+func (r *JavaSyncOnBoxedRule) Check(ctx context.Context, file *SourceFile) []models.Issue {
+	var issues []models.Issue
+	for _, m := range java.Methods(javaFile(file)) {
+		if ctx.Err() != nil {
+			return issues
+		}
+		if m.Body == nil || m.Owner == nil {
+			continue
+		}
+		fields := javaFields(file, m.Owner)
+		locals := javaLocals(m)
+		java.Inspect(m.Body, func(n java.Node) bool {
+			sync, ok := n.(*java.SyncStmt)
+			if !ok {
+				return true
+			}
+			if what := javaBoxedLock(sync.Lock, fields, locals); what != "" {
+				issue := javaIssueAt(sync.Lock.Pos(), sync.Lock.End())
+				issue.Message = fmt.Sprintf("对%s加锁，相同值的实例会被常量池或缓存复用，无关的代码可能持有同一把锁（检查清单4.4.3.1）", what)
+				issue.Suggestion = "使用专门的锁对象，如 private final Object lock = new Object()"
+				issues = append(issues, issue)
+			}
+			return true
+		})
+	}
+	return issues
+}
+
+func (r *JavaSyncOnBoxedRule) Meta() Metadata {
+	return Metadata{
+		ID:          "java/sync-on-boxed",
+		Name:        "JavaSyncOnBoxed",
+		Category:    CategoryDesign,
+		Severity:    models.SeverityError,
+		Languages:   []models.Language{models.Java},
+		Tags:        []string{"concurrency"},
+		Description: "synchronized的锁对象为字符串字面量、intern()或valueOf()的结果、Boolean.TRUE/FALSE，或类型为String和包装类的变量时报告",
+		DocURL:      concurrencyChecklistDoc,
+	}
+}
+
+// JavaMutableBeanFieldRule Spring单例Bean中的可变实例字段规则
+type JavaMutableBeanFieldRule struct{}
+
+func (r *JavaMutableBeanFieldRule) Check(ctx context.Context, file *SourceFile) []models.Issue {
+	var issues []models.Issue
+	for _, decl := range java.TypeDecls(javaFile(file)) {
+		if ctx.Err() != nil {
+			return issues
+		}
+		if !isJavaSingletonBean(decl) {
+			continue
+		}
+		fields := javaFields(file, decl)
+		reported := make(map[string]bool)
+		report := func(name, message string) {
+			if reported[name] {
+				return
+			}
+			reported[name] = true
+			issue := javaIssueAt(fields[name].Decl.Pos(), fields[name].Decl.End())
+			issue.Message = message + "（检查清单4.4.2.1）"
+			issue.Suggestion = "单例Bean应保持无状态：把请求相关的数据放在局部变量或参数中，共享计数和缓存使用Atomic类或并发集合"
+			issues = append(issues, issue)
+		}
+		for _, m := range javaOwnMethods(decl) {
+			if m.Body == nil || m.Constructor || m.Has("static") || isJavaLifecycleMethod(m) {
+				continue
+			}
+			locals := javaLocals(m)
+			java.Inspect(m.Body, func(n java.Node) bool {
+				var target java.Expr
+				switch n := n.(type) {
+				case *java.AssignExpr:
+					target = n.X
+				case *java.UnaryExpr:
+					if n.Op == "++" || n.Op == "--" {
+						target = n.X
+					}
+				case *java.CallExpr:
+					name := javaFieldTarget(n.X, locals)
+					if f, ok := fields[name]; ok && !f.Has("static") && unsafeCollectionTypes[f.typeName()] && isMutatingCall(n.Name.Name) {
+						report(name, fmt.Sprintf("单例Bean %s的字段%s是非线程安全的%s，在方法%s中被修改", decl.TypeName(), name, f.typeName(), m.Name.Name))
+					}
+				}
+				name := javaFieldTarget(target, locals)
+				if f, ok := fields[name]; ok && !f.Has("static") && !f.Has("final") && !f.Has("volatile") && !isJavaInjected(f.Modifiers) {
+					report(name, fmt.Sprintf("单例Bean %s的实例字段%s在方法%s中被修改，并发请求会互相覆盖", decl.TypeName(), name, m.Name.Name))
+				}
+				return true
+			})
+		}
+	}
+	return issues
+}
+
+func (r *JavaMutableBeanFieldRule) Meta() Metadata {
+	return Metadata{
+		ID:          "java/mutable-bean-field",
+		Name:        "JavaMutableBeanField",
+		Category:    CategoryDesign,
+		Severity:    models.SeverityWarning,
+		Languages:   []models.Language{models.Java},
+		Tags:        []string{"concurrency", "spring"},
+		Description: "@Service、@Component、@Controller等单例Bean的实例字段在普通方法中被赋值，或HashMap、ArrayList等非线程安全集合字段被修改时报告；构造器、@PostConstruct、@Autowired方法中的初始化和非单例作用域的Bean除外",
+		DocURL:      concurrencyChecklistDoc,
+	}
+}
+
+// JavaConcurrentCheckThenActRule ConcurrentHashMap先检查后操作规则
+type JavaConcurrentCheckThenActRule struct{}
+
+func (r *JavaConcurrentCheckThenActRule) Check(ctx context.Context, file *SourceFile) []models.Issue {
+	var issues []models.Issue
+	for _, m := range java.Methods(javaFile(file)) {
+		if ctx.Err() != nil {
+			return issues
+		}
+		if m.Body == nil || m.Owner == nil {
+			continue
+		}
+		fields := javaFields(file, m.Owner)
+		locals := javaLocals(m)
+		isConcurrentMap := func(name string) bool {
+			if v, ok := locals[name]; ok {
+				return concurrentMapTypes[v.typeName()]
+			}
+			v, ok := fields[name]
+			return ok && concurrentMapTypes[v.typeName()]
+		}
+		java.Inspect(m.Body, func(n java.Node) bool {
+			stmt, ok := n.(*java.IfStmt)
+			if !ok {
+				return true
+			}
+			name := javaMapCheck(stmt.Cond)
+			if name == "" || !isConcurrentMap(name) || !javaMapPuts(stmt, name) {
+				return true
+			}
+			issue := javaIssueAt(stmt.Pos(), stmt.Cond.End())
+			issue.Message = fmt.Sprintf("先检查%s中的键再写入不是原子操作，两次调用之间其他线程可能已写入（检查清单4.4.3.1）", name)
+			issue.Suggestion = "改用putIfAbsent、computeIfAbsent、compute或merge一次完成检查和写入"
+			issues = append(issues, issue)
+			return true
+		})
+	}
+	return issues
+}
+
+func (r *JavaConcurrentCheckThenActRule) Meta() Metadata {
+	return Metadata{
+		ID:          "java/concurrent-check-then-act",
+		Name:        "JavaConcurrentCheckThenAct",
+		Category:    CategoryDesign,
+		Severity:    models.SeverityWarning,
+		Languages:   []models.Language{models.Java},
+		Tags:        []string{"concurrency"},
+		Description: "if条件中对ConcurrentHashMap调用containsKey或判断get结果为null、分支中又对同一个Map调用put时报告",
+		DocURL:      concurrencyChecklistDoc,
+	}
+}
+
+// javaVar 字段、参数或局部变量的声明
+type javaVar struct {
+	java.Modifiers
+	Type *java.Type
+	Decl *java.VarDecl
+}
+
+// typeName 返回变量类型的简单名，声明为接口或var时取new表达式的类型
+func (v javaVar) typeName() string {
+	if v.Decl != nil {
+		if x, ok := v.Decl.Init.(*java.NewExpr); ok && x.Body == nil {
+			return javaSimpleName(x.Type.Name)
+		}
+	}
+	if v.Type == nil {
+		return ""
+	}
+	return javaSimpleName(v.Type.Name)
+}
+
+// javaFields 返回类型中直接声明的字段，按类型声明缓存到file供其他方法和规则复用，调用方不应修改结果
+func javaFields(file *SourceFile, decl *java.TypeDecl) map[string]javaVar {
+	if fields, ok := file.javaFields[decl]; ok {
+		return fields
+	}
+	fields := make(map[string]javaVar)
+	for _, member := range decl.Members {
+		if f, ok := member.(*java.FieldDecl); ok {
+			for _, v := range f.Vars {
+				fields[v.Name.Name] = javaVar{Modifiers: f.Modifiers, Type: f.Type, Decl: v}
+			}
+		}
+	}
+	if file.javaFields == nil {
+		file.javaFields = make(map[*java.TypeDecl]map[string]javaVar)
+	}
+	file.javaFields[decl] = fields
+	return fields
+}
+
+// javaLocals 返回方法的参数和局部变量，同名变量取第一个声明
+func javaLocals(m *java.MethodDecl) map[string]javaVar {
+	locals := make(map[string]javaVar)
+	for _, p := range m.Params {
+		locals[p.Name.Name] = javaVar{Modifiers: p.Modifiers, Type: p.Type}
+	}
+	if m.Body == nil {
+		return locals
+	}
+	java.Inspect(m.Body, func(n java.Node) bool {
+		if s, ok := n.(*java.LocalVarStmt); ok {
+			for _, v := range s.Vars {
+				if _, dup := locals[v.Name.Name]; !dup {
+					locals[v.Name.Name] = javaVar{Modifiers: s.Modifiers, Type: s.Type, Decl: v}
+				}
+			}
+		}
+		return true
+	})
+	return locals
+}
+
+// javaFieldTarget 返回表达式引用的字段名，与参数或此前声明的局部变量同名的简单名不视为字段
+func javaFieldTarget(x java.Expr, locals map[string]javaVar) string {
+	if id, ok := x.(*java.Identifier); ok {
+		if v, local := locals[id.Name]; local && (v.Decl == nil || v.Decl.Pos().Offset < id.Pos().Offset) {
+			return ""
+		}
+	}
+	return javaFieldRef(x)
+}
+
+// javaNullCheck 返回形如 x == null 的条件中的字段名
+func javaNullCheck(cond java.Expr) string {
+	b, ok := javaUnparen(cond).(*java.BinaryExpr)
+	if !ok || b.Op != "==" {
+		return ""
+	}
+	if isJavaNull(b.Y) {
+		return javaFieldRef(javaUnparen(b.X))
+	}
+	if isJavaNull(b.X) {
+		return javaFieldRef(javaUnparen(b.Y))
+	}
+	return ""
+}
+
+func isJavaNull(x java.Expr) bool {
+	lit, ok := javaUnparen(x).(*java.Literal)
+	return ok && lit.Value == "null"
+}
+
+// javaDoubleChecked 判断then分支中是否有synchronized块再次检查name为null并给它赋值
+func javaDoubleChecked(then java.Stmt, name string) bool {
+	found := false
+	java.Inspect(then, func(n java.Node) bool {
+		sync, ok := n.(*java.SyncStmt)
+		if !ok || found {
+			return !found
+		}
+		java.Inspect(sync.Body, func(n java.Node) bool {
+			if inner, ok := n.(*java.IfStmt); ok && javaNullCheck(inner.Cond) == name {
+				java.Inspect(inner.Then, func(n java.Node) bool {
+					if a, ok := n.(*java.AssignExpr); ok && javaFieldRef(a.X) == name {
+						found = true
+					}
+					return !found
+				})
+			}
+			return !found
+		})
+		return false
+	})
+	return found
+}
+
+// isMutatingCall 判断集合方法是否会修改集合
+func isMutatingCall(name string) bool {
+	switch name {
+	case "put", "putAll", "putIfAbsent", "remove", "clear", "add", "addAll", "set",
+		"compute", "computeIfAbsent", "computeIfPresent", "merge", "replace", "replaceAll",
+		"removeIf", "removeAll", "retainAll", "sort", "push", "pop", "offer", "poll":
+		return true
+	}
+	return false
+}
+
+// javaInspectUnsynchronized 遍历不在synchronized块中的方法调用
+func javaInspectUnsynchronized(body *java.Block, f func(*java.CallExpr)) {
+	java.Inspect(body, func(n java.Node) bool {
+		switch n := n.(type) {
+		case *java.SyncStmt:
+			return false
+		case *java.CallExpr:
+			f(n)
+		}
+		return true
+	})
+}
+
+// javaBoxedLock 判断锁对象是否为字符串或包装类型，返回描述，否则返回空字符串
+func javaBoxedLock(lock java.Expr, fields, locals map[string]javaVar) string {
+	switch x := javaUnparen(lock).(type) {
+	case *java.Literal:
+		if x.Kind == java.StringLiteral || x.Kind == java.TextBlock {
+			return "字符串字面量" + x.Value
+		}
+	case *java.SelectorExpr:
+		if id, ok := x.X.(*java.Identifier); ok && id.Name == "Boolean" && (x.Name.Name == "TRUE" || x.Name.Name == "FALSE") {
+			return "Boolean." + x.Name.Name
+		}
+	case *java.CallExpr:
+		if x.Name.Name == "intern" && len(x.Args) == 0 {
+			return "intern()返回的字符串"
+		}
+		if id, ok := x.X.(*java.Identifier); ok && boxedTypes[id.Name] && x.Name.Name == "valueOf" {
+			return id.Name + ".valueOf()的结果"
+		}
+	}
+	name := javaFieldRef(javaUnparen(lock))
+	v, ok := locals[name]
+	if !ok {
+		v, ok = fields[name]
+	}
+	if ok && v.Type != nil && boxedTypes[javaSimpleName(v.Type.Name)] {
+		return fmt.Sprintf("%s类型的%s", javaSimpleName(v.Type.Name), name)
+	}
+	return ""
+}
+
+// isJavaSingletonBean 判断类是否为单例作用域的Spring组件
+func isJavaSingletonBean(decl *java.TypeDecl) bool {
+	if decl.Kind != java.ClassKind {
+		return false
+	}
+	bean := false
+	for _, name := range []string{"Service", "Component", "Controller", "RestController", "Repository"} {
+		if javaAnnotation(decl.Modifiers, name) != nil {
+			bean = true
+		}
+	}
+	if !bean || javaAnnotation(decl.Modifiers, "ConfigurationProperties") != nil {
+		return false
+	}
+	if scope := javaAnnotation(decl.Modifiers, "Scope"); scope != nil {
+		return len(scope.Args) == 0 || strings.Contains(strings.ToLower(javaAnnotationText(scope)), "singleton")
+	}
+	for _, name := range []string{"RequestScope", "SessionScope", "ApplicationScope"} {
+		if javaAnnotation(decl.Modifiers, name) != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// javaAnnotationText 返回注解参数中的字面量和标识符，用于粗略匹配取值
+func javaAnnotationText(a *java.Annotation) string {
+	var b strings.Builder
+	for _, arg := range a.Args {
+		java.Inspect(arg, func(n java.Node) bool {
+			switch n := n.(type) {
+			case *java.Literal:
+				b.WriteString(n.Value)
+			case *java.Identifier:
+				b.WriteString(n.Name)
+			}
+			b.WriteByte(' ')
+			return true
+		})
+	}
+	return b.String()
+}
+
+// isJavaLifecycleMethod 判断方法是否由容器在初始化时调用（注入或初始化回调）
+func isJavaLifecycleMethod(m *java.MethodDecl) bool {
+	if isJavaInjected(m.Modifiers) {
+		return true
+	}
+	return javaAnnotation(m.Modifiers, "PostConstruct") != nil || m.Name.Name == "afterPropertiesSet" || m.Name.Name == "setApplicationContext"
+}
+
+// isJavaInjected 判断字段或方法是否由容器注入
+func isJavaInjected(mods java.Modifiers) bool {
+	for _, name := range []string{"Autowired", "Resource", "Inject", "Value", "PersistenceContext"} {
+		if javaAnnotation(mods, name) != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// javaMapCheck 返回形如 m.containsKey(k)、!m.containsKey(k)、m.get(k) == null 的条件中的Map变量名
+func javaMapCheck(cond java.Expr) string {
+	name := ""
+	java.Inspect(cond, func(n java.Node) bool {
+		if call, ok := n.(*java.CallExpr); ok && name == "" && (call.Name.Name == "containsKey" || call.Name.Name == "get") {
+			name = javaFieldRef(call.X)
+		}
+		return name == ""
+	})
+	return name
+}
+
+// javaMapPuts 判断if语句的分支中是否对name调用put
+func javaMapPuts(stmt *java.IfStmt, name string) bool {
+	found := false
+	for _, branch := range []java.Stmt{stmt.Then, stmt.Else} {
+		if branch == nil {
+			continue
+		}
+		java.Inspect(branch, func(n java.Node) bool {
+			if call, ok := n.(*java.CallExpr); ok && call.Name.Name == "put" && javaFieldRef(call.X) == name {
+				found = true
+			}
+			return !found
+		})
+	}
+	return found
+}
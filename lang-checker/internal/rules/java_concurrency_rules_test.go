@@ -0,0 +1,95 @@
+package rules
+
+import "testing"
+
+func TestJavaConcurrencyRules(t *testing.T) {
+	runJavaCases(t, []ruleCase{
+		{name: "static的SimpleDateFormat", rule: &JavaStaticFormatRule{}, want: 1, src: `
+class Dates {
+    private static final SimpleDateFormat FORMAT = new SimpleDateFormat("yyyy-MM-dd");
+}`},
+		{name: "static的DateTimeFormatter", rule: &JavaStaticFormatRule{}, want: 0, src: `
+class Dates {
+    private static final DateTimeFormatter FORMAT = DateTimeFormatter.ofPattern("yyyy-MM-dd");
+}`},
+
+		{name: "非volatile的双重检查锁", rule: &JavaDoubleCheckedLockingRule{}, want: 1, src: `
+class Holder {
+    private static Holder instance;
+    static Holder get() {
+        if (instance == null) {
+            synchronized (Holder.class) {
+                if (instance == null) { instance = new Holder(); }
+            }
+        }
+        return instance;
+    }
+}`},
+		{name: "volatile的双重检查锁", rule: &JavaDoubleCheckedLockingRule{}, want: 0, src: `
+class Holder {
+    private static volatile Holder instance;
+    static Holder get() {
+        if (instance == null) {
+            synchronized (Holder.class) {
+                if (instance == null) { instance = new Holder(); }
+            }
+        }
+        return instance;
+    }
+}`},
+
+		{name: "实例方法修改static集合", rule: &JavaStaticCollectionMutationRule{}, want: 1, src: `
+class Registry {
+    private static final Map<String, String> CACHE = new HashMap<>();
+    void put(String k, String v) { CACHE.put(k, v); CACHE.remove("x"); }
+}`},
+		{name: "synchronized中修改static集合", rule: &JavaStaticCollectionMutationRule{}, want: 0, src: `
+class Registry {
+    private static final Map<String, String> CACHE = new HashMap<>();
+    void put(String k, String v) { synchronized (CACHE) { CACHE.put(k, v); } }
+}`},
+
+		{name: "锁对象为包装类", rule: &JavaSyncOnBoxedRule{}, want: 1, src: `
+class Counter {
+    private Integer count = 0;
+    void inc() { synchronized (count) { count++; } }
+}`},
+		{name: "锁对象为专用Object", rule: &JavaSyncOnBoxedRule{}, want: 0, src: `
+class Counter {
+    private final Object lock = new Object();
+    void inc() { synchronized (lock) { count++; } }
+}`},
+
+		{name: "单例Bean的可变字段", rule: &JavaMutableBeanFieldRule{}, want: 1, src: `
+@Service
+class OrderService {
+    private String lastOrder;
+    void submit(String id) { lastOrder = id; }
+}`},
+		{name: "构造器中初始化", rule: &JavaMutableBeanFieldRule{}, want: 0, src: `
+@Service
+class OrderService {
+    private String prefix;
+    OrderService(String prefix) { this.prefix = prefix; }
+    String id(String n) { return prefix + n; }
+}`},
+		{name: "原型作用域的Bean", rule: &JavaMutableBeanFieldRule{}, want: 0, src: `
+@Component
+@Scope("prototype")
+class Builder {
+    private String name;
+    void name(String n) { name = n; }
+}`},
+
+		{name: "ConcurrentHashMap先检查后put", rule: &JavaConcurrentCheckThenActRule{}, want: 1, src: `
+class Cache {
+    private final ConcurrentHashMap<String, String> map = new ConcurrentHashMap<>();
+    void add(String k) { if (!map.containsKey(k)) { map.put(k, load(k)); } }
+}`},
+		{name: "使用putIfAbsent", rule: &JavaConcurrentCheckThenActRule{}, want: 0, src: `
+class Cache {
+    private final ConcurrentHashMap<String, String> map = new ConcurrentHashMap<>();
+    void add(String k) { map.putIfAbsent(k, load(k)); }
+}`},
+	})
+}
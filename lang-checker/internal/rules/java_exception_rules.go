@@ -0,0 +1,397 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/liujinliang/lang-checker/internal/java"
+	"github.com/liujinliang/lang-checker/internal/models"
+)
+
+// exceptionChecklistDoc 异常处理检查清单
+const exceptionChecklistDoc = "reviews/java-code-review-chapters/4.9-exception-handling-logging-check.md"
+
+// JavaEmptyCatchRule 空catch块规则
+type JavaEmptyCatchRule struct{}
+
+func (r *JavaEmptyCatchRule) Check(ctx context.Context, file *SourceFile) []models.Issue {
+	var issues []models.Issue
+	for _, c := range javaCatches(ctx, javaFile(file)) {
+		if len(c.Body.Stmts) > 0 || isIgnoredCatchName(c.Name.Name) {
+			continue
+		}
+		// 块内有注释说明忽略原因的不报告
+		inner := file.Content[c.Body.Pos().Offset:c.Body.End().Offset]
+		if strings.Contains(inner, "//") || strings.Contains(inner, "/*") {
+			continue
+		}
+		issue := javaIssueAt(c.Pos(), c.Body.Pos())
+		issue.Message = fmt.Sprintf("捕获%s后没有任何处理，异常被吞掉（检查清单4.9.2.1）", javaCatchTypes(c))
+		issue.Suggestion = "记录日志、转换后重新抛出或执行恢复逻辑；确需忽略时把变量命名为ignored并注释原因"
+		issues = append(issues, issue)
+	}
+	return issues
+}
+
+func (r *JavaEmptyCatchRule) Meta() Metadata {
+	return Metadata{
+		ID:          "java/empty-catch",
+		Name:        "JavaEmptyCatch",
+		Category:    CategoryErrorHandling,
+		Severity:    models.SeverityWarning,
+		Languages:   []models.Language{models.Java},
+		Tags:        []string{"reliability", "exception"},
+		Description: "catch块为空且没有注释时报告，变量名为ignored/ignore/expected的视为有意忽略",
+		DocURL:      exceptionChecklistDoc,
+	}
+}
+
+// JavaBroadCatchRule 捕获Exception或Throwable规则
+type JavaBroadCatchRule struct{}
+
+func (r *JavaBroadCatchRule) Check(ctx context.Context, file *SourceFile) []models.Issue {
+	var issues []models.Issue
+	for _, c := range javaCatches(ctx, javaFile(file)) {
+		// 清理后原样重新抛出的不报告
+		if javaRethrows(c) {
+			continue
+		}
+		for _, t := range c.Types {
+			switch t.Name {
+			case "Exception", "Throwable", "java.lang.Exception", "java.lang.Throwable":
+				issue := javaIssueAt(t.Pos(), t.End())
+				issue.Message = fmt.Sprintf("捕获的%s过于宽泛，会把编程错误和不同的失败原因混在一起处理（检查清单4.9.2.1）", javaSimpleName(t.Name))
+				issue.Suggestion = "捕获能够处理的具体异常类型，其余的交给上层或全局异常处理器"
+				if javaSimpleName(t.Name) == "Throwable" {
+					issue.Suggestion = "不要捕获Throwable，OutOfMemoryError等Error不应由业务代码处理；" + issue.Suggestion
+				}
+				issues = append(issues, issue)
+			}
+		}
+	}
+	return issues
+}
+
+func (r *JavaBroadCatchRule) Meta() Metadata {
+	return Metadata{
+		ID:          "java/broad-catch",
+		Name:        "JavaBroadCatch",
+		Category:    CategoryErrorHandling,
+		Severity:    models.SeverityWarning,
+		Languages:   []models.Language{models.Java},
+		Tags:        []string{"reliability", "exception"},
+		Description: "catch子句捕获Exception或Throwable时报告，catch块中原样重新抛出该异常的除外",
+		DocURL:      exceptionChecklistDoc,
+	}
+}
+
+// JavaPrintStackTraceRule 调用printStackTrace规则
+type JavaPrintStackTraceRule struct{}
+
+func (r *JavaPrintStackTraceRule) Check(ctx context.Context, file *SourceFile) []models.Issue {
+	var issues []models.Issue
+	java.Inspect(javaFile(file), func(n java.Node) bool {
+		if ctx.Err() != nil {
+			return false
+		}
+		if call, ok := n.(*java.CallExpr); ok && call.X != nil && call.Name.Name == "printStackTrace" {
+			issue := javaIssueAt(call.Pos(), call.End())
+			issue.Message = "调用printStackTrace()把堆栈输出到标准错误，不经过日志系统，线上无法检索（检查清单4.9.2.1）"
+			issue.Suggestion = "使用日志框架记录，并把异常作为最后一个参数传入，如 log.error(\"...\", e)"
+			issues = append(issues, issue)
+		}
+		return true
+	})
+	return issues
+}
+
+func (r *JavaPrintStackTraceRule) Meta() Metadata {
+	return Metadata{
+		ID:          "java/print-stack-trace",
+		Name:        "JavaPrintStackTrace",
+		Category:    CategoryErrorHandling,
+		Severity:    models.SeverityWarning,
+		Languages:   []models.Language{models.Java},
+		Tags:        []string{"reliability", "exception", "logging"},
+		Description: "调用异常的printStackTrace()时报告，异常应通过日志框架记录",
+		DocURL:      exceptionChecklistDoc,
+	}
+}
+
+// JavaRethrowWithoutCauseRule 重新抛出异常时丢失原始异常规则
+type JavaRethrowWithoutCauseRule struct{}
+
+func (r *JavaRethrowWithoutCauseRule) Check(ctx context.Context, file *SourceFile) []models.Issue {
+	var issues []models.Issue
+	for _, c := range javaCatches(ctx, javaFile(file)) {
+		name := c.Name.Name
+		javaInspectCatch(c, func(n java.Node) bool {
+			throw, ok := n.(*java.ThrowStmt)
+			if !ok {
+				return true
+			}
+			x, ok := throw.X.(*java.NewExpr)
+			if !ok || x.Body != nil || javaPassesCause(x.Args, name) {
+				return true
+			}
+			issue := javaIssueAt(throw.Pos(), throw.End())
+			issue.Message = fmt.Sprintf("在catch块中抛出新的%s时没有传入原始异常%s，异常链断裂（检查清单4.9.2.2）", x.Type.Name, name)
+			issue.Suggestion = fmt.Sprintf("把%s作为cause传入构造器，如 new %s(\"...\", %s)", name, javaSimpleName(x.Type.Name), name)
+			issues = append(issues, issue)
+			return true
+		})
+	}
+	return issues
+}
+
+func (r *JavaRethrowWithoutCauseRule) Meta() Metadata {
+	return Metadata{
+		ID:          "java/rethrow-without-cause",
+		Name:        "JavaRethrowWithoutCause",
+		Category:    CategoryErrorHandling,
+		Severity:    models.SeverityWarning,
+		Languages:   []models.Language{models.Java},
+		Tags:        []string{"reliability", "exception"},
+		Description: "catch块中throw new的异常构造参数不含捕获的异常变量时报告，只传入e.getMessage()同样会丢失堆栈",
+		DocURL:      exceptionChecklistDoc,
+	}
+}
+
+// JavaSwallowedInterruptRule 吞掉InterruptedException规则
+type JavaSwallowedInterruptRule struct{}
+
+func (r *JavaSwallowedInterruptRule) Check(ctx context.Context, file *SourceFile) []models.Issue {
+	var issues []models.Issue
+	for _, c := range javaCatches(ctx, javaFile(file)) {
+		var interrupted *java.Type
+		for _, t := range c.Types {
+			if javaSimpleName(t.Name) == "InterruptedException" {
+				interrupted = t
+			}
+		}
+		if interrupted == nil || javaRethrows(c) {
+			continue
+		}
+		restored := false
+		javaInspectCatch(c, func(n java.Node) bool {
+			if call, ok := n.(*java.CallExpr); ok && call.Name.Name == "interrupt" && len(call.Args) == 0 {
+				restored = true
+			}
+			return !restored
+		})
+		if restored {
+			continue
+		}
+		issue := javaIssueAt(interrupted.Pos(), interrupted.End())
+		issue.Message = "捕获InterruptedException后既没有恢复中断标志也没有重新抛出，调用方无法感知线程被中断（检查清单4.9.2.1、4.4.1.2）"
+		issue.Suggestion = "在catch块中调用Thread.currentThread().interrupt()，或把InterruptedException声明到throws中"
+		issues = append(issues, issue)
+	}
+	return issues
+}
+
+func (r *JavaSwallowedInterruptRule) Meta() Metadata {
+	return Metadata{
+		ID:          "java/swallowed-interrupt",
+		Name:        "JavaSwallowedInterrupt",
+		Category:    CategoryErrorHandling,
+		Severity:    models.SeverityError,
+		Languages:   []models.Language{models.Java},
+		Tags:        []string{"reliability", "exception", "concurrency"},
+		Description: "catch InterruptedException的块中没有调用interrupt()恢复中断标志、也没有原样重新抛出时报告",
+		DocURL:      exceptionChecklistDoc,
+	}
+}
+
+// JavaThrowInFinallyRule finally块中抛出异常规则
+type JavaThrowInFinallyRule struct{}
+
+func (r *JavaThrowInFinallyRule) Check(ctx context.Context, file *SourceFile) []models.Issue {
+	var issues []models.Issue
+	for _, finally := range javaFinallyBlocks(ctx, javaFile(file)) {
+		javaInspectFinally(finally, true, func(n java.Node) bool {
+			if throw, ok := n.(*java.ThrowStmt); ok {
+				issue := javaIssueAt(throw.Pos(), throw.End())
+				issue.Message = "finally块中抛出异常会掩盖try块中的原始异常（检查清单4.9.2.3）"
+				issue.Suggestion = "在finally中捕获并记录清理失败，或改用try-with-resources，让原始异常继续传播"
+				issues = append(issues, issue)
+			}
+			return true
+		})
+	}
+	return issues
+}
+
+func (r *JavaThrowInFinallyRule) Meta() Metadata {
+	return Metadata{
+		ID:          "java/throw-in-finally",
+		Name:        "JavaThrowInFinally",
+		Category:    CategoryErrorHandling,
+		Severity:    models.SeverityWarning,
+		Languages:   []models.Language{models.Java},
+		Tags:        []string{"reliability", "exception"},
+		Description: "finally块中直接出现throw语句时报告，finally内被try-catch包住的throw除外",
+		DocURL:      exceptionChecklistDoc,
+	}
+}
+
+// JavaReturnInFinallyRule finally块中return规则
+type JavaReturnInFinallyRule struct{}
+
+func (r *JavaReturnInFinallyRule) Check(ctx context.Context, file *SourceFile) []models.Issue {
+	var issues []models.Issue
+	for _, finally := range javaFinallyBlocks(ctx, javaFile(file)) {
+		javaInspectFinally(finally, false, func(n java.Node) bool {
+			if ret, ok := n.(*java.ReturnStmt); ok {
+				issue := javaIssueAt(ret.Pos(), ret.End())
+				issue.Message = "finally块中return会丢弃try/catch中抛出的异常，并覆盖它们的返回值（检查清单4.9.2.3）"
+				issue.Suggestion = "把return移到try块或finally之后，finally只做资源清理"
+				issues = append(issues, issue)
+			}
+			return true
+		})
+	}
+	return issues
+}
+
+func (r *JavaReturnInFinallyRule) Meta() Metadata {
+	return Metadata{
+		ID:          "java/return-in-finally",
+		Name:        "JavaReturnInFinally",
+		Category:    CategoryErrorHandling,
+		Severity:    models.SeverityError,
+		Languages:   []models.Language{models.Java},
+		Tags:        []string{"reliability", "exception"},
+		Description: "finally块中出现return语句时报告，lambda和匿名类中的return除外",
+		DocURL:      exceptionChecklistDoc,
+	}
+}
+
+// javaCatches 返回文件中所有带变量名的catch子句
+func javaCatches(ctx context.Context, file *java.File) []*java.CatchClause {
+	var catches []*java.CatchClause
+	java.Inspect(file, func(n java.Node) bool {
+		if ctx.Err() != nil {
+			return false
+		}
+		if c, ok := n.(*java.CatchClause); ok && c.Name != nil && c.Body != nil {
+			catches = append(catches, c)
+		}
+		return true
+	})
+	return catches
+}
+
+// javaFinallyBlocks 返回文件中所有finally块
+func javaFinallyBlocks(ctx context.Context, file *java.File) []*java.Block {
+	var blocks []*java.Block
+	java.Inspect(file, func(n java.Node) bool {
+		if ctx.Err() != nil {
+			return false
+		}
+		if t, ok := n.(*java.TryStmt); ok && t.Finally != nil {
+			blocks = append(blocks, t.Finally)
+		}
+		return true
+	})
+	return blocks
+}
+
+// javaInspectLocal 遍历语句，不进入lambda和匿名类/局部类，它们的return和throw不属于外层方法
+func javaInspectLocal(node java.Node, f func(java.Node) bool) {
+	java.Inspect(node, func(n java.Node) bool {
+		switch n.(type) {
+		case *java.LambdaExpr, *java.TypeDecl:
+			return false
+		}
+		return f(n)
+	})
+}
+
+// javaInspectCatch 遍历catch块，嵌套的catch子句由各自的检查处理
+func javaInspectCatch(c *java.CatchClause, f func(java.Node) bool) {
+	javaInspectLocal(c.Body, func(n java.Node) bool {
+		if _, ok := n.(*java.CatchClause); ok {
+			return false
+		}
+		return f(n)
+	})
+}
+
+// javaInspectFinally 遍历finally块，内层的finally块单独检查；guarded为true时还跳过带catch的内层try块，
+// 其中的throw可能被就地捕获
+func javaInspectFinally(finally *java.Block, guarded bool, f func(java.Node) bool) {
+	var skip []*java.Block
+	javaInspectLocal(finally, func(n java.Node) bool {
+		for _, b := range skip {
+			if n == java.Node(b) {
+				return false
+			}
+		}
+		if t, ok := n.(*java.TryStmt); ok {
+			if t.Finally != nil {
+				skip = append(skip, t.Finally)
+			}
+			if guarded && len(t.Catches) > 0 {
+				skip = append(skip, t.Body)
+			}
+		}
+		return f(n)
+	})
+}
+
+// javaRethrows 判断catch块中是否原样重新抛出捕获的异常
+func javaRethrows(c *java.CatchClause) bool {
+	rethrows := false
+	javaInspectCatch(c, func(n java.Node) bool {
+		if throw, ok := n.(*java.ThrowStmt); ok {
+			if id, ok := javaUnparen(throw.X).(*java.Identifier); ok && id.Name == c.Name.Name {
+				rethrows = true
+			}
+		}
+		return !rethrows
+	})
+	return rethrows
+}
+
+// javaPassesCause 判断构造参数中是否直接传入了异常变量
+func javaPassesCause(args []java.Expr, name string) bool {
+	for _, arg := range args {
+		if id, ok := javaUnparen(arg).(*java.Identifier); ok && id.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// javaUnparen 去掉括号和类型转换
+func javaUnparen(x java.Expr) java.Expr {
+	for {
+		switch e := x.(type) {
+		case *java.ParenExpr:
+			x = e.X
+		case *java.CastExpr:
+			x = e.X
+		default:
+			return x
+		}
+	}
+}
+
+// javaCatchTypes 返回catch子句捕获的类型，多重捕获以|连接
+func javaCatchTypes(c *java.CatchClause) string {
+	names := make([]string, len(c.Types))
+	for i, t := range c.Types {
+		names[i] = javaSimpleName(t.Name)
+	}
+	return strings.Join(names, "|")
+}
+
+func isIgnoredCatchName(name string) bool {
+	switch name {
+	case "ignored", "ignore", "expected", "_":
+		return true
+	}
+	return false
+}
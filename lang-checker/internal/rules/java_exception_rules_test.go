@@ -0,0 +1,49 @@
+package rules
+
+import "testing"
+
+// inMethod 把语句包进类的方法中
+func inMethod(body string) string {
+	return "class A {\n    void f() throws Exception {\n" + body + "\n    }\n}\n"
+}
+
+func TestJavaExceptionRules(t *testing.T) {
+	runJavaCases(t, []ruleCase{
+		{name: "空catch", rule: &JavaEmptyCatchRule{}, want: 1,
+			src: inMethod(`try { run(); } catch (IOException e) { }`)},
+		{name: "有意忽略", rule: &JavaEmptyCatchRule{}, want: 0,
+			src: inMethod(`try { run(); } catch (IOException ignored) { }`)},
+		{name: "有注释的空catch", rule: &JavaEmptyCatchRule{}, want: 0,
+			src: inMethod(`try { run(); } catch (IOException e) { /* 文件不存在时使用默认配置 */ }`)},
+
+		{name: "捕获Exception", rule: &JavaBroadCatchRule{}, want: 1,
+			src: inMethod(`try { run(); } catch (Exception e) { log.error("failed", e); }`)},
+		{name: "原样重新抛出", rule: &JavaBroadCatchRule{}, want: 0,
+			src: inMethod(`try { run(); } catch (Throwable t) { cleanup(); throw t; }`)},
+
+		{name: "printStackTrace", rule: &JavaPrintStackTraceRule{}, want: 1,
+			src: inMethod(`try { run(); } catch (IOException e) { e.printStackTrace(); }`)},
+		{name: "日志记录异常", rule: &JavaPrintStackTraceRule{}, want: 0,
+			src: inMethod(`try { run(); } catch (IOException e) { log.error("failed", e); }`)},
+
+		{name: "重新抛出丢失原因", rule: &JavaRethrowWithoutCauseRule{}, want: 1,
+			src: inMethod(`try { run(); } catch (IOException e) { throw new IllegalStateException(e.getMessage()); }`)},
+		{name: "重新抛出带原因", rule: &JavaRethrowWithoutCauseRule{}, want: 0,
+			src: inMethod(`try { run(); } catch (IOException e) { throw new IllegalStateException("读取失败", e); }`)},
+
+		{name: "吞掉中断", rule: &JavaSwallowedInterruptRule{}, want: 1,
+			src: inMethod(`try { Thread.sleep(10); } catch (InterruptedException e) { log.warn("interrupted"); }`)},
+		{name: "恢复中断标志", rule: &JavaSwallowedInterruptRule{}, want: 0,
+			src: inMethod(`try { Thread.sleep(10); } catch (InterruptedException e) { Thread.currentThread().interrupt(); }`)},
+
+		{name: "finally中throw", rule: &JavaThrowInFinallyRule{}, want: 1,
+			src: inMethod(`try { run(); } finally { throw new IllegalStateException(); }`)},
+		{name: "finally中被捕获的throw", rule: &JavaThrowInFinallyRule{}, want: 0,
+			src: inMethod(`try { run(); } finally { try { throw new IOException(); } catch (IOException e) { log.warn("x", e); } }`)},
+
+		{name: "finally中return", rule: &JavaReturnInFinallyRule{}, want: 1,
+			src: inMethod(`try { run(); } finally { return; }`)},
+		{name: "finally中lambda的return", rule: &JavaReturnInFinallyRule{}, want: 0,
+			src: inMethod(`try { run(); } finally { executor.submit(() -> { return 1; }); }`)},
+	})
+}
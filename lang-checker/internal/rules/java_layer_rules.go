@@ -0,0 +1,392 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/liujinliang/lang-checker/internal/complexity"
+	"github.com/liujinliang/lang-checker/internal/java"
+	"github.com/liujinliang/lang-checker/internal/models"
+)
+
+// Spring分层规范文档
+const (
+	controllersDoc  = "cursor/java/modules/controllers.mdc"
+	repositoriesDoc = "cursor/java/modules/repositories.mdc"
+)
+
+// JavaControllerRepositoryRule 控制器不应直接调用数据访问层
+type JavaControllerRepositoryRule struct{}
+
+func (r *JavaControllerRepositoryRule) Check(ctx context.Context, file *SourceFile) []models.Issue {
+	var issues []models.Issue
+	ast := javaFile(file)
+	for _, decl := range java.TypeDecls(ast) {
+		if ctx.Err() != nil {
+			return issues
+		}
+		if !isJavaController(decl) {
+			continue
+		}
+		repos := make(map[string]string)
+		for _, member := range decl.Members {
+			if f, ok := member.(*java.FieldDecl); ok && isJavaRepositoryType(ast, f.Type.Name) {
+				for _, v := range f.Vars {
+					repos[v.Name.Name] = f.Type.Name
+				}
+			}
+		}
+		if len(repos) == 0 {
+			continue
+		}
+		for _, m := range javaOwnMethods(decl) {
+			java.Inspect(m, func(n java.Node) bool {
+				call, ok := n.(*java.CallExpr)
+				if !ok {
+					return true
+				}
+				field := javaFieldRef(call.X)
+				if typ, ok := repos[field]; ok {
+					issue := javaIssueAt(call.Pos(), call.End())
+					issue.Message = fmt.Sprintf("控制器%s直接调用了数据访问层%s.%s", decl.TypeName(), typ, call.Name.Name)
+					issue.Suggestion = "通过Service层访问数据，控制器只负责参数校验、调用服务和封装响应"
+					issues = append(issues, issue)
+				}
+				return true
+			})
+		}
+	}
+	return issues
+}
+
+func (r *JavaControllerRepositoryRule) Meta() Metadata {
+	return Metadata{
+		ID:          "java/controller-repository-access",
+		Name:        "JavaControllerRepositoryAccess",
+		Category:    CategoryDesign,
+		Severity:    models.SeverityWarning,
+		Languages:   []models.Language{models.Java},
+		Tags:        []string{"spring", "layering"},
+		Description: "控制器通过Repository/Dao类型的字段直接访问数据时报告，数据访问应经由Service层",
+		DocURL:      controllersDoc,
+	}
+}
+
+// JavaControllerEntityRule @RestController的接口方法不应直接返回实体
+type JavaControllerEntityRule struct{}
+
+func (r *JavaControllerEntityRule) Check(ctx context.Context, file *SourceFile) []models.Issue {
+	var issues []models.Issue
+	ast := javaFile(file)
+	entities := make(map[string]bool)
+	for _, decl := range java.TypeDecls(ast) {
+		if javaAnnotation(decl.Modifiers, "Entity") != nil {
+			entities[decl.TypeName()] = true
+		}
+	}
+	for _, decl := range java.TypeDecls(ast) {
+		if ctx.Err() != nil {
+			return issues
+		}
+		rest := javaAnnotation(decl.Modifiers, "RestController") != nil ||
+			javaAnnotation(decl.Modifiers, "Controller") != nil && javaAnnotation(decl.Modifiers, "ResponseBody") != nil
+		for _, m := range javaOwnMethods(decl) {
+			if m.Result == nil || !rest && javaAnnotation(m.Modifiers, "ResponseBody") == nil {
+				continue
+			}
+			if !m.Has("public") && !isJavaHandler(m) {
+				continue
+			}
+			if t := javaEntityType(ast, m.Result, entities); t != nil {
+				issue := javaIssueAt(m.Result.Pos(), m.Result.End())
+				issue.Message = fmt.Sprintf("接口方法%s直接返回了实体%s", m.Name.Name, t.Name)
+				issue.Suggestion = "转换为DTO/VO后返回，避免暴露持久化结构和懒加载字段"
+				issues = append(issues, issue)
+			}
+		}
+	}
+	return issues
+}
+
+func (r *JavaControllerEntityRule) Meta() Metadata {
+	return Metadata{
+		ID:          "java/controller-returns-entity",
+		Name:        "JavaControllerReturnsEntity",
+		Category:    CategoryDesign,
+		Severity:    models.SeverityWarning,
+		Languages:   []models.Language{models.Java},
+		Tags:        []string{"spring", "layering"},
+		Description: "@RestController的接口方法返回类型（含ResponseEntity、List等的类型实参）为@Entity或Entity/PO/DO命名的实体类时报告",
+		DocURL:      controllersDoc,
+	}
+}
+
+// JavaControllerTransactionalRule 事务应在Service层声明，不应标注在控制器上
+type JavaControllerTransactionalRule struct{}
+
+func (r *JavaControllerTransactionalRule) Check(ctx context.Context, file *SourceFile) []models.Issue {
+	var issues []models.Issue
+	for _, decl := range java.TypeDecls(javaFile(file)) {
+		if ctx.Err() != nil {
+			return issues
+		}
+		if !isJavaController(decl) {
+			continue
+		}
+		report := func(a *java.Annotation, target string) {
+			issue := javaIssueAt(a.Pos(), a.End())
+			issue.Message = fmt.Sprintf("%s上标注了@Transactional", target)
+			issue.Suggestion = "把事务边界放到Service层的方法上，控制器只调用服务"
+			issues = append(issues, issue)
+		}
+		if a := javaAnnotation(decl.Modifiers, "Transactional"); a != nil {
+			report(a, "控制器"+decl.TypeName())
+		}
+		for _, m := range javaOwnMethods(decl) {
+			if a := javaAnnotation(m.Modifiers, "Transactional"); a != nil {
+				report(a, "控制器方法"+decl.TypeName()+"."+m.Name.Name)
+			}
+		}
+	}
+	return issues
+}
+
+func (r *JavaControllerTransactionalRule) Meta() Metadata {
+	return Metadata{
+		ID:          "java/controller-transactional",
+		Name:        "JavaControllerTransactional",
+		Category:    CategoryDesign,
+		Severity:    models.SeverityWarning,
+		Languages:   []models.Language{models.Java},
+		Tags:        []string{"spring", "layering"},
+		Description: "控制器类或其方法标注@Transactional时报告，事务应由Service层管理",
+		DocURL:      controllersDoc,
+	}
+}
+
+// JavaRepositoryServiceRule 数据访问层不应依赖Service层
+type JavaRepositoryServiceRule struct{}
+
+func (r *JavaRepositoryServiceRule) Check(ctx context.Context, file *SourceFile) []models.Issue {
+	var issues []models.Issue
+	ast := javaFile(file)
+	for _, decl := range java.TypeDecls(ast) {
+		if ctx.Err() != nil {
+			return issues
+		}
+		if !isJavaRepository(decl) {
+			continue
+		}
+		seen := make(map[string]bool)
+		java.Inspect(decl, func(n java.Node) bool {
+			switch n := n.(type) {
+			case *java.TypeDecl:
+				// 具名的内部类型单独判断
+				return n == decl || n.Name == nil
+			case *java.Type:
+				if !isJavaServiceType(ast, n.Name) {
+					return true
+				}
+				qualified := complexity.QualifyJavaType(ast, n.Name)
+				if seen[qualified] {
+					return true
+				}
+				seen[qualified] = true
+				issue := javaIssueAt(n.Pos(), n.End())
+				issue.Message = fmt.Sprintf("数据访问层%s依赖了服务层类型%s", decl.TypeName(), n.Name)
+				issue.Suggestion = "Repository只负责数据访问，需要组合业务逻辑时由Service调用Repository"
+				issues = append(issues, issue)
+			}
+			return true
+		})
+	}
+	return issues
+}
+
+func (r *JavaRepositoryServiceRule) Meta() Metadata {
+	return Metadata{
+		ID:          "java/repository-depends-on-service",
+		Name:        "JavaRepositoryDependsOnService",
+		Category:    CategoryDesign,
+		Severity:    models.SeverityWarning,
+		Languages:   []models.Language{models.Java},
+		Tags:        []string{"spring", "layering"},
+		Description: "Repository/Dao类引用Service类型（字段、参数、局部变量等）时报告，依赖方向应为Service依赖Repository",
+		DocURL:      repositoriesDoc,
+	}
+}
+
+// javaAnnotation 按简单名查找注解，不存在时返回nil
+func javaAnnotation(mods java.Modifiers, name string) *java.Annotation {
+	for _, a := range mods.Annotations {
+		if javaSimpleName(a.Name) == name {
+			return a
+		}
+	}
+	return nil
+}
+
+// javaSimpleName 返回限定名的最后一段
+func javaSimpleName(name string) string {
+	return name[strings.LastIndexByte(name, '.')+1:]
+}
+
+// javaOwnMethods 返回直接声明在类型中的方法，不含内部类型的方法
+func javaOwnMethods(decl *java.TypeDecl) []*java.MethodDecl {
+	var methods []*java.MethodDecl
+	for _, member := range decl.Members {
+		if m, ok := member.(*java.MethodDecl); ok {
+			methods = append(methods, m)
+		}
+	}
+	return methods
+}
+
+// javaFieldRef 返回表达式引用的字段名（name或this.name），否则返回空字符串
+func javaFieldRef(x java.Expr) string {
+	switch x := x.(type) {
+	case *java.Identifier:
+		return x.Name
+	case *java.SelectorExpr:
+		if this, ok := x.X.(*java.ThisExpr); ok && this.Qualifier == nil {
+			return x.Name.Name
+		}
+	}
+	return ""
+}
+
+func isJavaController(decl *java.TypeDecl) bool {
+	if decl.Kind != java.ClassKind {
+		return false
+	}
+	return javaAnnotation(decl.Modifiers, "RestController") != nil ||
+		javaAnnotation(decl.Modifiers, "Controller") != nil ||
+		strings.HasSuffix(decl.TypeName(), "Controller")
+}
+
+// isJavaHandler 判断方法是否标注了@RequestMapping、@GetMapping等请求映射注解
+func isJavaHandler(m *java.MethodDecl) bool {
+	for _, a := range m.Annotations {
+		if strings.HasSuffix(javaSimpleName(a.Name), "Mapping") {
+			return true
+		}
+	}
+	return false
+}
+
+func isJavaRepository(decl *java.TypeDecl) bool {
+	if decl.Kind != java.ClassKind && decl.Kind != java.InterfaceKind {
+		return false
+	}
+	if javaAnnotation(decl.Modifiers, "Repository") != nil || isRepositoryName(decl.TypeName()) {
+		return true
+	}
+	for _, t := range decl.Extends {
+		if isRepositoryName(javaSimpleName(t.Name)) {
+			return true
+		}
+	}
+	return false
+}
+
+func isRepositoryName(name string) bool {
+	name = strings.TrimSuffix(name, "Impl")
+	return strings.HasSuffix(name, "Repository") || strings.HasSuffix(name, "Dao") || strings.HasSuffix(name, "DAO")
+}
+
+// isJavaRepositoryType 按类型名或所在包（repository、dao等）判断是否为数据访问层类型
+func isJavaRepositoryType(file *java.File, name string) bool {
+	return javaLayerType(file, name, isRepositoryName, "repository", "repositories", "dao")
+}
+
+// serviceLikeJDKTypes 以Service结尾的JDK类型，通配符导入时无法按包排除
+var serviceLikeJDKTypes = map[string]bool{
+	"ExecutorService":           true,
+	"ScheduledExecutorService":  true,
+	"CompletionService":         true,
+	"ExecutorCompletionService": true,
+}
+
+// isJavaServiceType 按类型名或所在包（service）判断是否为服务层类型
+func isJavaServiceType(file *java.File, name string) bool {
+	return javaLayerType(file, name, func(simple string) bool {
+		if serviceLikeJDKTypes[simple] {
+			return false
+		}
+		simple = strings.TrimSuffix(simple, "Impl")
+		return strings.HasSuffix(simple, "Service")
+	}, "service", "services")
+}
+
+// entityLikeFrameworkTypes 以Entity结尾的Spring类型
+var entityLikeFrameworkTypes = map[string]bool{
+	"ResponseEntity": true,
+	"RequestEntity":  true,
+	"HttpEntity":     true,
+}
+
+// javaEntityType 返回类型或其类型实参中的第一个实体类型，entities为同文件中标注@Entity的类型
+func javaEntityType(file *java.File, t *java.Type, entities map[string]bool) *java.Type {
+	if t == nil {
+		return nil
+	}
+	if entities[t.Name] || javaLayerType(file, t.Name, func(simple string) bool {
+		if entityLikeFrameworkTypes[simple] {
+			return false
+		}
+		return strings.HasSuffix(simple, "Entity") || hasUpperSuffix(simple, "PO") || hasUpperSuffix(simple, "DO")
+	}, "entity", "entities", "po") {
+		return t
+	}
+	for _, arg := range t.Args {
+		if e := javaEntityType(file, arg, entities); e != nil {
+			return e
+		}
+	}
+	return javaEntityType(file, t.Bound, entities)
+}
+
+// hasUpperSuffix 判断name是否以大写缩写suffix结尾且前面是小写字母，如UserPO
+func hasUpperSuffix(name, suffix string) bool {
+	prefix, ok := strings.CutSuffix(name, suffix)
+	if !ok || prefix == "" {
+		return false
+	}
+	last := prefix[len(prefix)-1]
+	return last >= 'a' && last <= 'z'
+}
+
+// javaLayerType 判断类型是否属于某一层：简单名满足matchName，或解析到的包（与当前文件不同）含指定的包名段。
+// JDK和Spring自身的类型不属于任何业务层
+func javaLayerType(file *java.File, name string, matchName func(string) bool, segments ...string) bool {
+	if name == "" || !isJavaTypeName(javaSimpleName(name)) {
+		return false
+	}
+	qualified := complexity.QualifyJavaType(file, name)
+	for _, prefix := range []string{"java.", "javax.", "jakarta.", "org.springframework."} {
+		if strings.HasPrefix(qualified, prefix) {
+			return false
+		}
+	}
+	if matchName(javaSimpleName(name)) {
+		return true
+	}
+	pkg := qualified[:max(strings.LastIndexByte(qualified, '.'), 0)]
+	if pkg == file.Package {
+		return false
+	}
+	for _, seg := range strings.Split(pkg, ".") {
+		for _, s := range segments {
+			if seg == s {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isJavaTypeName 排除基本类型、var和通配符
+func isJavaTypeName(name string) bool {
+	return name != "" && name[0] >= 'A' && name[0] <= 'Z'
+}
@@ -0,0 +1,59 @@
+package rules
+
+import "testing"
+
+func TestJavaLayerRules(t *testing.T) {
+	runJavaCases(t, []ruleCase{
+		{name: "控制器调用Repository", rule: &JavaControllerRepositoryRule{}, want: 1, src: `
+@RestController
+class UserController {
+    private final UserRepository userRepository;
+    User get(long id) { return userRepository.findById(id); }
+}`},
+		{name: "控制器调用Service", rule: &JavaControllerRepositoryRule{}, want: 0, src: `
+@RestController
+class UserController {
+    private final UserService userService;
+    UserDto get(long id) { return userService.find(id); }
+}`},
+		{name: "接口返回实体", rule: &JavaControllerEntityRule{}, want: 1, src: `
+import com.example.entity.User;
+
+@RestController
+class UserController {
+    @GetMapping("/users")
+    public ResponseEntity<List<User>> list() { return null; }
+}`},
+		{name: "接口返回DTO", rule: &JavaControllerEntityRule{}, want: 0, src: `
+@RestController
+class UserController {
+    @GetMapping("/users")
+    public ResponseEntity<List<UserDto>> list() { return null; }
+}`},
+		{name: "控制器方法标注事务", rule: &JavaControllerTransactionalRule{}, want: 1, src: `
+@Controller
+class OrderController {
+    @Transactional
+    public void submit() {}
+}`},
+		{name: "Service标注事务", rule: &JavaControllerTransactionalRule{}, want: 0, src: `
+@Service
+class OrderService {
+    @Transactional
+    public void submit() {}
+}`},
+		{name: "Repository依赖Service", rule: &JavaRepositoryServiceRule{}, want: 1, src: `
+@Repository
+class OrderDao {
+    private OrderService orderService;
+    void save(OrderService service) {}
+}`},
+		{name: "Repository使用ExecutorService", rule: &JavaRepositoryServiceRule{}, want: 0, src: `
+import java.util.concurrent.*;
+
+@Repository
+class OrderDao {
+    private ExecutorService executor;
+}`},
+	})
+}
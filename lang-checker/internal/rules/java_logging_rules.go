@@ -0,0 +1,464 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/liujinliang/lang-checker/internal/java"
+	"github.com/liujinliang/lang-checker/internal/models"
+)
+
+// loggingChecklistDoc 日志检查清单
+const loggingChecklistDoc = "reviews/java-code-review-chapters/4.10-logging-monitoring-check.md"
+
+// logLevels 日志框架的输出方法
+var logLevels = map[string]bool{
+	"trace": true,
+	"debug": true,
+	"info":  true,
+	"warn":  true,
+	"error": true,
+	"fatal": true,
+}
+
+// loggerFactories 创建日志对象的工厂方法，如 LoggerFactory.getLogger
+var loggerFactories = map[string]string{
+	"LoggerFactory": "getLogger",
+	"LogManager":    "getLogger",
+	"Logger":        "getLogger",
+	"LogFactory":    "getLog",
+}
+
+// lombokLoggers 生成log字段的Lombok注解
+var lombokLoggers = []string{"Slf4j", "Log4j2", "Log4j", "CommonsLog", "Log", "XSlf4j", "JBossLog", "Flogger", "CustomLog"}
+
+// sensitiveWords 敏感字段名包含的词，比较时忽略大小写和下划线
+var sensitiveWords = []string{"password", "passwd", "pwd", "token", "secret", "idcard", "idno", "phone", "mobile", "bankcard", "cardno"}
+
+// JavaSystemOutRule 使用System.out/System.err输出规则
+type JavaSystemOutRule struct{}
+
+func (r *JavaSystemOutRule) Check(ctx context.Context, file *SourceFile) []models.Issue {
+	var issues []models.Issue
+	if file.IsTest {
+		return nil
+	}
+	for _, m := range java.Methods(javaFile(file)) {
+		if ctx.Err() != nil {
+			return issues
+		}
+		if m.Body == nil || isJavaMain(m) {
+			continue
+		}
+		javaInspectLocal(m.Body, func(n java.Node) bool {
+			call, ok := n.(*java.CallExpr)
+			if !ok {
+				return true
+			}
+			if stream := javaSystemStream(call.X); stream != "" {
+				issue := javaIssueAt(call.Pos(), call.End())
+				issue.Message = fmt.Sprintf("使用System.%s输出，内容不经过日志框架，没有级别、时间和traceId（检查清单4.10.1.1）", stream)
+				issue.Suggestion = "使用SLF4J日志对象按级别输出，如 log.info(\"...{}\", value)"
+				issues = append(issues, issue)
+			}
+			return true
+		})
+	}
+	return issues
+}
+
+func (r *JavaSystemOutRule) Meta() Metadata {
+	return Metadata{
+		ID:          "java/system-out",
+		Name:        "JavaSystemOut",
+		Category:    CategoryDesign,
+		Severity:    models.SeverityWarning,
+		Languages:   []models.Language{models.Java},
+		Tags:        []string{"logging"},
+		Description: "在main方法和测试代码之外调用System.out/System.err的方法时报告",
+		DocURL:      loggingChecklistDoc,
+	}
+}
+
+// JavaLogConcatRule 日志消息使用字符串拼接规则
+type JavaLogConcatRule struct{}
+
+func (r *JavaLogConcatRule) Check(ctx context.Context, file *SourceFile) []models.Issue {
+	var issues []models.Issue
+	ast := javaFile(file)
+	loggers := javaLoggerNames(ast)
+	java.Inspect(ast, func(n java.Node) bool {
+		if ctx.Err() != nil {
+			return false
+		}
+		call, ok := n.(*java.CallExpr)
+		if !ok || !isJavaLogCall(call, loggers) || len(call.Args) == 0 {
+			return true
+		}
+		if msg, ok := javaUnparen(call.Args[0]).(*java.BinaryExpr); ok && msg.Op == "+" && !isJavaConstantConcat(msg) {
+			issue := javaIssueAt(msg.Pos(), msg.End())
+			issue.Message = fmt.Sprintf("日志%s()的消息使用字符串拼接，日志级别关闭时也会执行拼接和toString()（检查清单4.10.1.1）", call.Name.Name)
+			issue.Suggestion = "使用{}占位符并把变量作为参数传入，如 log.info(\"orderId={}\", orderId)"
+			issues = append(issues, issue)
+		}
+		return true
+	})
+	return issues
+}
+
+func (r *JavaLogConcatRule) Meta() Metadata {
+	return Metadata{
+		ID:          "java/log-string-concat",
+		Name:        "JavaLogStringConcat",
+		Category:    CategoryDesign,
+		Severity:    models.SeverityInfo,
+		Languages:   []models.Language{models.Java},
+		Tags:        []string{"logging", "performance"},
+		Description: "SLF4J/Log4j日志调用的消息参数是含变量的字符串拼接时报告，只拼接字面量的除外",
+		DocURL:      loggingChecklistDoc,
+	}
+}
+
+// JavaLogWithoutThrowableRule 记录异常时未传入异常对象规则
+type JavaLogWithoutThrowableRule struct{}
+
+func (r *JavaLogWithoutThrowableRule) Check(ctx context.Context, file *SourceFile) []models.Issue {
+	var issues []models.Issue
+	ast := javaFile(file)
+	loggers := javaLoggerNames(ast)
+	for _, c := range javaCatches(ctx, ast) {
+		name := c.Name.Name
+		javaInspectCatch(c, func(n java.Node) bool {
+			call, ok := n.(*java.CallExpr)
+			if !ok || !isJavaLogCall(call, loggers) || javaPassesCause(call.Args, name) {
+				return true
+			}
+			mentioned := false
+			for _, arg := range call.Args {
+				java.Inspect(arg, func(n java.Node) bool {
+					if id, ok := n.(*java.Identifier); ok && id.Name == name {
+						mentioned = true
+					}
+					return !mentioned
+				})
+			}
+			if !mentioned && call.Name.Name != "error" {
+				return true
+			}
+			issue := javaIssueAt(call.Pos(), call.End())
+			if mentioned {
+				issue.Message = fmt.Sprintf("日志只记录了异常%s的部分信息，没有把%s本身作为参数传入，堆栈会丢失（检查清单4.9.2.1、4.10.1.2）", name, name)
+			} else {
+				issue.Message = fmt.Sprintf("在catch块中记录错误日志时没有传入异常%s，无法定位原因（检查清单4.9.2.1、4.10.1.2）", name)
+			}
+			issue.Suggestion = fmt.Sprintf("把异常作为最后一个参数传入，如 log.%s(\"...\", %s)", call.Name.Name, name)
+			issues = append(issues, issue)
+			return true
+		})
+	}
+	return issues
+}
+
+func (r *JavaLogWithoutThrowableRule) Meta() Metadata {
+	return Metadata{
+		ID:          "java/log-without-throwable",
+		Name:        "JavaLogWithoutThrowable",
+		Category:    CategoryErrorHandling,
+		Severity:    models.SeverityWarning,
+		Languages:   []models.Language{models.Java},
+		Tags:        []string{"logging", "exception"},
+		Description: "catch块中的日志调用只使用了e.getMessage()等而没有直接传入异常变量，或error级别日志完全没有引用异常时报告",
+		DocURL:      exceptionChecklistDoc,
+	}
+}
+
+// JavaLoggerDeclarationRule 日志对象声明规则
+type JavaLoggerDeclarationRule struct{}
+
+func (r *JavaLoggerDeclarationRule) Check(ctx context.Context, file *SourceFile) []models.Issue {
+	var issues []models.Issue
+	ast := javaFile(file)
+	for _, decl := range java.TypeDecls(ast) {
+		if ctx.Err() != nil {
+			return issues
+		}
+		for _, member := range decl.Members {
+			f, ok := member.(*java.FieldDecl)
+			if !ok {
+				continue
+			}
+			for _, v := range f.Vars {
+				call, ok := javaUnparen(v.Init).(*java.CallExpr)
+				if !ok || !isJavaLoggerFactory(call) {
+					continue
+				}
+				var problems []string
+				if decl.Kind != java.InterfaceKind && (!f.Has("static") || !f.Has("final")) {
+					problems = append(problems, "没有声明为static final，每个实例都会创建或持有一个日志对象")
+				}
+				if lit := javaLoggerClass(call); lit != "" && !isJavaEnclosingType(decl, lit) {
+					problems = append(problems, fmt.Sprintf("getLogger传入的是%s.class而不是所在类%s，日志会记在其他类名下", lit, decl.TypeName()))
+				}
+				if len(problems) == 0 {
+					continue
+				}
+				issue := javaIssueAt(v.Pos(), v.End())
+				issue.Message = fmt.Sprintf("日志对象%s：%s（检查清单4.10.1.1）", v.Name.Name, strings.Join(problems, "；"))
+				issue.Suggestion = fmt.Sprintf("声明为 private static final Logger log = LoggerFactory.getLogger(%s.class)，或使用Lombok的@Slf4j", decl.TypeName())
+				issues = append(issues, issue)
+			}
+		}
+		if name, call := javaUndeclaredLogger(file, decl); call != nil {
+			issue := javaIssueAt(call.Pos(), call.End())
+			issue.Message = fmt.Sprintf("类%s使用的日志对象%s不是在本类中声明的，日志会记在父类或其他类名下（检查清单4.10.1.1）", decl.TypeName(), name)
+			issue.Suggestion = fmt.Sprintf("在%s中声明自己的 private static final Logger，或使用Lombok的@Slf4j", decl.TypeName())
+			issues = append(issues, issue)
+		}
+	}
+	return issues
+}
+
+func (r *JavaLoggerDeclarationRule) Meta() Metadata {
+	return Metadata{
+		ID:          "java/logger-declaration",
+		Name:        "JavaLoggerDeclaration",
+		Category:    CategoryDesign,
+		Severity:    models.SeverityWarning,
+		Languages:   []models.Language{models.Java},
+		Tags:        []string{"logging"},
+		Description: "通过LoggerFactory.getLogger等创建的日志字段不是static final、传入的类字面量不是所在类，或类中使用了未在本类及外层类声明、也没有Lombok日志注解的log/logger时报告",
+		DocURL:      loggingChecklistDoc,
+	}
+}
+
+// JavaLogSensitiveDataRule 日志输出敏感字段规则
+type JavaLogSensitiveDataRule struct{}
+
+func (r *JavaLogSensitiveDataRule) Check(ctx context.Context, file *SourceFile) []models.Issue {
+	var issues []models.Issue
+	ast := javaFile(file)
+	loggers := javaLoggerNames(ast)
+	java.Inspect(ast, func(n java.Node) bool {
+		if ctx.Err() != nil {
+			return false
+		}
+		call, ok := n.(*java.CallExpr)
+		if !ok || !isJavaLogCall(call, loggers) {
+			return true
+		}
+		if name := javaSensitiveArg(call.Args); name != "" {
+			issue := javaIssueAt(call.Pos(), call.End())
+			issue.Message = fmt.Sprintf("日志输出了敏感字段%s（检查清单4.10.1.2）", name)
+			issue.Suggestion = "不要记录密码、令牌等凭据；手机号、身份证号等个人信息脱敏后再记录"
+			issues = append(issues, issue)
+		}
+		return true
+	})
+	return issues
+}
+
+func (r *JavaLogSensitiveDataRule) Meta() Metadata {
+	return Metadata{
+		ID:          "java/log-sensitive-data",
+		Name:        "JavaLogSensitiveData",
+		Category:    CategoryDesign,
+		Severity:    models.SeverityError,
+		Languages:   []models.Language{models.Java},
+		Tags:        []string{"logging", "security"},
+		Description: "日志调用的参数引用了名称含password、token、secret、idCard、phone、mobile等的变量、字段或getter时报告，经过mask/desensitize/encrypt/hash等方法处理的除外",
+		DocURL:      loggingChecklistDoc,
+	}
+}
+
+// isJavaMain 判断是否为 public static void main(String[] args)
+func isJavaMain(m *java.MethodDecl) bool {
+	return m.Name.Name == "main" && m.Has("public") && m.Has("static") &&
+		m.Result != nil && m.Result.Name == "void" && len(m.Params) == 1
+}
+
+// javaSystemStream 表达式为System.out或System.err时返回out或err
+func javaSystemStream(x java.Expr) string {
+	sel, ok := x.(*java.SelectorExpr)
+	if !ok {
+		return ""
+	}
+	if id, ok := sel.X.(*java.Identifier); ok && id.Name == "System" && (sel.Name.Name == "out" || sel.Name.Name == "err") {
+		return sel.Name.Name
+	}
+	return ""
+}
+
+// javaLoggerNames 返回文件中日志对象的变量名：类型为Logger/Log的字段，以及Lombok和常见写法的log、logger
+func javaLoggerNames(file *java.File) map[string]bool {
+	names := map[string]bool{"log": true, "logger": true, "LOG": true, "LOGGER": true}
+	java.Inspect(file, func(n java.Node) bool {
+		if f, ok := n.(*java.FieldDecl); ok {
+			if typ := javaSimpleName(f.Type.Name); typ == "Logger" || typ == "Log" {
+				for _, v := range f.Vars {
+					names[v.Name.Name] = true
+				}
+			}
+		}
+		return true
+	})
+	return names
+}
+
+// isJavaLogCall 判断是否为对日志对象的info/warn/error等调用
+func isJavaLogCall(call *java.CallExpr, loggers map[string]bool) bool {
+	return logLevels[call.Name.Name] && call.X != nil && loggers[javaFieldRef(call.X)]
+}
+
+// isJavaConstantConcat 判断拼接的各部分是否都是字面量
+func isJavaConstantConcat(x java.Expr) bool {
+	switch x := javaUnparen(x).(type) {
+	case *java.Literal:
+		return true
+	case *java.BinaryExpr:
+		return x.Op == "+" && isJavaConstantConcat(x.X) && isJavaConstantConcat(x.Y)
+	}
+	return false
+}
+
+// isJavaLoggerFactory 判断调用是否为 LoggerFactory.getLogger(...) 等日志工厂方法
+func isJavaLoggerFactory(call *java.CallExpr) bool {
+	id, ok := call.X.(*java.Identifier)
+	if !ok {
+		if sel, ok := call.X.(*java.SelectorExpr); ok {
+			id = sel.Name
+		}
+	}
+	return id != nil && loggerFactories[id.Name] == call.Name.Name
+}
+
+// javaLoggerClass 返回getLogger参数中的类字面量类型名，参数不是类字面量时返回空字符串
+func javaLoggerClass(call *java.CallExpr) string {
+	if len(call.Args) != 1 {
+		return ""
+	}
+	if lit, ok := javaUnparen(call.Args[0]).(*java.ClassLit); ok {
+		return javaSimpleName(lit.Type.Name)
+	}
+	return ""
+}
+
+// isJavaEnclosingType 判断name是否为decl或其外层类型
+func isJavaEnclosingType(decl *java.TypeDecl, name string) bool {
+	for t := decl; t != nil; t = t.Outer {
+		if t.TypeName() == name {
+			return true
+		}
+	}
+	return false
+}
+
+// javaUndeclaredLogger 返回类中第一个对log/logger等的日志调用，前提是该名称不是本类或外层类的字段、
+// 方法的参数或局部变量，且这些类上没有Lombok日志注解
+func javaUndeclaredLogger(file *SourceFile, decl *java.TypeDecl) (string, *java.CallExpr) {
+	declared := make(map[string]bool)
+	for t := decl; t != nil; t = t.Outer {
+		for _, name := range lombokLoggers {
+			if javaAnnotation(t.Modifiers, name) != nil {
+				return "", nil
+			}
+		}
+		for name := range javaFields(file, t) {
+			declared[name] = true
+		}
+	}
+	loggers := map[string]bool{"log": true, "logger": true, "LOG": true, "LOGGER": true}
+	for _, m := range javaOwnMethods(decl) {
+		if m.Body == nil {
+			continue
+		}
+		// 匿名类和lambda中的调用也在方法体内，外层方法的局部变量对它们可见
+		locals := javaLocals(m)
+		var found *java.CallExpr
+		java.Inspect(m.Body, func(n java.Node) bool {
+			call, ok := n.(*java.CallExpr)
+			if !ok || found != nil {
+				return found == nil
+			}
+			id, ok := call.X.(*java.Identifier)
+			if ok && isJavaLogCall(call, loggers) && !declared[id.Name] {
+				if _, local := locals[id.Name]; !local {
+					found = call
+				}
+			}
+			return true
+		})
+		if found != nil {
+			return found.X.(*java.Identifier).Name, found
+		}
+	}
+	return "", nil
+}
+
+// javaSensitiveArg 返回日志参数中引用的第一个敏感名称，脱敏、加密方法的参数和普通方法的接收者不计入
+func javaSensitiveArg(args []java.Expr) string {
+	for _, arg := range args {
+		if name := javaSensitiveExpr(arg); name != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+func javaSensitiveExpr(x java.Expr) string {
+	switch x := javaUnparen(x).(type) {
+	case *java.Identifier:
+		if isSensitiveName(x.Name) {
+			return x.Name
+		}
+	case *java.SelectorExpr:
+		if isSensitiveName(x.Name.Name) {
+			return x.Name.Name
+		}
+	case *java.CallExpr:
+		if isJavaMaskingCall(x.Name.Name) {
+			return ""
+		}
+		if getter := strings.TrimPrefix(x.Name.Name, "get"); getter != x.Name.Name && len(x.Args) == 0 && isSensitiveName(getter) {
+			return x.Name.Name + "()"
+		}
+		// user.getPhone().trim() 这类链式调用检查接收者
+		if inner, ok := x.X.(*java.CallExpr); ok {
+			if name := javaSensitiveExpr(inner); name != "" {
+				return name
+			}
+		}
+		return javaSensitiveArg(x.Args)
+	case *java.BinaryExpr:
+		if name := javaSensitiveExpr(x.X); name != "" {
+			return name
+		}
+		return javaSensitiveExpr(x.Y)
+	case *java.CondExpr:
+		if name := javaSensitiveExpr(x.Then); name != "" {
+			return name
+		}
+		return javaSensitiveExpr(x.Else)
+	}
+	return ""
+}
+
+func isSensitiveName(name string) bool {
+	normalized := strings.ToLower(strings.ReplaceAll(name, "_", ""))
+	for _, w := range sensitiveWords {
+		if strings.Contains(normalized, w) {
+			return true
+		}
+	}
+	return false
+}
+
+func isJavaMaskingCall(name string) bool {
+	lower := strings.ToLower(name)
+	for _, w := range []string{"mask", "desensitiz", "encrypt", "hash", "digest", "hide", "redact"} {
+		if strings.Contains(lower, w) {
+			return true
+		}
+	}
+	return false
+}
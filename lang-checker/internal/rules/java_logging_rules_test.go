@@ -0,0 +1,53 @@
+package rules
+
+import "testing"
+
+func TestJavaLoggingRules(t *testing.T) {
+	const logger = "    private static final Logger log = LoggerFactory.getLogger(A.class);\n"
+	withLogger := func(body string) string {
+		return "class A {\n" + logger + "    void f(Order order, String password) {\n" + body + "\n    }\n}\n"
+	}
+	runJavaCases(t, []ruleCase{
+		{name: "System.out", rule: &JavaSystemOutRule{}, want: 1,
+			src: inMethod(`System.out.println("done");`)},
+		{name: "main方法中的System.out", rule: &JavaSystemOutRule{}, want: 0,
+			src: "class A {\n    public static void main(String[] args) {\n        System.out.println(\"usage\");\n    }\n}"},
+		{name: "测试代码中的System.out", rule: &JavaSystemOutRule{}, test: true, want: 0,
+			src: inMethod(`System.out.println("debug");`)},
+
+		{name: "日志消息拼接", rule: &JavaLogConcatRule{}, want: 1,
+			src: withLogger(`log.info("order " + order.getId() + " created");`)},
+		{name: "日志使用占位符", rule: &JavaLogConcatRule{}, want: 0,
+			src: withLogger(`log.info("order {} created", order.getId());`)},
+		{name: "拼接字面量", rule: &JavaLogConcatRule{}, want: 0,
+			src: withLogger(`log.info("order " + "created");`)},
+
+		{name: "只记录getMessage", rule: &JavaLogWithoutThrowableRule{}, want: 1,
+			src: withLogger(`try { run(); } catch (IOException e) { log.error("failed: {}", e.getMessage()); }`)},
+		{name: "传入异常", rule: &JavaLogWithoutThrowableRule{}, want: 0,
+			src: withLogger(`try { run(); } catch (IOException e) { log.error("failed", e); }`)},
+
+		{name: "日志字段不是static final", rule: &JavaLoggerDeclarationRule{}, want: 1, src: `
+class A {
+    private Logger log = LoggerFactory.getLogger(A.class);
+}`},
+		{name: "类字面量不是所在类", rule: &JavaLoggerDeclarationRule{}, want: 1, src: `
+class A {
+    private static final Logger log = LoggerFactory.getLogger(B.class);
+}`},
+		{name: "未声明的log", rule: &JavaLoggerDeclarationRule{}, want: 1,
+			src: inMethod(`log.info("x");`)},
+		{name: "Lombok日志注解", rule: &JavaLoggerDeclarationRule{}, want: 0, src: `
+@Slf4j
+class A {
+    void f() { log.info("x"); }
+}`},
+		{name: "规范的日志声明", rule: &JavaLoggerDeclarationRule{}, want: 0,
+			src: withLogger(`log.info("x");`)},
+
+		{name: "记录密码", rule: &JavaLogSensitiveDataRule{}, want: 1,
+			src: withLogger(`log.info("login {} {}", order.getId(), password);`)},
+		{name: "脱敏后记录", rule: &JavaLogSensitiveDataRule{}, want: 0,
+			src: withLogger(`log.info("login {}", mask(password));`)},
+	})
+}
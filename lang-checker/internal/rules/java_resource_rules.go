@@ -0,0 +1,449 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/liujinliang/lang-checker/internal/java"
+	"github.com/liujinliang/lang-checker/internal/models"
+)
+
+// resourceChecklistDoc 资源管理检查清单
+const resourceChecklistDoc = "reviews/java-code-review-chapters/4.8-resource-management-check.md"
+
+// resourceSuffixes 需要关闭的资源类型名后缀
+var resourceSuffixes = []string{"InputStream", "Reader", "Connection", "Statement", "ResultSet", "Socket"}
+
+// memoryResources 只持有内存数据、不需要关闭的资源类型
+var memoryResources = map[string]bool{
+	"ByteArrayInputStream": true,
+	"StringReader":         true,
+	"CharArrayReader":      true,
+	"URLConnection":        true,
+	"HttpURLConnection":    true,
+}
+
+// executorTypes 线程池类型
+var executorTypes = map[string]bool{
+	"ExecutorService":             true,
+	"ScheduledExecutorService":    true,
+	"ThreadPoolExecutor":          true,
+	"ScheduledThreadPoolExecutor": true,
+	"ForkJoinPool":                true,
+}
+
+// JavaUnclosedResourceRule 流、连接等资源未在所有路径上关闭规则
+type JavaUnclosedResourceRule struct{}
+
+func (r *JavaUnclosedResourceRule) Check(ctx context.Context, file *SourceFile) []models.Issue {
+	var issues []models.Issue
+	for _, m := range java.Methods(javaFile(file)) {
+		if ctx.Err() != nil {
+			return issues
+		}
+		if m.Body == nil {
+			continue
+		}
+		managed := make(map[java.Stmt]bool)
+		java.Inspect(m.Body, func(n java.Node) bool {
+			if t, ok := n.(*java.TryStmt); ok {
+				for _, res := range t.Resources {
+					managed[res] = true
+				}
+			}
+			return true
+		})
+		java.Inspect(m.Body, func(n java.Node) bool {
+			decl, ok := n.(*java.LocalVarStmt)
+			if !ok || managed[decl] {
+				return true
+			}
+			for _, v := range decl.Vars {
+				typ := javaDeclaredType(decl.Type, v.Init)
+				if !isJavaResourceType(typ) || !javaCreated(m.Body, v, isJavaResourceCreation) {
+					continue
+				}
+				use := javaResourceUse(m.Body, v.Name.Name, "close")
+				if use.escaped || use.inFinally {
+					continue
+				}
+				issue := javaIssueAt(v.Pos(), v.End())
+				if use.elsewhere {
+					issue.Message = fmt.Sprintf("%s %s只在正常流程中关闭，发生异常时不会释放（检查清单4.8.2.1、4.8.3.3）", typ, v.Name.Name)
+				} else {
+					issue.Message = fmt.Sprintf("%s %s创建后没有关闭（检查清单4.8.2.1、4.8.3.3）", typ, v.Name.Name)
+				}
+				issue.Suggestion = "使用try-with-resources声明资源，或在finally块中关闭"
+				issues = append(issues, issue)
+			}
+			return true
+		})
+	}
+	return issues
+}
+
+func (r *JavaUnclosedResourceRule) Meta() Metadata {
+	return Metadata{
+		ID:          "java/unclosed-resource",
+		Name:        "JavaUnclosedResource",
+		Category:    CategoryErrorHandling,
+		Severity:    models.SeverityWarning,
+		Languages:   []models.Language{models.Java},
+		Tags:        []string{"reliability", "resource"},
+		Description: "在try-with-resources之外创建的InputStream、Reader、Connection、Statement、ResultSet、Socket局部变量没有在finally中关闭时报告，返回、赋给字段或交给包装流的视为转移了所有权",
+		DocURL:      resourceChecklistDoc,
+	}
+}
+
+// JavaExecutorShutdownRule 线程池未关闭规则
+type JavaExecutorShutdownRule struct{}
+
+func (r *JavaExecutorShutdownRule) Check(ctx context.Context, file *SourceFile) []models.Issue {
+	var issues []models.Issue
+	ast := javaFile(file)
+	report := func(v *java.VarDecl, typ, where string) {
+		issue := javaIssueAt(v.Pos(), v.End())
+		issue.Message = fmt.Sprintf("%s %s创建后%s从未调用shutdown()，线程不会退出，应用无法正常停止", typ, v.Name.Name, where)
+		issue.Suggestion = "在使用完毕或组件销毁（如@PreDestroy）时调用shutdown()并awaitTermination()，或交给Spring管理的线程池"
+		issues = append(issues, issue)
+	}
+	for _, decl := range java.TypeDecls(ast) {
+		if ctx.Err() != nil {
+			return issues
+		}
+		for _, member := range decl.Members {
+			f, ok := member.(*java.FieldDecl)
+			if !ok || !executorTypes[javaSimpleName(f.Type.Name)] {
+				continue
+			}
+			for _, v := range f.Vars {
+				if javaCreated(decl, v, isJavaExecutorCreation) && !javaResourceUse(decl, v.Name.Name, "shutdown", "shutdownNow", "close").released() {
+					report(v, javaSimpleName(f.Type.Name), "在类中")
+				}
+			}
+		}
+	}
+	for _, m := range java.Methods(ast) {
+		if ctx.Err() != nil {
+			return issues
+		}
+		if m.Body == nil {
+			continue
+		}
+		java.Inspect(m.Body, func(n java.Node) bool {
+			decl, ok := n.(*java.LocalVarStmt)
+			if !ok {
+				return true
+			}
+			for _, v := range decl.Vars {
+				typ := javaDeclaredType(decl.Type, v.Init)
+				if !executorTypes[typ] && !(typ == "var" && isJavaExecutorCreation(v.Init)) {
+					continue
+				}
+				use := javaResourceUse(m.Body, v.Name.Name, "shutdown", "shutdownNow", "close")
+				if javaCreated(m.Body, v, isJavaExecutorCreation) && !use.released() {
+					report(v, typ, "在方法中")
+				}
+			}
+			return true
+		})
+	}
+	return issues
+}
+
+func (r *JavaExecutorShutdownRule) Meta() Metadata {
+	return Metadata{
+		ID:          "java/executor-not-shutdown",
+		Name:        "JavaExecutorNotShutdown",
+		Category:    CategoryErrorHandling,
+		Severity:    models.SeverityWarning,
+		Languages:   []models.Language{models.Java},
+		Tags:        []string{"reliability", "resource", "concurrency"},
+		Description: "通过Executors工厂方法或new ThreadPoolExecutor创建的线程池字段或局部变量，在所属类或方法中从未调用shutdown()/shutdownNow()/close()且没有返回或转交时报告",
+		DocURL:      resourceChecklistDoc,
+	}
+}
+
+// JavaThreadLocalRemoveRule ThreadLocal未清理规则
+type JavaThreadLocalRemoveRule struct{}
+
+func (r *JavaThreadLocalRemoveRule) Check(ctx context.Context, file *SourceFile) []models.Issue {
+	var issues []models.Issue
+	ast := javaFile(file)
+	for _, decl := range java.TypeDecls(ast) {
+		if ctx.Err() != nil {
+			return issues
+		}
+		for _, member := range decl.Members {
+			f, ok := member.(*java.FieldDecl)
+			if !ok || !f.Has("private") {
+				continue
+			}
+			if typ := javaSimpleName(f.Type.Name); typ != "ThreadLocal" && typ != "InheritableThreadLocal" {
+				continue
+			}
+			for _, v := range f.Vars {
+				if use := javaResourceUse(decl, v.Name.Name, "remove"); use.inFinally || use.elsewhere {
+					continue
+				}
+				issue := javaIssueAt(v.Pos(), v.End())
+				issue.Message = fmt.Sprintf("ThreadLocal %s从未调用remove()，线程池复用线程时会泄漏内存并串用上一个请求的数据（检查清单4.8.1.1）", v.Name.Name)
+				issue.Suggestion = "在使用结束的finally块中调用remove()"
+				issues = append(issues, issue)
+			}
+		}
+	}
+	return issues
+}
+
+func (r *JavaThreadLocalRemoveRule) Meta() Metadata {
+	return Metadata{
+		ID:          "java/threadlocal-not-removed",
+		Name:        "JavaThreadLocalNotRemoved",
+		Category:    CategoryErrorHandling,
+		Severity:    models.SeverityWarning,
+		Languages:   []models.Language{models.Java},
+		Tags:        []string{"reliability", "resource", "concurrency"},
+		Description: "private的ThreadLocal字段在所属类中从未调用remove()时报告，非private字段可能由其他类清理，不做判断",
+		DocURL:      resourceChecklistDoc,
+	}
+}
+
+// JavaLockUnlockRule lock()后未在finally中unlock()规则
+type JavaLockUnlockRule struct{}
+
+func (r *JavaLockUnlockRule) Check(ctx context.Context, file *SourceFile) []models.Issue {
+	var issues []models.Issue
+	for _, m := range java.Methods(javaFile(file)) {
+		if ctx.Err() != nil {
+			return issues
+		}
+		if m.Body == nil {
+			continue
+		}
+		unlocked := make(map[string]bool)
+		java.Inspect(m.Body, func(n java.Node) bool {
+			if t, ok := n.(*java.TryStmt); ok && t.Finally != nil {
+				java.Inspect(t.Finally, func(n java.Node) bool {
+					if call, ok := n.(*java.CallExpr); ok && call.X != nil && call.Name.Name == "unlock" {
+						unlocked[javaLockName(file, call.X)] = true
+					}
+					return true
+				})
+			}
+			return true
+		})
+		java.Inspect(m.Body, func(n java.Node) bool {
+			call, ok := n.(*java.CallExpr)
+			if !ok || call.X == nil || len(call.Args) > 0 {
+				return true
+			}
+			if name := call.Name.Name; name != "lock" && name != "lockInterruptibly" {
+				return true
+			}
+			if lock := javaLockName(file, call.X); !unlocked[lock] {
+				issue := javaIssueAt(call.Pos(), call.End())
+				issue.Message = fmt.Sprintf("%s.%s()之后没有在finally块中调用%s.unlock()，异常时锁不会释放", lock, call.Name.Name, lock)
+				issue.Suggestion = "加锁后紧跟try块，并在finally中unlock()"
+				issues = append(issues, issue)
+			}
+			return true
+		})
+	}
+	return issues
+}
+
+func (r *JavaLockUnlockRule) Meta() Metadata {
+	return Metadata{
+		ID:          "java/lock-without-unlock",
+		Name:        "JavaLockWithoutUnlock",
+		Category:    CategoryErrorHandling,
+		Severity:    models.SeverityError,
+		Languages:   []models.Language{models.Java},
+		Tags:        []string{"reliability", "resource", "concurrency"},
+		Description: "调用lock()或lockInterruptibly()的方法中没有finally块对同一个锁对象调用unlock()时报告",
+		DocURL:      resourceChecklistDoc,
+	}
+}
+
+// resourceUse 变量在作用域内的释放和转移情况
+type resourceUse struct {
+	// inFinally 在finally块中释放
+	inFinally bool
+	// elsewhere 在finally块之外释放
+	elsewhere bool
+	// escaped 被返回、赋给其他变量或字段、交给包装对象或try-with-resources管理
+	escaped bool
+}
+
+func (u resourceUse) released() bool {
+	return u.inFinally || u.elsewhere || u.escaped
+}
+
+// javaResourceUse 统计scope中对变量name的释放和转移：调用name.release()，
+// 或把name传给名称含release的方法（如closeQuietly），release为空时不检查
+func javaResourceUse(scope java.Node, name string, release ...string) resourceUse {
+	var use resourceUse
+	owner := javaScopeOwner(scope)
+	isName := func(x java.Expr) bool {
+		return x != nil && javaOwnFieldRef(javaUnparen(x), owner) == name
+	}
+	releases := func(call *java.CallExpr) bool {
+		for _, r := range release {
+			if call.Name.Name == r && isName(call.X) {
+				return true
+			}
+			if strings.Contains(strings.ToLower(call.Name.Name), strings.ToLower(r)) {
+				for _, arg := range call.Args {
+					if isName(arg) {
+						return true
+					}
+				}
+			}
+		}
+		return false
+	}
+	var finally []*java.Block
+	var visit func(n java.Node) bool
+	visit = func(n java.Node) bool {
+		switch n := n.(type) {
+		case *java.TryStmt:
+			for _, res := range n.Resources {
+				switch res := res.(type) {
+				case *java.ExprStmt:
+					use.escaped = use.escaped || isName(res.X)
+				case *java.LocalVarStmt:
+					for _, v := range res.Vars {
+						use.escaped = use.escaped || isName(v.Init)
+					}
+				}
+			}
+			if n.Finally != nil {
+				finally = append(finally, n.Finally)
+			}
+		case *java.CallExpr:
+			if releases(n) {
+				inFinally := false
+				for _, b := range finally {
+					if n.Pos().Offset >= b.Pos().Offset && n.End().Offset <= b.End().Offset {
+						inFinally = true
+					}
+				}
+				if inFinally {
+					use.inFinally = true
+				} else {
+					use.elsewhere = true
+				}
+			}
+		case *java.ReturnStmt:
+			use.escaped = use.escaped || isName(n.X)
+		case *java.AssignExpr:
+			use.escaped = use.escaped || isName(n.Y)
+		case *java.VarDecl:
+			use.escaped = use.escaped || isName(n.Init)
+		case *java.NewExpr:
+			for _, arg := range n.Args {
+				use.escaped = use.escaped || isName(arg)
+			}
+		}
+		return true
+	}
+	java.Inspect(scope, visit)
+	return use
+}
+
+// javaCreated 判断变量的初始值或scope中对它的赋值是否为新建对象
+func javaCreated(scope java.Node, v *java.VarDecl, isCreation func(java.Expr) bool) bool {
+	if v.Init != nil {
+		return isCreation(v.Init)
+	}
+	created := false
+	owner := javaScopeOwner(scope)
+	java.Inspect(scope, func(n java.Node) bool {
+		if a, ok := n.(*java.AssignExpr); ok && a.Op == "=" && javaOwnFieldRef(a.X, owner) == v.Name.Name && isCreation(a.Y) {
+			created = true
+		}
+		return !created
+	})
+	return created
+}
+
+// javaScopeOwner scope为类型声明时返回类名，否则返回空字符串
+func javaScopeOwner(scope java.Node) string {
+	if decl, ok := scope.(*java.TypeDecl); ok {
+		return decl.TypeName()
+	}
+	return ""
+}
+
+// javaOwnFieldRef 返回表达式引用的字段名，除name和this.name外，还接受以所在类名owner限定的owner.name
+func javaOwnFieldRef(x java.Expr, owner string) string {
+	if sel, ok := x.(*java.SelectorExpr); ok && owner != "" {
+		if id, ok := sel.X.(*java.Identifier); ok && id.Name == owner {
+			return sel.Name.Name
+		}
+	}
+	return javaFieldRef(x)
+}
+
+// javaDeclaredType 返回变量声明的类型简单名，var声明时取new表达式的类型
+func javaDeclaredType(t *java.Type, init java.Expr) string {
+	if t.Name == "var" {
+		if x, ok := init.(*java.NewExpr); ok {
+			return javaSimpleName(x.Type.Name)
+		}
+	}
+	return javaSimpleName(t.Name)
+}
+
+func isJavaResourceType(name string) bool {
+	if memoryResources[name] {
+		return false
+	}
+	for _, suffix := range resourceSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// isJavaResourceCreation 判断表达式是否创建了新资源：new表达式，或除getter外的方法调用
+// （如getConnection、createStatement、executeQuery、Files.newInputStream）
+func isJavaResourceCreation(x java.Expr) bool {
+	switch x := javaUnparen(x).(type) {
+	case *java.NewExpr:
+		return x.Body == nil && !memoryResources[javaSimpleName(x.Type.Name)]
+	case *java.CallExpr:
+		name := x.Name.Name
+		return !strings.HasPrefix(name, "get") || name == "getConnection" || name == "getResourceAsStream"
+	}
+	return false
+}
+
+// isJavaExecutorCreation 判断表达式是否为Executors.newXxx()或new ThreadPoolExecutor(...)等
+func isJavaExecutorCreation(x java.Expr) bool {
+	switch x := javaUnparen(x).(type) {
+	case *java.NewExpr:
+		return executorTypes[javaSimpleName(x.Type.Name)]
+	case *java.CallExpr:
+		if id, ok := x.X.(*java.Identifier); ok && id.Name == "Executors" {
+			return strings.HasPrefix(x.Name.Name, "new")
+		}
+		if sel, ok := x.X.(*java.SelectorExpr); ok && sel.Name.Name == "Executors" {
+			return strings.HasPrefix(x.Name.Name, "new")
+		}
+	}
+	return false
+}
+
+// javaLockName 返回锁对象表达式的源码文本，this.lock与lock视为同一个锁
+func javaLockName(file *SourceFile, x java.Expr) string {
+	return strings.TrimPrefix(javaNodeText(file, x), "this.")
+}
+
+// javaNodeText 返回节点的源码文本
+func javaNodeText(file *SourceFile, n java.Node) string {
+	return file.Content[n.Pos().Offset:n.End().Offset]
+}
@@ -0,0 +1,58 @@
+package rules
+
+import "testing"
+
+func TestJavaResourceRules(t *testing.T) {
+	runJavaCases(t, []ruleCase{
+		{name: "未关闭的流", rule: &JavaUnclosedResourceRule{}, want: 1,
+			src: inMethod(`InputStream in = new FileInputStream("a.txt");
+        in.read();`)},
+		{name: "try-with-resources", rule: &JavaUnclosedResourceRule{}, want: 0,
+			src: inMethod(`try (InputStream in = new FileInputStream("a.txt")) {
+            in.read();
+        }`)},
+		{name: "finally中关闭", rule: &JavaUnclosedResourceRule{}, want: 0,
+			src: inMethod(`InputStream in = new FileInputStream("a.txt");
+        try {
+            in.read();
+        } finally {
+            in.close();
+        }`)},
+		{name: "返回给调用方", rule: &JavaUnclosedResourceRule{}, want: 0,
+			src: "class A {\n    InputStream open() throws IOException {\n        InputStream in = new FileInputStream(\"a.txt\");\n        return in;\n    }\n}"},
+
+		{name: "线程池未关闭", rule: &JavaExecutorShutdownRule{}, want: 1,
+			src: inMethod(`ExecutorService pool = Executors.newFixedThreadPool(4);
+        pool.submit(task);`)},
+		{name: "线程池已关闭", rule: &JavaExecutorShutdownRule{}, want: 0,
+			src: inMethod(`ExecutorService pool = Executors.newFixedThreadPool(4);
+        pool.submit(task);
+        pool.shutdown();`)},
+
+		{name: "ThreadLocal未remove", rule: &JavaThreadLocalRemoveRule{}, want: 1, src: `
+class Context {
+    private static final ThreadLocal<String> USER = new ThreadLocal<>();
+    static void set(String u) { USER.set(u); }
+}`},
+		{name: "ThreadLocal已remove", rule: &JavaThreadLocalRemoveRule{}, want: 0, src: `
+class Context {
+    private static final ThreadLocal<String> USER = new ThreadLocal<>();
+    static void set(String u) { USER.set(u); }
+    static void clear() { USER.remove(); }
+}`},
+
+		{name: "lock后未在finally中unlock", rule: &JavaLockUnlockRule{}, want: 1, src: `
+class Counter {
+    private final Lock lock = new ReentrantLock();
+    void inc() { lock.lock(); count++; lock.unlock(); }
+}`},
+		{name: "finally中unlock", rule: &JavaLockUnlockRule{}, want: 0, src: `
+class Counter {
+    private final Lock lock = new ReentrantLock();
+    void inc() {
+        lock.lock();
+        try { count++; } finally { lock.unlock(); }
+    }
+}`},
+	})
+}
@@ -1,80 +1,124 @@
 package rules
 
 import (
-	"regexp"
-	"strings"
+	"context"
+	"fmt"
 
+	"github.com/liujinliang/lang-checker/internal/complexity"
+	"github.com/liujinliang/lang-checker/internal/java"
 	"github.com/liujinliang/lang-checker/internal/models"
 )
 
 // JavaFunctionLengthRule Java函数长度规则
-type JavaFunctionLengthRule struct{}
+type JavaFunctionLengthRule struct {
+	MaxLines int
+}
 
-func (r *JavaFunctionLengthRule) CheckJava(content string) []models.Issue {
+func (r *JavaFunctionLengthRule) Check(ctx context.Context, file *SourceFile) []models.Issue {
 	var issues []models.Issue
-	lines := strings.Split(content, "\n")
-	methodPattern := regexp.MustCompile(`(?m)^\s*(?:public|private|protected)?\s*(?:static)?\s*(?:\w+\s+)*\w+\s*\([^)]*\)`)
-
-	for i, line := range lines {
-		if methodPattern.MatchString(line) {
-			braceCount := 0
-			methodStart := i + 1
-			methodEnd := i + 1
-
-			for j := i; j < len(lines); j++ {
-				braceCount += strings.Count(lines[j], "{")
-				braceCount -= strings.Count(lines[j], "}")
-				if braceCount == 0 && j > i {
-					methodEnd = j + 1
-					break
-				}
-			}
-
-			if methodEnd-methodStart > 50 {
-				issues = append(issues, models.Issue{
-					Line:     methodStart,
-					Message:  "方法过长，建议拆分",
-					Severity: "warning",
-				})
-			}
+	maxLines := orDefault(r.MaxLines, DefaultThresholds().FunctionLength)
+	for _, m := range java.Methods(javaFile(file)) {
+		if ctx.Err() != nil {
+			return issues
+		}
+		if m.Body != nil && m.Body.End().Line-m.Name.Pos().Line > maxLines {
+			issue := javaMethodHeader(m)
+			issue.Message = "方法过长，建议拆分"
+			issues = append(issues, issue)
 		}
 	}
 	return issues
 }
 
-func (r *JavaFunctionLengthRule) Name() string {
-	return "JavaFunctionLength"
+func (r *JavaFunctionLengthRule) Meta() Metadata {
+	return Metadata{
+		ID:          "java/function-length",
+		Name:        "JavaFunctionLength",
+		Category:    CategorySize,
+		Severity:    models.SeverityWarning,
+		Languages:   []models.Language{models.Java},
+		Tags:        []string{"maintainability"},
+		Description: "方法体超过阈值行数（默认50行）时报告，过长的方法难以理解和测试",
+	}
 }
 
 // JavaNamingConventionRule Java命名规范规则
 type JavaNamingConventionRule struct{}
 
-func (r *JavaNamingConventionRule) CheckJava(content string) []models.Issue {
+func (r *JavaNamingConventionRule) Check(ctx context.Context, file *SourceFile) []models.Issue {
 	var issues []models.Issue
-	lines := strings.Split(content, "\n")
-	methodPattern := regexp.MustCompile(`(?m)^\s*(?:public|private|protected)?\s*(?:static)?\s*(?:\w+\s+)*(\w+)\s*\([^)]*\)`)
+	for _, m := range java.Methods(javaFile(file)) {
+		if ctx.Err() != nil {
+			return issues
+		}
+		// 构造器与类同名，不适用方法命名规范
+		if m.Constructor || isValidJavaMethodName(m.Name.Name) {
+			continue
+		}
+		issue := javaIssueAt(m.Name.Pos(), m.Name.End())
+		issue.Message = "方法命名不符合规范"
+		issues = append(issues, issue)
+	}
+	return issues
+}
 
-	for i, line := range lines {
-		matches := methodPattern.FindStringSubmatch(line)
-		if len(matches) > 1 {
-			methodName := matches[1]
-			if !isValidJavaMethodName(methodName) {
-				issues = append(issues, models.Issue{
-					Line:     i + 1,
-					Message:  "方法命名不符合规范",
-					Severity: "warning",
-				})
-			}
+func (r *JavaNamingConventionRule) Meta() Metadata {
+	return Metadata{
+		ID:          "java/naming-convention",
+		Name:        "JavaNamingConvention",
+		Category:    CategoryNaming,
+		Severity:    models.SeverityWarning,
+		Languages:   []models.Language{models.Java},
+		Tags:        []string{"style"},
+		Description: "检查方法命名是否符合小驼峰规范",
+	}
+}
+
+// JavaCognitiveComplexityRule Java认知复杂度规则
+type JavaCognitiveComplexityRule struct {
+	MaxComplexity int
+}
+
+func (r *JavaCognitiveComplexityRule) Check(ctx context.Context, file *SourceFile) []models.Issue {
+	var issues []models.Issue
+	maxComplexity := orDefault(r.MaxComplexity, DefaultThresholds().CognitiveComplexity)
+	for _, m := range java.Methods(javaFile(file)) {
+		if ctx.Err() != nil {
+			return issues
+		}
+		if m.Body == nil {
+			continue
+		}
+		if c := complexity.JavaCognitiveComplexity(m.Body); c > maxComplexity {
+			issue := javaMethodHeader(m)
+			issue.Message = fmt.Sprintf("方法%s的认知复杂度为%d，超过阈值%d", m.Name.Name, c, maxComplexity)
+			issue.Suggestion = "减少嵌套层级，使用卫语句或将分支提取为独立方法"
+			issues = append(issues, issue)
 		}
 	}
 	return issues
 }
 
-func (r *JavaNamingConventionRule) Name() string {
-	return "JavaNamingConvention"
+func (r *JavaCognitiveComplexityRule) Meta() Metadata {
+	return Metadata{
+		ID:          "java/cognitive-complexity",
+		Name:        "JavaCognitiveComplexity",
+		Category:    CategoryComplexity,
+		Severity:    models.SeverityWarning,
+		Languages:   []models.Language{models.Java},
+		Tags:        []string{"maintainability"},
+		Description: "方法认知复杂度超过阈值（默认15）时报告，嵌套的分支和混合的逻辑运算符会增加阅读难度",
+	}
 }
 
 // 辅助函数
+func javaFile(file *SourceFile) *java.File {
+	if file.JavaAST != nil {
+		return file.JavaAST
+	}
+	return java.Parse(file.Content)
+}
+
 func isValidJavaMethodName(name string) bool {
 	// Java方法命名规范：小驼峰
 	if len(name) == 0 {
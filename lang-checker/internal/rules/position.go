@@ -0,0 +1,63 @@
+package rules
+
+import (
+	"fmt"
+	"go/token"
+	"strings"
+
+	"github.com/liujinliang/lang-checker/internal/java"
+	"github.com/liujinliang/lang-checker/internal/models"
+)
+
+// DefaultSnippetContext 代码片段中问题范围前后默认附带的行数
+const DefaultSnippetContext = 2
+
+// goIssueAt 返回定位到[from, to)范围的问题，结束位置与token.Pos的End一致，指向范围后的第一个字符
+func goIssueAt(fset *token.FileSet, from, to token.Pos) models.Issue {
+	start, end := fset.Position(from), fset.Position(to)
+	return models.Issue{
+		Line:      start.Line,
+		Column:    start.Column,
+		EndLine:   end.Line,
+		EndColumn: end.Column,
+	}
+}
+
+// javaIssueAt 返回定位到[from, to)范围的问题
+func javaIssueAt(from, to java.Pos) models.Issue {
+	return models.Issue{
+		Line:      from.Line,
+		Column:    from.Column,
+		EndLine:   to.Line,
+		EndColumn: to.Column,
+	}
+}
+
+// javaMethodHeader 返回定位到方法名至参数列表')'的问题，紧凑构造器只定位方法名
+func javaMethodHeader(m *java.MethodDecl) models.Issue {
+	return javaIssueAt(m.Name.Pos(), m.ParamsEnd)
+}
+
+// Snippet 截取startLine到endLine的源码及前后context行，每行带行号，问题范围内的行以'>'标记。
+// lines为按'\n'切分的文件内容，行号超出文件范围时返回空字符串
+func Snippet(lines []string, startLine, endLine, context int) string {
+	if startLine < 1 || startLine > len(lines) {
+		return ""
+	}
+	endLine = min(max(endLine, startLine), len(lines))
+	context = max(context, 0)
+	from, to := max(startLine-context, 1), min(endLine+context, len(lines))
+
+	width := len(fmt.Sprint(to))
+	var b strings.Builder
+	for n := from; n <= to; n++ {
+		marker := ' '
+		if n >= startLine && n <= endLine {
+			marker = '>'
+		}
+		row := fmt.Sprintf("%c %*d | %s", marker, width, n, strings.TrimRight(lines[n-1], "\r"))
+		b.WriteString(strings.TrimRight(row, " "))
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
@@ -0,0 +1,29 @@
+package rules
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSnippet(t *testing.T) {
+	lines := strings.Split("a\nbb\r\nccc\nd\ne\n", "\n")
+	tests := []struct {
+		name                string
+		start, end, context int
+		want                string
+	}{
+		{"单行带上下文", 3, 3, 1, "  2 | bb\n> 3 | ccc\n  4 | d\n"},
+		{"多行", 2, 3, 0, "> 2 | bb\n> 3 | ccc\n"},
+		{"文件开头", 1, 1, 2, "> 1 | a\n  2 | bb\n  3 | ccc\n"},
+		{"结束行早于开始行", 4, 0, 0, "> 4 | d\n"},
+		{"行号超出范围", 10, 10, 2, ""},
+		{"行号为0", 0, 0, 2, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Snippet(lines, tt.start, tt.end, tt.context); got != tt.want {
+				t.Errorf("Snippet() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
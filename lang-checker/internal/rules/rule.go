@@ -0,0 +1,296 @@
+package rules
+
+import (
+	"context"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"github.com/liujinliang/lang-checker/internal/java"
+	"github.com/liujinliang/lang-checker/internal/models"
+)
+
+// 规则分类
+const (
+	CategorySize          = "size"
+	CategoryComplexity    = "complexity"
+	CategoryNaming        = "naming"
+	CategoryErrorHandling = "error-handling"
+	CategoryDesign        = "design"
+)
+
+// Metadata 规则元数据
+type Metadata struct {
+	ID          string            `json:"id"`
+	Name        string            `json:"name"`
+	Category    string            `json:"category"`
+	Severity    string            `json:"severity"`
+	Languages   []models.Language `json:"languages"`
+	Tags        []string          `json:"tags,omitempty"`
+	Description string            `json:"description"`
+	DocURL      string            `json:"docUrl,omitempty"`
+}
+
+// Supports 判断规则是否适用于指定语言
+func (m Metadata) Supports(lang models.Language) bool {
+	for _, l := range m.Languages {
+		if l == lang {
+			return true
+		}
+	}
+	return false
+}
+
+// SourceFile 规则检查的输入文件
+type SourceFile struct {
+	Path     string
+	Language models.Language
+	Content  string
+
+	// Go语言专用，其他语言为nil
+	Fset *token.FileSet
+	AST  *ast.File
+
+	// Go包模式下的类型信息，单文件分析时为nil，依赖类型的规则此时应跳过
+	TypesInfo *types.Info
+	TypesPkg  *types.Package
+
+	// Java语言专用，分析器已解析的语法树，为nil时规则自行解析
+	JavaAST *java.File
+	// Java语言专用，分析器已计算的类指标，与java.TypeDecls(JavaAST)一一对应，为nil时由类级规则计算一次后缓存
+	JavaClasses []models.ClassMetrics
+
+	// IsTest 是否为测试代码，由分析器按文件路径判断
+	IsTest bool
+
+	// javaFields 各类型声明直接声明的字段，由javaFields首次使用时构建
+	javaFields map[*java.TypeDecl]map[string]javaVar
+}
+
+// Rule 统一规则接口，Check应在耗时循环中检查ctx并在取消后尽快返回
+type Rule interface {
+	Meta() Metadata
+	Check(ctx context.Context, file *SourceFile) []models.Issue
+}
+
+// Thresholds 规则阈值配置
+type Thresholds struct {
+	FunctionLength       int `json:"functionLength"`
+	CyclomaticComplexity int `json:"cyclomaticComplexity"`
+	NestingDepth         int `json:"nestingDepth"`
+	CognitiveComplexity  int `json:"cognitiveComplexity"`
+	// DuplicateTokens 重复代码检测的最小词法单元数
+	DuplicateTokens int `json:"duplicateTokens"`
+	// GodClassWMC/GodClassCBO 类的方法加权和与耦合度同时超过阈值时视为上帝类
+	GodClassWMC int `json:"godClassWmc"`
+	GodClassCBO int `json:"godClassCbo"`
+	// MaxLCOM 类的LCOM4（互不相关的方法组数）上限
+	MaxLCOM int `json:"maxLcom"`
+}
+
+// DefaultThresholds 返回默认阈值
+func DefaultThresholds() Thresholds {
+	return Thresholds{
+		FunctionLength:       50,
+		CyclomaticComplexity: 10,
+		NestingDepth:         4,
+		CognitiveComplexity:  15,
+		DuplicateTokens:      70,
+		GodClassWMC:          47,
+		GodClassCBO:          14,
+		MaxLCOM:              2,
+	}
+}
+
+// DefaultTestThresholds 返回测试代码的默认阈值，测试中的表驱动用例和准备代码通常更长
+func DefaultTestThresholds() Thresholds {
+	return Thresholds{
+		FunctionLength:       100,
+		CyclomaticComplexity: 15,
+		NestingDepth:         4,
+		CognitiveComplexity:  25,
+		DuplicateTokens:      70,
+		GodClassWMC:          47,
+		GodClassCBO:          14,
+		MaxLCOM:              2,
+	}
+}
+
+// WithDefaults 用默认值补全未设置（<=0）的阈值
+func (t Thresholds) WithDefaults() Thresholds {
+	return t.withFallback(DefaultThresholds())
+}
+
+// WithTestDefaults 用测试代码的默认值补全未设置（<=0）的阈值
+func (t Thresholds) WithTestDefaults() Thresholds {
+	return t.withFallback(DefaultTestThresholds())
+}
+
+func (t Thresholds) withFallback(d Thresholds) Thresholds {
+	if t.FunctionLength <= 0 {
+		t.FunctionLength = d.FunctionLength
+	}
+	if t.CyclomaticComplexity <= 0 {
+		t.CyclomaticComplexity = d.CyclomaticComplexity
+	}
+	if t.NestingDepth <= 0 {
+		t.NestingDepth = d.NestingDepth
+	}
+	if t.CognitiveComplexity <= 0 {
+		t.CognitiveComplexity = d.CognitiveComplexity
+	}
+	if t.DuplicateTokens <= 0 {
+		t.DuplicateTokens = d.DuplicateTokens
+	}
+	if t.GodClassWMC <= 0 {
+		t.GodClassWMC = d.GodClassWMC
+	}
+	if t.GodClassCBO <= 0 {
+		t.GodClassCBO = d.GodClassCBO
+	}
+	if t.MaxLCOM <= 0 {
+		t.MaxLCOM = d.MaxLCOM
+	}
+	return t
+}
+
+// DefaultRules 返回使用默认阈值的所有内置规则
+func DefaultRules() []Rule {
+	return BuiltinRules(DefaultThresholds())
+}
+
+// BuiltinRules 返回使用指定阈值的所有内置规则
+func BuiltinRules(th Thresholds) []Rule {
+	th = th.WithDefaults()
+	return []Rule{
+		&FunctionLengthRule{MaxLines: th.FunctionLength},
+		&CyclomaticComplexityRule{MaxComplexity: th.CyclomaticComplexity},
+		&CognitiveComplexityRule{MaxComplexity: th.CognitiveComplexity},
+		&NamingConventionRule{},
+		&UncheckedErrorRule{},
+		&JavaFunctionLengthRule{MaxLines: th.FunctionLength},
+		&JavaNamingConventionRule{},
+		&JavaCognitiveComplexityRule{MaxComplexity: th.CognitiveComplexity},
+		&JavaGodClassRule{MaxWMC: th.GodClassWMC, MaxCBO: th.GodClassCBO},
+		&JavaLowCohesionRule{MaxLCOM: th.MaxLCOM},
+		&JavaControllerRepositoryRule{},
+		&JavaControllerEntityRule{},
+		&JavaControllerTransactionalRule{},
+		&JavaRepositoryServiceRule{},
+		&JavaEmptyCatchRule{},
+		&JavaBroadCatchRule{},
+		&JavaPrintStackTraceRule{},
+		&JavaRethrowWithoutCauseRule{},
+		&JavaSwallowedInterruptRule{},
+		&JavaThrowInFinallyRule{},
+		&JavaReturnInFinallyRule{},
+		&JavaUnclosedResourceRule{},
+		&JavaExecutorShutdownRule{},
+		&JavaThreadLocalRemoveRule{},
+		&JavaLockUnlockRule{},
+		&JavaStaticFormatRule{},
+		&JavaDoubleCheckedLockingRule{},
+		&JavaStaticCollectionMutationRule{},
+		&JavaSyncOnBoxedRule{},
+		&JavaMutableBeanFieldRule{},
+		&JavaConcurrentCheckThenActRule{},
+		&JavaSystemOutRule{},
+		&JavaLogConcatRule{},
+		&JavaLogWithoutThrowableRule{},
+		&JavaLoggerDeclarationRule{},
+		&JavaLogSensitiveDataRule{},
+	}
+}
+
+// Filter 按启用/禁用列表过滤规则，enabled为空表示全部启用
+func Filter(rs []Rule, enabled, disabled []string) []Rule {
+	enabledSet := make(map[string]bool, len(enabled))
+	for _, id := range enabled {
+		enabledSet[id] = true
+	}
+	disabledSet := make(map[string]bool, len(disabled))
+	for _, id := range disabled {
+		disabledSet[id] = true
+	}
+
+	var result []Rule
+	for _, r := range rs {
+		id := r.Meta().ID
+		if len(enabledSet) > 0 && !enabledSet[id] {
+			continue
+		}
+		if disabledSet[id] {
+			continue
+		}
+		result = append(result, r)
+	}
+	return result
+}
+
+// Engine 规则引擎，负责按语言分发规则并补全问题元数据
+type Engine struct {
+	rules          []Rule
+	snippetContext int
+}
+
+// NewEngine 创建规则引擎，代码片段默认附带DefaultSnippetContext行上下文
+func NewEngine(rules ...Rule) *Engine {
+	return &Engine{rules: rules, snippetContext: DefaultSnippetContext}
+}
+
+// SetSnippetContext 设置代码片段中问题范围前后附带的行数，<0表示不生成代码片段
+func (e *Engine) SetSnippetContext(n int) {
+	e.snippetContext = n
+}
+
+// Rules 返回引擎中注册的规则
+func (e *Engine) Rules() []Rule {
+	return e.rules
+}
+
+// Rule 按ID查找规则
+func (e *Engine) Rule(id string) (Rule, bool) {
+	for _, r := range e.rules {
+		if r.Meta().ID == id {
+			return r, true
+		}
+	}
+	return nil, false
+}
+
+// Run 对文件执行所有适用规则，并为每个问题填充规则ID、文件、分类和代码片段
+func (e *Engine) Run(ctx context.Context, file *SourceFile) ([]models.Issue, error) {
+	var issues []models.Issue
+	// lines 文件按行切分的内容，首次生成代码片段时切分，各问题共用
+	var lines []string
+	for _, r := range e.rules {
+		if err := ctx.Err(); err != nil {
+			return issues, err
+		}
+		meta := r.Meta()
+		if !meta.Supports(file.Language) {
+			continue
+		}
+		for _, issue := range r.Check(ctx, file) {
+			issue.RuleID = meta.ID
+			issue.FilePath = file.Path
+			issue.Category = meta.Category
+			issue.DocURL = meta.DocURL
+			if issue.Severity == "" {
+				issue.Severity = meta.Severity
+			}
+			if issue.EndLine == 0 {
+				issue.EndLine, issue.EndColumn = issue.Line, issue.Column
+			}
+			if issue.CodeSnippet == "" && e.snippetContext >= 0 {
+				if lines == nil {
+					lines = strings.Split(file.Content, "\n")
+				}
+				issue.CodeSnippet = Snippet(lines, issue.Line, issue.EndLine, e.snippetContext)
+			}
+			issues = append(issues, issue)
+		}
+	}
+	return issues, ctx.Err()
+}
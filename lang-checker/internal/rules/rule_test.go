@@ -0,0 +1,58 @@
+package rules
+
+import (
+	"context"
+	"testing"
+
+	"github.com/liujinliang/lang-checker/internal/models"
+)
+
+func TestEngineRun(t *testing.T) {
+	src := "class A {\n    void f() {\n        System.out.println(1);\n    }\n}\n"
+	engine := NewEngine(Filter(DefaultRules(), []string{"java/system-out", "go/naming-convention"}, nil)...)
+	engine.SetSnippetContext(0)
+	issues, err := engine.Run(context.Background(), &SourceFile{Path: "A.java", Language: models.Java, Content: src})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 1 {
+		t.Fatalf("%d个问题, want 1: %+v", len(issues), issues)
+	}
+	issue := issues[0]
+	if issue.RuleID != "java/system-out" || issue.FilePath != "A.java" || issue.Category != CategoryDesign ||
+		issue.Severity != models.SeverityWarning || issue.DocURL == "" {
+		t.Errorf("问题元数据未填充: %+v", issue)
+	}
+	if issue.Line != 3 || issue.EndLine != 3 || issue.CodeSnippet != "> 3 |         System.out.println(1);\n" {
+		t.Errorf("位置或代码片段错误: %+v", issue)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := engine.Run(ctx, &SourceFile{Path: "A.java", Language: models.Java, Content: src}); err != context.Canceled {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+}
+
+// ruleCase 单个规则对一段源码应报告的问题数
+type ruleCase struct {
+	name string
+	rule Rule
+	src  string
+	test bool
+	want int
+}
+
+// runJavaCases 对每个用例的Java源码执行规则并核对问题数
+func runJavaCases(t *testing.T, cases []ruleCase) {
+	t.Helper()
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			file := &SourceFile{Path: "Sample.java", Language: models.Java, Content: tc.src, IsTest: tc.test}
+			issues := tc.rule.Check(context.Background(), file)
+			if len(issues) != tc.want {
+				t.Errorf("%s: %d个问题, want %d: %+v", tc.rule.Meta().ID, len(issues), tc.want, issues)
+			}
+		})
+	}
+}
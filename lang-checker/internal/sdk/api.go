@@ -0,0 +1,563 @@
+// Package sdk 从JAR包的字节码中提取公开API，输出按包分组的Markdown文档、JSON以及数据对象（DTO）的JSON Schema
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/liujinliang/lang-checker/internal/classfile"
+)
+
+// API JAR包的公开API
+type API struct {
+	JarInfo  JarInfo   `json:"jarInfo"`
+	Packages []Package `json:"packages"`
+	// Errors 无法解析而跳过的class文件
+	Errors []string `json:"errors,omitempty"`
+}
+
+// JarInfo JAR包的基本信息，版本和Maven坐标取自MANIFEST.MF和META-INF/maven下的pom.properties
+type JarInfo struct {
+	Name       string `json:"name"`
+	Title      string `json:"title,omitempty"`
+	Version    string `json:"version,omitempty"`
+	GroupID    string `json:"groupId,omitempty"`
+	ArtifactID string `json:"artifactId,omitempty"`
+}
+
+// Package 一个包中的公开类型，按全限定名排序
+type Package struct {
+	PackageName string  `json:"packageName"`
+	Classes     []Class `json:"classes"`
+}
+
+// Class 公开的类型。成员只包含public和protected的，按class文件中的声明顺序排列
+type Class struct {
+	// ClassName 全限定名，内部类为 pkg.Outer.Inner
+	ClassName   string `json:"className"`
+	PackageName string `json:"packageName"`
+	// ClassType class、interface、enum、record或annotation
+	ClassType     string   `json:"classType"`
+	Modifiers     []string `json:"modifiers,omitempty"`
+	TypeParams    string   `json:"typeParameters,omitempty"`
+	SuperClass    string   `json:"superClass,omitempty"`
+	Interfaces    []string `json:"interfaces,omitempty"`
+	Annotations   []string `json:"annotations,omitempty"`
+	EnumConstants []string `json:"enumConstants,omitempty"`
+	Constructors  []Method `json:"constructors,omitempty"`
+	Methods       []Method `json:"methods,omitempty"`
+	Fields        []Field  `json:"fields,omitempty"`
+	// DTO 是否为数据对象：record，或只有属性访问器的具体类
+	DTO bool `json:"dto,omitempty"`
+	// Properties 数据对象的属性（含继承的），非数据对象为空
+	Properties []Property `json:"properties,omitempty"`
+
+	dtoCandidate bool
+}
+
+// Method 方法或构造器
+type Method struct {
+	MethodName string `json:"methodName"`
+	// Signature 源码形式的完整声明，如 public <T> java.util.List<T> find(java.lang.String name) throws java.io.IOException
+	Signature  string      `json:"signature"`
+	Modifiers  []string    `json:"modifiers,omitempty"`
+	TypeParams string      `json:"typeParameters,omitempty"`
+	ReturnType string      `json:"returnType,omitempty"`
+	Parameters []Parameter `json:"parameters,omitempty"`
+	Exceptions []string    `json:"exceptions,omitempty"`
+	// DefaultValue 注解元素的默认值
+	DefaultValue string   `json:"defaultValue,omitempty"`
+	Annotations  []string `json:"annotations,omitempty"`
+}
+
+// Parameter 方法参数，class文件没有保留参数名时按arg0、arg1命名
+type Parameter struct {
+	Name        string   `json:"name"`
+	Type        string   `json:"type"`
+	Annotations []string `json:"annotations,omitempty"`
+}
+
+// Field 字段
+type Field struct {
+	FieldName string   `json:"fieldName"`
+	Type      string   `json:"type"`
+	Modifiers []string `json:"modifiers,omitempty"`
+	// DefaultValue 编译期常量的值
+	DefaultValue string   `json:"defaultValue,omitempty"`
+	Annotations  []string `json:"annotations,omitempty"`
+}
+
+// Property 数据对象的属性，来自record组件、getter/setter或public字段
+type Property struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	// Annotations 字段、getter和setter上的注解
+	Annotations []string `json:"annotations,omitempty"`
+
+	annotations []*classfile.Annotation
+}
+
+// Extract 解析JAR包中的class文件，提取公开类型及其public和protected成员。
+// maxSize为单个class文件的最大字节数（<=0不限制），无法解析的class文件记录在API.Errors中
+func Extract(ctx context.Context, path string, maxSize int64) (*API, error) {
+	api := &API{JarInfo: readJarInfo(path)}
+	var classes []*classfile.ClassFile
+	err := classfile.WalkJar(ctx, path, maxSize, func(e classfile.Entry) error {
+		if e.Err != nil {
+			api.Errors = append(api.Errors, fmt.Sprintf("%s: %v", e.Name, e.Err))
+			return nil
+		}
+		if !e.Class.Is(classfile.AccModule) {
+			classes = append(classes, e.Class)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	byName := make(map[string]*classfile.ClassFile, len(classes))
+	for _, c := range classes {
+		byName[c.Name] = c
+	}
+	packages := make(map[string]*Package)
+	for _, c := range classes {
+		if !accessible(c, byName) {
+			continue
+		}
+		class := extractClass(c)
+		p := packages[class.PackageName]
+		if p == nil {
+			p = &Package{PackageName: class.PackageName}
+			packages[class.PackageName] = p
+		}
+		p.Classes = append(p.Classes, class)
+	}
+
+	api.Packages = sortedPackages(packages)
+	resolveDTOs(api.Classes())
+	return api, nil
+}
+
+// sortedPackages 按包名排列，包内的类型按全限定名排列
+func sortedPackages(packages map[string]*Package) []Package {
+	list := make([]Package, 0, len(packages))
+	for _, p := range packages {
+		sort.Slice(p.Classes, func(i, j int) bool { return p.Classes[i].ClassName < p.Classes[j].ClassName })
+		list = append(list, *p)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].PackageName < list[j].PackageName })
+	return list
+}
+
+// Classes 返回所有包中的类型
+func (api *API) Classes() []*Class {
+	var list []*Class
+	for i := range api.Packages {
+		for j := range api.Packages[i].Classes {
+			list = append(list, &api.Packages[i].Classes[j])
+		}
+	}
+	return list
+}
+
+// resolveDTOs 确定数据对象：父类只能是Object或其他数据对象（可以是抽象类），异常类和继承了行为的子类不算；
+// 父类的属性并入子类，排在子类自身的属性之前。抽象类本身不标记为数据对象
+func resolveDTOs(classes []*Class) {
+	byName := make(map[string]*Class, len(classes))
+	for _, c := range classes {
+		byName[c.ClassName] = c
+	}
+	type state struct {
+		props []Property
+		ok    bool
+	}
+	resolved := make(map[*Class]*state)
+	var resolve func(c *Class) *state
+	resolve = func(c *Class) *state {
+		if s, ok := resolved[c]; ok {
+			return s
+		}
+		s := &state{}
+		// 先登记，继承关系有环时按非数据对象处理
+		resolved[c] = s
+		if !c.dtoCandidate {
+			return s
+		}
+		if c.SuperClass == "" {
+			s.props, s.ok = c.Properties, true
+			return s
+		}
+		base, _ := splitTypeArgs(c.SuperClass)
+		if parent, found := byName[base]; found {
+			if ps := resolve(parent); ps.ok {
+				s.props, s.ok = mergeProperties(ps.props, c.Properties), true
+			}
+		}
+		return s
+	}
+	for _, c := range classes {
+		s := resolve(c)
+		c.DTO = s.ok && len(s.props) > 0 && !slices.Contains(c.Modifiers, "abstract")
+		c.Properties = nil
+		if c.DTO {
+			c.Properties = s.props
+		}
+	}
+}
+
+// mergeProperties 合并父类和子类的属性，子类重新声明的同名属性覆盖父类的
+func mergeProperties(inherited, own []Property) []Property {
+	declared := make(map[string]bool, len(own))
+	for _, p := range own {
+		declared[p.Name] = true
+	}
+	var merged []Property
+	for _, p := range inherited {
+		if !declared[p.Name] {
+			merged = append(merged, p)
+		}
+	}
+	return append(merged, own...)
+}
+
+// accessible 判断类型在包外是否可见：自身为public（内部类可以是protected），且外层类型均可见。
+// 匿名类、局部类和合成类不可见
+func accessible(c *classfile.ClassFile, byName map[string]*classfile.ClassFile) bool {
+	seen := make(map[string]bool)
+	for c != nil && !seen[c.Name] {
+		seen[c.Name] = true
+		flags := c.AccessFlags
+		ic, nested := c.InnerClasses[c.Name]
+		if nested {
+			if ic.Outer == "" || ic.Name == "" {
+				return false
+			}
+			flags = ic.AccessFlags
+		}
+		if flags&classfile.AccSynthetic != 0 || flags&(classfile.AccPublic|classfile.AccProtected) == 0 {
+			return false
+		}
+		if !nested {
+			return true
+		}
+		outer, ok := byName[ic.Outer]
+		if !ok {
+			// 外层类型不在JAR包中（如拆分打包），按自身的可见性判断
+			return true
+		}
+		c = outer
+	}
+	return true
+}
+
+func extractClass(c *classfile.ClassFile) Class {
+	flags := c.AccessFlags
+	ic, nested := c.InnerClasses[c.Name]
+	if nested {
+		flags = ic.AccessFlags
+	}
+	class := Class{
+		ClassName:   classfile.JavaName(c.Name),
+		PackageName: classfile.JavaName(c.Package()),
+		ClassType:   classType(c),
+		Modifiers:   classModifiers(c, flags),
+		Annotations: annotationStrings(c.Annotations),
+	}
+	class.SuperClass, class.Interfaces = supertypes(c)
+	if c.Signature != "" {
+		if ct, err := classfile.ParseClassSignature(c.Signature); err == nil {
+			class.TypeParams = ct.TypeParams
+		}
+	}
+
+	simple := c.Name[strings.LastIndexAny(c.Name, "/$")+1:]
+	if nested {
+		simple = ic.Name
+	}
+	// 非静态内部类的构造器在描述符中多一个外层实例参数
+	var outerParam string
+	if nested && flags&classfile.AccStatic == 0 && !c.Is(classfile.AccInterface) && !c.Is(classfile.AccEnum) && !c.Record {
+		outerParam = classfile.JavaName(ic.Outer)
+	}
+
+	for _, f := range c.Fields {
+		if f.Is(classfile.AccEnum) {
+			class.EnumConstants = append(class.EnumConstants, f.Name)
+			continue
+		}
+		if visibleMember(f) {
+			class.Fields = append(class.Fields, extractField(f))
+		}
+	}
+	for _, m := range c.Methods {
+		if !visibleMember(m) || m.Is(classfile.AccBridge) || m.Name == "<clinit>" {
+			continue
+		}
+		method := extractMethod(c, m, simple, outerParam)
+		if m.Name == "<init>" {
+			class.Constructors = append(class.Constructors, method)
+		} else {
+			class.Methods = append(class.Methods, method)
+		}
+	}
+
+	// 是否为数据对象还取决于父类，由resolveDTOs最终确定
+	class.Properties, class.dtoCandidate = dtoProperties(c)
+	return class
+}
+
+func visibleMember(m *classfile.Member) bool {
+	return !m.Is(classfile.AccSynthetic) && m.Is(classfile.AccPublic|classfile.AccProtected)
+}
+
+func classType(c *classfile.ClassFile) string {
+	if kind := c.Kind(); kind != "@interface" {
+		return kind
+	}
+	return "annotation"
+}
+
+// classModifiers 返回源码中可写出的类修饰符，省略接口的abstract以及枚举和record隐含的final
+func classModifiers(c *classfile.ClassFile, flags uint16) []string {
+	var mods []string
+	add := func(flag uint16, name string) {
+		if flags&flag != 0 {
+			mods = append(mods, name)
+		}
+	}
+	add(classfile.AccPublic, "public")
+	add(classfile.AccProtected, "protected")
+	if !c.Is(classfile.AccInterface) && !c.Is(classfile.AccEnum) {
+		add(classfile.AccAbstract, "abstract")
+	}
+	add(classfile.AccStatic, "static")
+	if !c.Is(classfile.AccEnum) && !c.Record {
+		add(classfile.AccFinal, "final")
+	}
+	return mods
+}
+
+// memberModifiers 返回成员的修饰符，接口中的非抽象实例方法为default
+func memberModifiers(owner *classfile.ClassFile, m *classfile.Member, method bool) []string {
+	var mods []string
+	add := func(flag uint16, name string) {
+		if m.Is(flag) {
+			mods = append(mods, name)
+		}
+	}
+	add(classfile.AccPublic, "public")
+	add(classfile.AccProtected, "protected")
+	interfaceMethod := method && owner.Is(classfile.AccInterface)
+	if !interfaceMethod {
+		add(classfile.AccAbstract, "abstract")
+	} else if !m.Is(classfile.AccAbstract) && !m.Is(classfile.AccStatic) {
+		mods = append(mods, "default")
+	}
+	add(classfile.AccStatic, "static")
+	add(classfile.AccFinal, "final")
+	if method {
+		add(classfile.AccSynchronized, "synchronized")
+		add(classfile.AccNative, "native")
+	} else {
+		add(classfile.AccTransient, "transient")
+		add(classfile.AccVolatile, "volatile")
+	}
+	return mods
+}
+
+// supertypes 返回带泛型实参的父类和接口，省略Object以及枚举、record和注解隐含的父类型
+func supertypes(c *classfile.ClassFile) (string, []string) {
+	super := classfile.JavaName(c.SuperName)
+	interfaces := make([]string, 0, len(c.Interfaces))
+	for _, i := range c.Interfaces {
+		interfaces = append(interfaces, classfile.JavaName(i))
+	}
+	if c.Signature != "" {
+		if ct, err := classfile.ParseClassSignature(c.Signature); err == nil {
+			super, interfaces = ct.Super, ct.Interfaces
+		}
+	}
+	if super == "java.lang.Object" || c.Is(classfile.AccInterface) ||
+		(c.Is(classfile.AccEnum) && strings.HasPrefix(super, "java.lang.Enum")) ||
+		(c.Record && super == "java.lang.Record") {
+		super = ""
+	}
+	if c.Is(classfile.AccAnnotation) {
+		interfaces = nil
+	}
+	if len(interfaces) == 0 {
+		interfaces = nil
+	}
+	return super, interfaces
+}
+
+func extractField(f *classfile.Member) Field {
+	sig := f.Signature
+	if sig == "" {
+		sig = f.Descriptor
+	}
+	t, err := classfile.ParseFieldSignature(sig)
+	if err != nil {
+		t = f.Descriptor
+	}
+	return Field{
+		FieldName:    f.Name,
+		Type:         t,
+		Modifiers:    memberModifiers(nil, f, false),
+		DefaultValue: constantString(f),
+		Annotations:  annotationStrings(f.Annotations),
+	}
+}
+
+// constantString 按源码形式输出编译期常量
+func constantString(f *classfile.Member) string {
+	switch v := f.ConstantValue.(type) {
+	case string:
+		return strconv.Quote(v)
+	case int64:
+		switch f.Descriptor {
+		case "Z":
+			return strconv.FormatBool(v != 0)
+		case "C":
+			return strconv.QuoteRune(rune(v))
+		case "J":
+			return strconv.FormatInt(v, 10) + "L"
+		}
+		return strconv.FormatInt(v, 10)
+	case float64:
+		s := strconv.FormatFloat(v, 'g', -1, 64)
+		if f.Descriptor == "F" {
+			return s + "f"
+		}
+		return s
+	}
+	return ""
+}
+
+func extractMethod(owner *classfile.ClassFile, m *classfile.Member, simpleName, outerParam string) Method {
+	method := Method{
+		MethodName:  m.Name,
+		Modifiers:   memberModifiers(owner, m, true),
+		Annotations: annotationStrings(m.Annotations),
+	}
+	constructor := m.Name == "<init>"
+	if constructor {
+		method.MethodName = simpleName
+	}
+
+	mt := methodType(m)
+	names := m.ParameterNames()
+	annotations := m.ParameterAnnotations
+	params := mt.Params
+	if constructor && outerParam != "" && m.Signature == "" && len(params) > 0 && params[0] == outerParam {
+		params = params[1:]
+	}
+	// 泛型签名和描述符的参数个数可能不同（如内部类构造器），参数名和注解按末尾对齐
+	names = alignTail(names, len(params))
+	annotations = alignTail(annotations, len(params))
+	for i, t := range params {
+		p := Parameter{Name: fmt.Sprintf("arg%d", i), Type: t}
+		if names != nil && names[i] != "" {
+			p.Name = names[i]
+		}
+		if annotations != nil {
+			p.Annotations = annotationStrings(annotations[i])
+		}
+		if i == len(params)-1 && m.Is(classfile.AccVarargs) {
+			p.Type = strings.TrimSuffix(p.Type, "[]") + "..."
+		}
+		method.Parameters = append(method.Parameters, p)
+	}
+
+	method.TypeParams = mt.TypeParams
+	if !constructor {
+		method.ReturnType = mt.Result
+	}
+	method.Exceptions = mt.Throws
+	if len(method.Exceptions) == 0 {
+		for _, e := range m.Exceptions {
+			method.Exceptions = append(method.Exceptions, classfile.JavaName(e))
+		}
+	}
+	if m.AnnotationDefault != nil {
+		method.DefaultValue = m.AnnotationDefault.String()
+	}
+	method.Signature = methodSignature(method)
+	return method
+}
+
+// methodType 解析方法的泛型签名，没有或无法解析时使用描述符
+func methodType(m *classfile.Member) *classfile.MethodType {
+	if m.Signature != "" {
+		if mt, err := classfile.ParseMethodSignature(m.Signature); err == nil {
+			return mt
+		}
+	}
+	mt, err := classfile.ParseMethodSignature(m.Descriptor)
+	if err != nil {
+		return &classfile.MethodType{}
+	}
+	return mt
+}
+
+func alignTail[T any](list []T, n int) []T {
+	if len(list) < n {
+		return nil
+	}
+	return list[len(list)-n:]
+}
+
+// methodSignature 拼出源码形式的方法声明
+func methodSignature(m Method) string {
+	var b strings.Builder
+	for _, mod := range m.Modifiers {
+		b.WriteString(mod + " ")
+	}
+	if m.TypeParams != "" {
+		b.WriteString(m.TypeParams + " ")
+	}
+	if m.ReturnType != "" {
+		b.WriteString(m.ReturnType + " ")
+	}
+	b.WriteString(m.MethodName + "(")
+	for i, p := range m.Parameters {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		for _, a := range p.Annotations {
+			b.WriteString(a + " ")
+		}
+		b.WriteString(p.Type + " " + p.Name)
+	}
+	b.WriteString(")")
+	if len(m.Exceptions) > 0 {
+		b.WriteString(" throws " + strings.Join(m.Exceptions, ", "))
+	}
+	if m.DefaultValue != "" {
+		b.WriteString(" default " + m.DefaultValue)
+	}
+	return b.String()
+}
+
+func annotationStrings(annotations []*classfile.Annotation) []string {
+	var list []string
+	for _, a := range annotations {
+		list = append(list, a.String())
+	}
+	return list
+}
+
+// simpleName 返回全限定名的最后一段
+func simpleName(name string) string {
+	return name[strings.LastIndex(name, ".")+1:]
+}
+
+func jarBaseName(path string) string {
+	return strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+}
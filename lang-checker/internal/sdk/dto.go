@@ -0,0 +1,134 @@
+package sdk
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/liujinliang/lang-checker/internal/classfile"
+)
+
+// objectMethods 数据对象常见的Object方法和Lombok生成的方法，不影响数据对象的判断
+var objectMethods = map[string]bool{
+	"equals": true, "hashCode": true, "toString": true, "canEqual": true,
+}
+
+// dtoProperties 判断类型自身是否符合数据对象的形式并返回其属性：record按组件；类（可以是抽象类）要求所有public实例方法
+// 都是getter/setter（或equals等Object方法），属性来自getter、setter和public实例字段
+func dtoProperties(c *classfile.ClassFile) ([]Property, bool) {
+	if c.Is(classfile.AccInterface) || c.Is(classfile.AccEnum) {
+		return nil, false
+	}
+
+	fields := make(map[string]*classfile.Member)
+	var order []string
+	for _, f := range c.Fields {
+		if !f.Is(classfile.AccStatic) && !f.Is(classfile.AccSynthetic) {
+			fields[f.Name] = f
+			order = append(order, f.Name)
+		}
+	}
+
+	props := make(map[string]*Property)
+	add := func(name, typ string, annotations []*classfile.Annotation) {
+		p := props[name]
+		if p == nil {
+			p = &Property{Name: name, Type: typ}
+			props[name] = p
+			if fields[name] == nil {
+				order = append(order, name)
+			}
+		}
+		p.annotations = append(p.annotations, annotations...)
+	}
+
+	if c.Record {
+		// record的组件即实例字段，同名的访问器上可能有注解
+		for _, name := range order {
+			add(name, fieldType(fields[name]), fields[name].Annotations)
+		}
+		for _, m := range c.Methods {
+			if p := props[m.Name]; p != nil && strings.HasPrefix(m.Descriptor, "()") {
+				p.annotations = append(p.annotations, m.Annotations...)
+			}
+		}
+		return collectProperties(props, order), true
+	}
+
+	for _, m := range c.Methods {
+		if m.Is(classfile.AccStatic) || m.Is(classfile.AccSynthetic) || m.Is(classfile.AccBridge) ||
+			!m.Is(classfile.AccPublic) || m.Name == "<init>" || objectMethods[m.Name] {
+			continue
+		}
+		name, typ, ok := accessorProperty(m)
+		if !ok {
+			return nil, false
+		}
+		add(name, typ, m.Annotations)
+	}
+	for _, name := range order {
+		if f := fields[name]; f != nil && f.Is(classfile.AccPublic) {
+			add(name, fieldType(f), nil)
+		}
+	}
+	// 字段上的注解（如@NotNull、@JsonProperty）同样作用于属性
+	for name, p := range props {
+		if f := fields[name]; f != nil {
+			p.annotations = append(append([]*classfile.Annotation(nil), f.Annotations...), p.annotations...)
+			if p.Type == "" {
+				p.Type = fieldType(f)
+			}
+		}
+	}
+	return collectProperties(props, order), true
+}
+
+// accessorProperty 识别getX()、isX()（返回boolean）和setX(v)形式的访问器，返回属性名和类型
+func accessorProperty(m *classfile.Member) (name, typ string, ok bool) {
+	mt := methodType(m)
+	switch {
+	case strings.HasPrefix(m.Name, "get") && len(mt.Params) == 0 && mt.Result != "void":
+		name, typ = m.Name[3:], mt.Result
+	case strings.HasPrefix(m.Name, "is") && len(mt.Params) == 0 && (mt.Result == "boolean" || mt.Result == "java.lang.Boolean"):
+		name, typ = m.Name[2:], mt.Result
+	case strings.HasPrefix(m.Name, "set") && len(mt.Params) == 1:
+		name, typ = m.Name[3:], mt.Params[0]
+	default:
+		return "", "", false
+	}
+	if name == "" {
+		return "", "", false
+	}
+	return decapitalize(name), typ, true
+}
+
+// decapitalize 按JavaBeans规则把访问器名转为属性名：首字母小写，前两个字母都是大写时保持不变（如URL）
+func decapitalize(name string) string {
+	r := []rune(name)
+	if len(r) > 1 && unicode.IsUpper(r[0]) && unicode.IsUpper(r[1]) {
+		return name
+	}
+	r[0] = unicode.ToLower(r[0])
+	return string(r)
+}
+
+func fieldType(f *classfile.Member) string {
+	sig := f.Signature
+	if sig == "" {
+		sig = f.Descriptor
+	}
+	if t, err := classfile.ParseFieldSignature(sig); err == nil {
+		return t
+	}
+	return f.Descriptor
+}
+
+func collectProperties(props map[string]*Property, order []string) []Property {
+	var list []Property
+	for _, name := range order {
+		if p := props[name]; p != nil {
+			p.Annotations = annotationStrings(p.annotations)
+			list = append(list, *p)
+		}
+	}
+	return list
+}
@@ -0,0 +1,89 @@
+package sdk
+
+import (
+	"archive/zip"
+	"bufio"
+	"io"
+	"path"
+	"strings"
+)
+
+// maxMetadataSize MANIFEST.MF和pom.properties的读取上限
+const maxMetadataSize = 1 << 20
+
+// readJarInfo 读取JAR包的名称、版本和Maven坐标。pom.properties优先；
+// 打包了多个Maven构件时取artifactId与文件名匹配的一个，无法确定时只用MANIFEST.MF
+func readJarInfo(jarPath string) JarInfo {
+	info := JarInfo{Name: jarBaseName(jarPath)}
+	zr, err := zip.OpenReader(jarPath)
+	if err != nil {
+		return info
+	}
+	defer zr.Close()
+
+	var poms []map[string]string
+	for _, f := range zr.File {
+		switch {
+		case f.Name == "META-INF/MANIFEST.MF":
+			manifest := readProperties(f, ':')
+			info.Title = firstNonEmpty(manifest["Implementation-Title"], manifest["Bundle-Name"], manifest["Title"])
+			info.Version = firstNonEmpty(manifest["Implementation-Version"], manifest["Bundle-Version"], manifest["Version"])
+		case strings.HasPrefix(f.Name, "META-INF/maven/") && path.Base(f.Name) == "pom.properties":
+			poms = append(poms, readProperties(f, '='))
+		}
+	}
+
+	var pom map[string]string
+	if len(poms) == 1 {
+		pom = poms[0]
+	}
+	for _, p := range poms {
+		if id := p["artifactId"]; id != "" && strings.HasPrefix(info.Name, id) {
+			pom = p
+		}
+	}
+	if pom != nil {
+		info.GroupID, info.ArtifactID = pom["groupId"], pom["artifactId"]
+		info.Version = firstNonEmpty(pom["version"], info.Version)
+	}
+	return info
+}
+
+// readProperties 读取 键<sep>值 形式的文件，MANIFEST.MF中以空格开头的行是上一行的续行
+func readProperties(f *zip.File, sep byte) map[string]string {
+	props := make(map[string]string)
+	rc, err := f.Open()
+	if err != nil {
+		return props
+	}
+	defer rc.Close()
+
+	var lastKey string
+	scanner := bufio.NewScanner(io.LimitReader(rc, maxMetadataSize))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if sep == ':' && strings.HasPrefix(line, " ") && lastKey != "" {
+			props[lastKey] += line[1:]
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, string(sep))
+		if !ok {
+			continue
+		}
+		lastKey = strings.TrimSpace(key)
+		props[lastKey] = strings.TrimSpace(value)
+	}
+	return props
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
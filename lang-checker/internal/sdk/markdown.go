@@ -0,0 +1,150 @@
+package sdk
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Markdown 按包输出开发者文档：概述、每个类型的声明、构造方法、方法、字段，以及数据对象的属性表
+func Markdown(api *API) string {
+	var b strings.Builder
+	info := api.JarInfo
+	fmt.Fprintf(&b, "# %s SDK文档\n\n", firstNonEmpty(info.Title, info.Name))
+
+	classes := api.Classes()
+	dtos := 0
+	for _, c := range classes {
+		if c.DTO {
+			dtos++
+		}
+	}
+	b.WriteString("## 概述\n\n")
+	fmt.Fprintf(&b, "- **JAR包**: %s\n", info.Name)
+	if info.Version != "" {
+		fmt.Fprintf(&b, "- **版本**: %s\n", info.Version)
+	}
+	if info.GroupID != "" {
+		fmt.Fprintf(&b, "- **Maven坐标**: `%s:%s:%s`\n", info.GroupID, info.ArtifactID, info.Version)
+	}
+	fmt.Fprintf(&b, "- **公开类型**: %d个，分布在%d个包中，其中数据对象%d个\n", len(classes), len(api.Packages), dtos)
+	if info.GroupID != "" {
+		fmt.Fprintf(&b, "\n### 安装\n\n```xml\n<dependency>\n    <groupId>%s</groupId>\n    <artifactId>%s</artifactId>\n    <version>%s</version>\n</dependency>\n```\n",
+			info.GroupID, info.ArtifactID, info.Version)
+	}
+
+	for _, p := range api.Packages {
+		fmt.Fprintf(&b, "\n## 包: %s\n", firstNonEmpty(p.PackageName, "(default)"))
+		for _, c := range p.Classes {
+			writeClass(&b, &c)
+		}
+	}
+
+	if len(api.Errors) > 0 {
+		b.WriteString("\n## 未能解析的class文件\n\n")
+		for _, e := range api.Errors {
+			fmt.Fprintf(&b, "- %s\n", e)
+		}
+	}
+	return b.String()
+}
+
+func writeClass(b *strings.Builder, c *Class) {
+	name := strings.TrimPrefix(c.ClassName, c.PackageName+".")
+	fmt.Fprintf(b, "\n### 类: %s\n\n", name)
+	fmt.Fprintf(b, "**包路径**: `%s`\n\n", c.ClassName)
+	fmt.Fprintf(b, "```java\n%s\n```\n", classDeclaration(c, name))
+
+	if len(c.EnumConstants) > 0 {
+		b.WriteString("\n#### 枚举常量\n\n")
+		for _, e := range c.EnumConstants {
+			fmt.Fprintf(b, "- `%s`\n", e)
+		}
+	}
+	if len(c.Fields) > 0 {
+		b.WriteString("\n#### 字段\n\n")
+		for _, f := range c.Fields {
+			decl := strings.TrimSpace(strings.Join(f.Modifiers, " ") + " " + f.Type + " " + f.FieldName)
+			if f.DefaultValue != "" {
+				decl += " = " + f.DefaultValue
+			}
+			fmt.Fprintf(b, "- `%s`\n", decl)
+			writeAnnotations(b, f.Annotations)
+		}
+	}
+	if len(c.Constructors) > 0 {
+		b.WriteString("\n#### 构造方法\n\n")
+		for _, m := range c.Constructors {
+			writeMethod(b, m)
+		}
+	}
+	if len(c.Methods) > 0 {
+		b.WriteString("\n#### 方法\n\n")
+		for _, m := range c.Methods {
+			writeMethod(b, m)
+		}
+	}
+	if c.DTO {
+		b.WriteString("\n#### 属性（数据对象）\n\n| 属性 | 类型 | 注解 |\n| --- | --- | --- |\n")
+		// 表格单元格中的'|'需要转义
+		escape := strings.NewReplacer("|", "\\|").Replace
+		for _, p := range c.Properties {
+			fmt.Fprintf(b, "| %s | `%s` | %s |\n", p.Name, escape(p.Type), escape(markdownCode(p.Annotations)))
+		}
+	}
+}
+
+func writeMethod(b *strings.Builder, m Method) {
+	fmt.Fprintf(b, "- `%s`\n", m.Signature)
+	writeAnnotations(b, m.Annotations)
+	if len(m.Parameters) > 0 {
+		parts := make([]string, len(m.Parameters))
+		for i, p := range m.Parameters {
+			parts[i] = fmt.Sprintf("`%s` (%s)", p.Name, p.Type)
+		}
+		fmt.Fprintf(b, "  - **参数**: %s\n", strings.Join(parts, ", "))
+	}
+	if m.ReturnType != "" && m.ReturnType != "void" {
+		fmt.Fprintf(b, "  - **返回值**: `%s`\n", m.ReturnType)
+	}
+	if len(m.Exceptions) > 0 {
+		fmt.Fprintf(b, "  - **异常**: %s\n", markdownCode(m.Exceptions))
+	}
+}
+
+func writeAnnotations(b *strings.Builder, annotations []string) {
+	if len(annotations) > 0 {
+		fmt.Fprintf(b, "  - **注解**: %s\n", markdownCode(annotations))
+	}
+}
+
+func markdownCode(items []string) string {
+	parts := make([]string, len(items))
+	for i, s := range items {
+		parts[i] = "`" + s + "`"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// classDeclaration 拼出源码形式的类型声明，如 public final class Foo<T> extends Bar implements Baz
+func classDeclaration(c *Class, name string) string {
+	var parts []string
+	parts = append(parts, c.Annotations...)
+	parts = append(parts, c.Modifiers...)
+	keyword := c.ClassType
+	if keyword == "annotation" {
+		keyword = "@interface"
+	}
+	parts = append(parts, keyword, simpleName(name)+c.TypeParams)
+	if c.SuperClass != "" {
+		parts = append(parts, "extends", c.SuperClass)
+	}
+	if len(c.Interfaces) > 0 {
+		if c.ClassType == "interface" {
+			parts = append(parts, "extends")
+		} else {
+			parts = append(parts, "implements")
+		}
+		parts = append(parts, strings.Join(c.Interfaces, ", "))
+	}
+	return strings.Join(parts, " ")
+}
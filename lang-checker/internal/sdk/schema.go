@@ -0,0 +1,338 @@
+package sdk
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/liujinliang/lang-checker/internal/classfile"
+)
+
+// SchemaDraft 生成的JSON Schema版本
+const SchemaDraft = "http://json-schema.org/draft-07/schema#"
+
+// Schema JSON Schema文档或其中的一个子模式
+type Schema struct {
+	Schema      string   `json:"$schema,omitempty"`
+	Ref         string   `json:"$ref,omitempty"`
+	Title       string   `json:"title,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Type        string   `json:"type,omitempty"`
+	Format      string   `json:"format,omitempty"`
+	Enum        []string `json:"enum,omitempty"`
+
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Definitions          map[string]*Schema `json:"definitions,omitempty"`
+
+	// Bean Validation约束
+	MinLength *int     `json:"minLength,omitempty"`
+	MaxLength *int     `json:"maxLength,omitempty"`
+	MinItems  *int     `json:"minItems,omitempty"`
+	MaxItems  *int     `json:"maxItems,omitempty"`
+	Minimum   *float64 `json:"minimum,omitempty"`
+	Maximum   *float64 `json:"maximum,omitempty"`
+	Pattern   string   `json:"pattern,omitempty"`
+}
+
+// scalarTypes Java类型对应的JSON类型和格式
+var scalarTypes = map[string][2]string{
+	"boolean":                  {"boolean", ""},
+	"java.lang.Boolean":        {"boolean", ""},
+	"byte":                     {"integer", "int32"},
+	"java.lang.Byte":           {"integer", "int32"},
+	"short":                    {"integer", "int32"},
+	"java.lang.Short":          {"integer", "int32"},
+	"int":                      {"integer", "int32"},
+	"java.lang.Integer":        {"integer", "int32"},
+	"long":                     {"integer", "int64"},
+	"java.lang.Long":           {"integer", "int64"},
+	"java.math.BigInteger":     {"integer", ""},
+	"float":                    {"number", "float"},
+	"java.lang.Float":          {"number", "float"},
+	"double":                   {"number", "double"},
+	"java.lang.Double":         {"number", "double"},
+	"java.math.BigDecimal":     {"number", ""},
+	"char":                     {"string", ""},
+	"java.lang.Character":      {"string", ""},
+	"java.lang.String":         {"string", ""},
+	"java.lang.CharSequence":   {"string", ""},
+	"byte[]":                   {"string", "byte"},
+	"java.util.UUID":           {"string", "uuid"},
+	"java.net.URI":             {"string", "uri"},
+	"java.net.URL":             {"string", "uri"},
+	"java.time.LocalDate":      {"string", "date"},
+	"java.time.LocalTime":      {"string", "time"},
+	"java.time.LocalDateTime":  {"string", "date-time"},
+	"java.time.Instant":        {"string", "date-time"},
+	"java.time.OffsetDateTime": {"string", "date-time"},
+	"java.time.ZonedDateTime":  {"string", "date-time"},
+	"java.time.Duration":       {"string", "duration"},
+	"java.util.Date":           {"string", "date-time"},
+	"java.sql.Date":            {"string", "date"},
+	"java.sql.Timestamp":       {"string", "date-time"},
+}
+
+// collectionTypes 按数组处理的集合类型
+var collectionTypes = map[string]bool{
+	"java.util.Collection": true, "java.util.List": true, "java.util.Set": true, "java.util.SortedSet": true,
+	"java.util.ArrayList": true, "java.util.LinkedList": true, "java.util.HashSet": true,
+	"java.util.LinkedHashSet": true, "java.util.TreeSet": true, "java.lang.Iterable": true,
+}
+
+// mapTypes 按对象处理的映射类型，值类型为第二个类型实参
+var mapTypes = map[string]bool{
+	"java.util.Map": true, "java.util.HashMap": true, "java.util.LinkedHashMap": true,
+	"java.util.TreeMap": true, "java.util.SortedMap": true, "java.util.concurrent.ConcurrentHashMap": true,
+}
+
+// requiredAnnotations 表示属性不能为空的注解（按简单名称匹配，兼容javax、jakarta和各类@NonNull）
+var requiredAnnotations = map[string]bool{
+	"NotNull": true, "NonNull": true, "Nonnull": true, "NotBlank": true, "NotEmpty": true,
+}
+
+// JSONSchema 为API中的数据对象生成JSON Schema，每个数据对象和被引用的枚举是definitions中的一项，
+// 以全限定名为键。基本类型属性和带@NotNull等注解的属性列入required，Jackson的@JsonProperty改名、
+// @JsonIgnore忽略，Bean Validation的@Size、@Min、@Max和@Pattern转为对应的约束
+func JSONSchema(api *API) *Schema {
+	classes := make(map[string]*Class)
+	for _, c := range api.Classes() {
+		classes[c.ClassName] = c
+	}
+	g := &schemaGen{classes: classes, defs: make(map[string]*Schema)}
+	for _, c := range api.Classes() {
+		if c.DTO {
+			g.define(c)
+		}
+	}
+	title := api.JarInfo.Name
+	if api.JarInfo.Version != "" {
+		title += " " + api.JarInfo.Version
+	}
+	return &Schema{
+		Schema:      SchemaDraft,
+		Title:       title,
+		Description: "数据对象（DTO）的JSON Schema，由class文件提取",
+		Definitions: g.defs,
+	}
+}
+
+type schemaGen struct {
+	classes map[string]*Class
+	defs    map[string]*Schema
+}
+
+func (g *schemaGen) define(c *Class) {
+	if _, ok := g.defs[c.ClassName]; ok {
+		return
+	}
+	s := &Schema{Title: simpleName(c.ClassName), Type: "object", Properties: make(map[string]*Schema)}
+	// 先登记再展开属性，以支持自引用和相互引用
+	g.defs[c.ClassName] = s
+	for _, p := range c.Properties {
+		name, ignored := jsonProperty(p)
+		if ignored {
+			continue
+		}
+		ps := g.typeSchema(p.Type)
+		applyConstraints(ps, p.annotations)
+		s.Properties[name] = ps
+		if isPrimitive(p.Type) || hasAnnotation(p.annotations, requiredAnnotations) {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
+
+func (g *schemaGen) typeSchema(t string) *Schema {
+	t = strings.TrimSpace(t)
+	if rest, ok := strings.CutPrefix(t, "? extends "); ok {
+		t = rest
+	} else if t == "?" || strings.HasPrefix(t, "? super ") {
+		return &Schema{}
+	}
+	if st, ok := scalarTypes[t]; ok {
+		return &Schema{Type: st[0], Format: st[1]}
+	}
+	if elem, ok := strings.CutSuffix(t, "[]"); ok {
+		return &Schema{Type: "array", Items: g.typeSchema(elem)}
+	}
+	if elem, ok := strings.CutSuffix(t, "..."); ok {
+		return &Schema{Type: "array", Items: g.typeSchema(elem)}
+	}
+
+	base, args := splitTypeArgs(t)
+	switch {
+	case collectionTypes[base]:
+		items := &Schema{}
+		if len(args) == 1 {
+			items = g.typeSchema(args[0])
+		}
+		return &Schema{Type: "array", Items: items}
+	case mapTypes[base]:
+		values := &Schema{}
+		if len(args) == 2 {
+			values = g.typeSchema(args[1])
+		}
+		return &Schema{Type: "object", AdditionalProperties: values}
+	case base == "java.util.Optional" && len(args) == 1:
+		return g.typeSchema(args[0])
+	}
+
+	if c, ok := g.classes[base]; ok {
+		switch {
+		case c.ClassType == "enum":
+			if _, ok := g.defs[c.ClassName]; !ok {
+				g.defs[c.ClassName] = &Schema{Title: simpleName(c.ClassName), Type: "string", Enum: c.EnumConstants}
+			}
+			return &Schema{Ref: "#/definitions/" + c.ClassName}
+		case c.DTO:
+			g.define(c)
+			return &Schema{Ref: "#/definitions/" + c.ClassName}
+		}
+	}
+	// 类型参数、分析范围外的类型和非数据对象：只标注Java类型
+	return &Schema{Description: t}
+}
+
+// splitTypeArgs 把 a.B<X, c.D<Y>> 拆为 a.B 和 [X, c.D<Y>]
+func splitTypeArgs(t string) (string, []string) {
+	open := strings.IndexByte(t, '<')
+	if open < 0 || !strings.HasSuffix(t, ">") {
+		return t, nil
+	}
+	var args []string
+	depth, start := 0, open+1
+	for i := open + 1; i < len(t)-1; i++ {
+		switch t[i] {
+		case '<':
+			depth++
+		case '>':
+			depth--
+		case ',':
+			if depth == 0 {
+				args = append(args, strings.TrimSpace(t[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	args = append(args, strings.TrimSpace(t[start:len(t)-1]))
+	return t[:open], args
+}
+
+func isPrimitive(t string) bool {
+	switch t {
+	case "boolean", "byte", "short", "int", "long", "float", "double", "char":
+		return true
+	}
+	return false
+}
+
+// jsonProperty 返回属性的JSON名称，@JsonIgnore的属性返回ignored
+func jsonProperty(p Property) (name string, ignored bool) {
+	name = p.Name
+	for _, a := range p.annotations {
+		switch a.TypeName() {
+		case "com.fasterxml.jackson.annotation.JsonIgnore":
+			ignored = elementBool(a, "value", true)
+		case "com.fasterxml.jackson.annotation.JsonProperty", "com.google.gson.annotations.SerializedName":
+			if v, ok := element(a, "value"); ok && v.Tag == 's' && v.Const != "" {
+				name = v.Const
+			}
+		}
+	}
+	return name, ignored
+}
+
+// applyConstraints 把Bean Validation注解转为JSON Schema约束
+func applyConstraints(s *Schema, annotations []*classfile.Annotation) {
+	for _, a := range annotations {
+		switch simpleName(a.TypeName()) {
+		case "Size":
+			lo, hasMin := elementInt(a, "min")
+			hi, hasMax := elementInt(a, "max")
+			if s.Type == "array" {
+				if hasMin {
+					s.MinItems = &lo
+				}
+				if hasMax {
+					s.MaxItems = &hi
+				}
+			} else {
+				if hasMin {
+					s.MinLength = &lo
+				}
+				if hasMax {
+					s.MaxLength = &hi
+				}
+			}
+		case "Min", "DecimalMin", "Positive", "PositiveOrZero":
+			s.Minimum = boundValue(a, 0)
+		case "Max", "DecimalMax", "Negative", "NegativeOrZero":
+			s.Maximum = boundValue(a, 0)
+		case "Pattern":
+			if v, ok := element(a, "regexp"); ok {
+				s.Pattern = v.Const
+			}
+		case "Email":
+			s.Format = "email"
+		case "NotBlank", "NotEmpty":
+			one := 1
+			if s.Type == "array" {
+				s.MinItems = &one
+			} else if s.Type == "string" {
+				s.MinLength = &one
+			}
+		}
+	}
+}
+
+// boundValue 读取@Min/@Max（value为整数）或@DecimalMin/@DecimalMax（value为字符串）的边界，
+// @Positive等没有value的注解使用def
+func boundValue(a *classfile.Annotation, def float64) *float64 {
+	v, ok := element(a, "value")
+	if !ok {
+		return &def
+	}
+	f, err := strconv.ParseFloat(v.Const, 64)
+	if err != nil {
+		return nil
+	}
+	return &f
+}
+
+func element(a *classfile.Annotation, name string) (classfile.ElementValue, bool) {
+	for _, e := range a.Elements {
+		if e.Name == name {
+			return e.Value, true
+		}
+	}
+	return classfile.ElementValue{}, false
+}
+
+func elementInt(a *classfile.Annotation, name string) (int, bool) {
+	v, ok := element(a, name)
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.Atoi(v.Const)
+	return n, err == nil
+}
+
+// elementBool 读取布尔元素，注解中未写出时返回默认值def
+func elementBool(a *classfile.Annotation, name string, def bool) bool {
+	v, ok := element(a, name)
+	if !ok {
+		return def
+	}
+	return v.Const != "0"
+}
+
+func hasAnnotation(annotations []*classfile.Annotation, names map[string]bool) bool {
+	for _, a := range annotations {
+		if names[simpleName(a.TypeName())] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,225 @@
+package sdk
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func findClass(api *API, name string) *Class {
+	for _, c := range api.Classes() {
+		if c.ClassName == name {
+			return c
+		}
+	}
+	return nil
+}
+
+func TestExtractSource(t *testing.T) {
+	root := writeFiles(t, map[string]string{
+		"src/main/java/com/example/Api.java": `package com.example;
+
+import java.util.List;
+import java.io.IOException;
+
+public interface Api {
+    int LIMIT = 10;
+    <T> List<T> find(String name) throws IOException;
+    default void close() {}
+}
+`,
+		"src/main/java/com/example/Point.java": `package com.example;
+
+public record Point(int x, int y) {
+    public static class Builder {}
+    private static class Hidden {}
+}
+`,
+		"src/main/java/com/example/Internal.java": `package com.example;
+
+class Internal {
+    public void run() {}
+}
+`,
+		"src/main/java/com/example/Broken.java": `package com.example;
+
+public class Broken {
+    public void run( {}
+}
+`,
+		"src/test/java/com/example/ApiTest.java": `package com.example;
+
+public class ApiTest {}
+`,
+	})
+
+	api, err := ExtractSource(context.Background(), root, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, c := range api.Classes() {
+		names = append(names, c.ClassName)
+	}
+	want := []string{"com.example.Api", "com.example.Broken", "com.example.Point", "com.example.Point.Builder"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("classes = %v, want %v", names, want)
+	}
+	if len(api.Errors) != 1 || !strings.HasPrefix(api.Errors[0], "src/main/java/com/example/Broken.java:") {
+		t.Errorf("Errors = %v, want one error for Broken.java", api.Errors)
+	}
+
+	apiClass := findClass(api, "com.example.Api")
+	if apiClass == nil {
+		t.Fatal("missing com.example.Api")
+	}
+	var signatures []string
+	for _, m := range apiClass.Methods {
+		signatures = append(signatures, m.Signature)
+	}
+	wantSignatures := []string{
+		"public <T> java.util.List<T> find(java.lang.String name) throws java.io.IOException",
+		"public default void close()",
+	}
+	if !reflect.DeepEqual(signatures, wantSignatures) {
+		t.Errorf("Api methods = %q, want %q", signatures, wantSignatures)
+	}
+	if len(apiClass.Fields) != 1 || apiClass.Fields[0].DefaultValue != "10" ||
+		!reflect.DeepEqual(apiClass.Fields[0].Modifiers, []string{"public", "static", "final"}) {
+		t.Errorf("Api fields = %+v, want public static final LIMIT = 10", apiClass.Fields)
+	}
+
+	point := findClass(api, "com.example.Point")
+	if point == nil {
+		t.Fatal("missing com.example.Point")
+	}
+	if len(point.Constructors) != 1 || point.Constructors[0].Signature != "public Point(int x, int y)" {
+		t.Errorf("Point constructors = %+v, want the canonical constructor", point.Constructors)
+	}
+	if len(point.Methods) != 2 || point.Methods[0].Signature != "public int x()" || point.Methods[1].Signature != "public int y()" {
+		t.Errorf("Point methods = %+v, want accessors x() and y()", point.Methods)
+	}
+	if builder := findClass(api, "com.example.Point.Builder"); builder == nil ||
+		!reflect.DeepEqual(builder.Modifiers, []string{"public", "static"}) {
+		t.Errorf("Point.Builder = %+v, want a public static class", builder)
+	}
+}
+
+func TestExtractSourceMissing(t *testing.T) {
+	if _, err := ExtractSource(context.Background(), filepath.Join(t.TempDir(), "missing"), 0); err == nil {
+		t.Error("ExtractSource() on a missing path should fail")
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	root := writeFiles(t, map[string]string{
+		"Greeter.java": `package demo;
+
+public class Greeter {
+    public String greet(String name) { return name; }
+}
+`,
+	})
+	api, err := ExtractSource(context.Background(), root, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := Markdown(api)
+	for _, want := range []string{
+		"## 包: demo",
+		"### 类: Greeter",
+		"- `public java.lang.String greet(java.lang.String name)`",
+		"  - **参数**: `name` (java.lang.String)",
+	} {
+		if !strings.Contains(doc, want) {
+			t.Errorf("Markdown() missing %q:\n%s", want, doc)
+		}
+	}
+}
+
+func TestDecapitalize(t *testing.T) {
+	cases := map[string]string{
+		"Name":    "name",
+		"URL":     "URL",
+		"X":       "x",
+		"UserID":  "userID",
+		"ÄBCount": "ÄBCount",
+	}
+	for in, want := range cases {
+		if got := decapitalize(in); got != want {
+			t.Errorf("decapitalize(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestJSONSchema(t *testing.T) {
+	api := &API{
+		JarInfo: JarInfo{Name: "demo", Version: "1.0"},
+		Packages: []Package{{
+			PackageName: "demo",
+			Classes: []Class{
+				{ClassName: "demo.Status", ClassType: "enum", EnumConstants: []string{"ACTIVE", "CLOSED"}},
+				{ClassName: "demo.Order", ClassType: "class", DTO: true, Properties: []Property{
+					{Name: "id", Type: "long"},
+					{Name: "status", Type: "demo.Status"},
+					{Name: "items", Type: "java.util.List<demo.Item>"},
+					{Name: "tags", Type: "java.util.Map<java.lang.String, java.lang.String>"},
+					{Name: "parent", Type: "demo.Order"},
+				}},
+				{ClassName: "demo.Item", ClassType: "record", DTO: true, Properties: []Property{
+					{Name: "price", Type: "java.math.BigDecimal"},
+				}},
+			},
+		}},
+	}
+	s := JSONSchema(api)
+	if s.Title != "demo 1.0" {
+		t.Errorf("Title = %q, want demo 1.0", s.Title)
+	}
+	order := s.Definitions["demo.Order"]
+	if order == nil {
+		t.Fatalf("definitions = %v, want demo.Order", s.Definitions)
+	}
+	if !reflect.DeepEqual(order.Required, []string{"id"}) {
+		t.Errorf("Order.Required = %v, want [id]", order.Required)
+	}
+	cases := []struct {
+		prop string
+		want *Schema
+	}{
+		{"id", &Schema{Type: "integer", Format: "int64"}},
+		{"status", &Schema{Ref: "#/definitions/demo.Status"}},
+		{"items", &Schema{Type: "array", Items: &Schema{Ref: "#/definitions/demo.Item"}}},
+		{"tags", &Schema{Type: "object", AdditionalProperties: &Schema{Type: "string"}}},
+		{"parent", &Schema{Ref: "#/definitions/demo.Order"}},
+	}
+	for _, c := range cases {
+		if got := order.Properties[c.prop]; !reflect.DeepEqual(got, c.want) {
+			t.Errorf("Order.%s = %+v, want %+v", c.prop, got, c.want)
+		}
+	}
+	if status := s.Definitions["demo.Status"]; status == nil || !reflect.DeepEqual(status.Enum, []string{"ACTIVE", "CLOSED"}) {
+		t.Errorf("Status definition = %+v, want enum ACTIVE, CLOSED", status)
+	}
+	if item := s.Definitions["demo.Item"]; item == nil || item.Properties["price"].Type != "number" {
+		t.Errorf("Item definition = %+v, want price as number", item)
+	}
+}
@@ -0,0 +1,419 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/liujinliang/lang-checker/internal/complexity"
+	"github.com/liujinliang/lang-checker/internal/java"
+	"github.com/liujinliang/lang-checker/internal/source"
+)
+
+// ExtractSource 解析目录（或单个.java文件）中的Java源码，提取公开类型及其public和protected成员，结构与Extract相同，
+// 用于源码版本之间的API对比。类型名按导入补全为全限定名，隐含的修饰符（如接口成员的public）、默认构造器和
+// record的访问器按编译结果补齐。测试代码（src/test/java和*Test.java）不计入，不识别数据对象；
+// 无法读取的文件和语法错误记录在API.Errors中，出错的成员已跳过
+func ExtractSource(ctx context.Context, root string, maxSize int64) (*API, error) {
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, err
+	}
+	api := &API{JarInfo: JarInfo{Name: filepath.Base(filepath.Clean(root))}}
+	packages := make(map[string]*Package)
+	add := func(c Class) {
+		p := packages[c.PackageName]
+		if p == nil {
+			p = &Package{PackageName: c.PackageName}
+			packages[c.PackageName] = p
+		}
+		p.Classes = append(p.Classes, c)
+	}
+
+	parse := func(path string) {
+		name := path
+		if rel, err := filepath.Rel(root, path); err == nil && info.IsDir() {
+			name = filepath.ToSlash(rel)
+		}
+		content, _, err := source.ReadFile(path, maxSize)
+		if err != nil {
+			api.Errors = append(api.Errors, fmt.Sprintf("%s: %v", name, err))
+			return
+		}
+		file := java.Parse(content)
+		if len(file.Errors) > 0 {
+			api.Errors = append(api.Errors, fmt.Sprintf("%s: %d处语法错误，首个错误: %s", name, len(file.Errors), file.Errors[0]))
+		}
+		for _, t := range file.Types {
+			extractSourceTypes(file, t, false, add)
+		}
+	}
+
+	if !info.IsDir() {
+		parse(root)
+	} else {
+		err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if d.IsDir() {
+				if path != root && (strings.HasPrefix(d.Name(), ".") || d.Name() == "target" || d.Name() == "build") {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if isMainJavaSource(path) {
+				parse(path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	api.Packages = sortedPackages(packages)
+	return api, nil
+}
+
+// isMainJavaSource 判断是否为非测试的Java源文件
+func isMainJavaSource(path string) bool {
+	slashed := filepath.ToSlash(path)
+	return strings.HasSuffix(slashed, ".java") && !strings.Contains(slashed, "src/test/java/") &&
+		!strings.HasSuffix(slashed, "Test.java") && !strings.HasSuffix(slashed, "/package-info.java") &&
+		!strings.HasSuffix(slashed, "/module-info.java")
+}
+
+// extractSourceTypes 提取包外可见的类型及其可见的内部类型，inInterface表示外层类型是接口或注解
+func extractSourceTypes(file *java.File, t *java.TypeDecl, inInterface bool, add func(Class)) {
+	visible := t.Has("public") || (t.Outer != nil && (inInterface || t.Has("protected")))
+	if t.Name == nil || !visible || t.Has("private") {
+		return
+	}
+	add(sourceClass(file, t, inInterface))
+	for _, m := range t.Members {
+		if nested, ok := m.(*java.TypeDecl); ok {
+			extractSourceTypes(file, nested, isInterfaceLike(t), add)
+		}
+	}
+}
+
+func isInterfaceLike(t *java.TypeDecl) bool {
+	return t.Kind == java.InterfaceKind || t.Kind == java.AnnotationKind
+}
+
+// sourceScope 在一个类型内把源码中的类型名补全为全限定名，类型参数保持原样
+type sourceScope struct {
+	file     *java.File
+	typeVars map[string]bool
+}
+
+func newSourceScope(file *java.File, t *java.TypeDecl) *sourceScope {
+	s := &sourceScope{file: file, typeVars: make(map[string]bool)}
+	for o := t; o != nil; o = o.Outer {
+		for _, tp := range o.TypeParams {
+			s.typeVars[tp.Name] = true
+		}
+	}
+	return s
+}
+
+// with 返回加入方法类型参数后的作用域
+func (s *sourceScope) with(params []*java.TypeParam) *sourceScope {
+	if len(params) == 0 {
+		return s
+	}
+	vars := make(map[string]bool, len(s.typeVars)+len(params))
+	for v := range s.typeVars {
+		vars[v] = true
+	}
+	for _, tp := range params {
+		vars[tp.Name] = true
+	}
+	return &sourceScope{file: s.file, typeVars: vars}
+}
+
+func (s *sourceScope) qualify(name string) string {
+	if s.typeVars[name] {
+		return name
+	}
+	return complexity.QualifyJavaType(s.file, name)
+}
+
+// typeString 按class文件签名的格式输出类型，如 java.util.Map<java.lang.String, ? extends T>[]
+func (s *sourceScope) typeString(t *java.Type) string {
+	if t == nil {
+		return ""
+	}
+	var b strings.Builder
+	if t.Name == "?" {
+		b.WriteString("?")
+		if t.Bound != nil {
+			if t.Super {
+				b.WriteString(" super ")
+			} else {
+				b.WriteString(" extends ")
+			}
+			b.WriteString(s.typeString(t.Bound))
+		}
+	} else {
+		b.WriteString(s.qualify(t.Name))
+		if len(t.Args) > 0 {
+			args := make([]string, len(t.Args))
+			for i, a := range t.Args {
+				args[i] = s.typeString(a)
+			}
+			b.WriteString("<" + strings.Join(args, ", ") + ">")
+		}
+	}
+	b.WriteString(strings.Repeat("[]", t.Dims))
+	return b.String()
+}
+
+func (s *sourceScope) typeParams(params []*java.TypeParam) string {
+	if len(params) == 0 {
+		return ""
+	}
+	parts := make([]string, len(params))
+	for i, tp := range params {
+		parts[i] = tp.Name
+		if len(tp.Bounds) > 0 {
+			bounds := make([]string, len(tp.Bounds))
+			for j, b := range tp.Bounds {
+				bounds[j] = s.typeString(b)
+			}
+			parts[i] += " extends " + strings.Join(bounds, " & ")
+		}
+	}
+	return "<" + strings.Join(parts, ", ") + ">"
+}
+
+func (s *sourceScope) annotations(list []*java.Annotation) []string {
+	var names []string
+	for _, a := range list {
+		names = append(names, "@"+s.qualify(a.Name))
+	}
+	return names
+}
+
+func sourceClass(file *java.File, t *java.TypeDecl, inInterface bool) Class {
+	s := newSourceScope(file, t)
+	name := t.NestedName()
+	class := Class{
+		ClassName:   name,
+		PackageName: file.Package,
+		ClassType:   sourceClassType(t.Kind),
+		TypeParams:  s.typeParams(t.TypeParams),
+		Annotations: s.annotations(t.Annotations),
+	}
+	if file.Package != "" {
+		class.ClassName = file.Package + "." + name
+	}
+
+	var mods []string
+	switch {
+	case t.Has("public") || inInterface:
+		mods = append(mods, "public")
+	case t.Has("protected"):
+		mods = append(mods, "protected")
+	}
+	if t.Has("abstract") && !isInterfaceLike(t) {
+		mods = append(mods, "abstract")
+	}
+	// 内部的接口、枚举、record以及接口中的类型隐含static
+	if t.Outer != nil && (t.Has("static") || t.Kind != java.ClassKind || inInterface) {
+		mods = append(mods, "static")
+	}
+	if t.Has("final") && t.Kind == java.ClassKind {
+		mods = append(mods, "final")
+	}
+	class.Modifiers = mods
+
+	var supers []*java.Type
+	if t.Kind == java.InterfaceKind {
+		supers = t.Extends
+	} else {
+		if t.Kind == java.ClassKind && len(t.Extends) > 0 {
+			if super := s.typeString(t.Extends[0]); super != "java.lang.Object" {
+				class.SuperClass = super
+			}
+		}
+		supers = t.Implements
+	}
+	for _, i := range supers {
+		class.Interfaces = append(class.Interfaces, s.typeString(i))
+	}
+	for _, c := range t.Constants {
+		class.EnumConstants = append(class.EnumConstants, c.Name.Name)
+	}
+
+	interfaceLike := isInterfaceLike(t)
+	declared := make(map[string]bool)
+	hasConstructor := false
+	for _, m := range t.Members {
+		switch d := m.(type) {
+		case *java.FieldDecl:
+			if interfaceLike || d.Has("public") || d.Has("protected") {
+				class.Fields = append(class.Fields, sourceFields(s, d, interfaceLike)...)
+			}
+		case *java.MethodDecl:
+			if d.Constructor {
+				hasConstructor = true
+			} else if len(d.Params) == 0 {
+				declared[d.Name.Name] = true
+			}
+			if t.Kind == java.EnumKind && d.Constructor {
+				continue
+			}
+			if d.Has("private") || !(interfaceLike || d.Has("public") || d.Has("protected")) {
+				continue
+			}
+			method := sourceMethod(s, file, t, d, interfaceLike)
+			if d.Constructor {
+				class.Constructors = append(class.Constructors, method)
+			} else {
+				class.Methods = append(class.Methods, method)
+			}
+		}
+	}
+
+	access := class.Modifiers[0]
+	switch {
+	case t.Kind == java.RecordKind:
+		// record的规范构造器和组件访问器由编译器生成（显式声明时以声明为准）
+		if !hasConstructor {
+			ctor := Method{MethodName: t.TypeName(), Modifiers: []string{access}}
+			for _, c := range t.Components {
+				ctor.Parameters = append(ctor.Parameters, sourceParam(s, c))
+			}
+			ctor.Signature = methodSignature(ctor)
+			class.Constructors = append(class.Constructors, ctor)
+		}
+		for _, c := range t.Components {
+			if declared[c.Name.Name] {
+				continue
+			}
+			accessor := Method{MethodName: c.Name.Name, Modifiers: []string{"public"}, ReturnType: s.typeString(c.Type)}
+			accessor.Signature = methodSignature(accessor)
+			class.Methods = append(class.Methods, accessor)
+		}
+	case t.Kind == java.ClassKind && !hasConstructor:
+		// 没有声明构造器时编译器生成与类同样可见的无参构造器
+		ctor := Method{MethodName: t.TypeName(), Modifiers: []string{access}}
+		ctor.Signature = methodSignature(ctor)
+		class.Constructors = append(class.Constructors, ctor)
+	}
+	return class
+}
+
+func sourceClassType(kind java.TypeKind) string {
+	if kind == java.AnnotationKind {
+		return "annotation"
+	}
+	return kind.String()
+}
+
+// sourceFields 提取字段声明中的每个变量，接口中的字段隐含public static final
+func sourceFields(s *sourceScope, d *java.FieldDecl, interfaceLike bool) []Field {
+	var mods []string
+	if interfaceLike {
+		mods = []string{"public", "static", "final"}
+	} else {
+		for _, k := range []string{"public", "protected", "static", "final", "transient", "volatile"} {
+			if d.Has(k) {
+				mods = append(mods, k)
+			}
+		}
+	}
+	constant := interfaceLike || (d.Has("static") && d.Has("final"))
+	var fields []Field
+	for _, v := range d.Vars {
+		f := Field{
+			FieldName:   v.Name.Name,
+			Type:        s.typeString(d.Type) + strings.Repeat("[]", v.Dims),
+			Modifiers:   mods,
+			Annotations: s.annotations(d.Annotations),
+		}
+		if lit, ok := v.Init.(*java.Literal); ok && constant {
+			f.DefaultValue = lit.Value
+		}
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+// sourceMethod 提取方法或构造器，修饰符与Extract一致：接口方法为public，非抽象的实例方法为default
+func sourceMethod(s *sourceScope, file *java.File, owner *java.TypeDecl, d *java.MethodDecl, interfaceLike bool) Method {
+	s = s.with(d.TypeParams)
+	method := Method{
+		MethodName:  d.Name.Name,
+		TypeParams:  s.typeParams(d.TypeParams),
+		Annotations: s.annotations(d.Annotations),
+	}
+	if d.Constructor {
+		method.MethodName = owner.TypeName()
+	} else {
+		method.ReturnType = s.typeString(d.Result)
+	}
+
+	var mods []string
+	if interfaceLike {
+		mods = append(mods, "public")
+		if d.Body != nil && !d.Has("static") {
+			mods = append(mods, "default")
+		}
+		if d.Has("static") {
+			mods = append(mods, "static")
+		}
+	} else {
+		for _, k := range []string{"public", "protected", "abstract", "static", "final", "synchronized", "native"} {
+			if d.Has(k) {
+				mods = append(mods, k)
+			}
+		}
+	}
+	method.Modifiers = mods
+
+	params := d.Params
+	if d.Compact {
+		params = owner.Components
+	}
+	for _, p := range params {
+		method.Parameters = append(method.Parameters, sourceParam(s, p))
+	}
+	for _, e := range d.Throws {
+		method.Exceptions = append(method.Exceptions, s.typeString(e))
+	}
+	if d.Default != nil {
+		method.DefaultValue = nodeText(file, d.Default)
+	}
+	method.Signature = methodSignature(method)
+	return method
+}
+
+func sourceParam(s *sourceScope, p *java.Param) Parameter {
+	param := Parameter{Name: p.Name.Name, Type: s.typeString(p.Type), Annotations: s.annotations(p.Annotations)}
+	if p.Varargs {
+		param.Type += "..."
+	}
+	return param
+}
+
+// nodeText 按词法单元拼出节点的源码，相邻的标识符、关键字和字面量之间加空格
+func nodeText(file *java.File, n java.Node) string {
+	first, last := n.Span()
+	var b strings.Builder
+	wordy := func(k java.TokenKind) bool { return k != java.Operator }
+	for i := first; i <= last && i < len(file.Tokens); i++ {
+		if i > first && wordy(file.Tokens[i].Kind) && wordy(file.Tokens[i-1].Kind) {
+			b.WriteByte(' ')
+		}
+		b.WriteString(file.Tokens[i].Text)
+	}
+	return b.String()
+}
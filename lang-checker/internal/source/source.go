@@ -0,0 +1,138 @@
+// Package source 读取源文件并识别编码，统一转码为UTF-8后交给分析器
+package source
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+// 识别出的源文件编码
+const (
+	UTF8    = "UTF-8"
+	UTF8BOM = "UTF-8 BOM"
+	UTF16LE = "UTF-16LE"
+	UTF16BE = "UTF-16BE"
+	GB18030 = "GB18030"
+	// Unknown 无法识别的编码，非法字节已替换为U+FFFD
+	Unknown = "unknown"
+)
+
+// DefaultMaxSize 默认的最大文件大小
+const DefaultMaxSize = 5 << 20
+
+var (
+	// ErrBinary 文件内容为二进制
+	ErrBinary = errors.New("二进制文件")
+	// ErrTooLarge 文件超过大小限制
+	ErrTooLarge = errors.New("文件过大")
+)
+
+// ReadFile 读取文件并转码为UTF-8，返回内容和原始编码，maxSize<=0表示不限制大小
+func ReadFile(path string, maxSize int64) (string, string, error) {
+	data, err := ReadBytes(path, maxSize)
+	if err != nil {
+		return "", "", err
+	}
+	return Decode(data)
+}
+
+// ReadBytes 在大小限制内读取文件的原始字节，maxSize<=0表示不限制大小
+func ReadBytes(path string, maxSize int64) ([]byte, error) {
+	if maxSize > 0 {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if info.Size() > maxSize {
+			return nil, fmt.Errorf("%w: %d字节，上限%d字节", ErrTooLarge, info.Size(), maxSize)
+		}
+	}
+	return os.ReadFile(path)
+}
+
+// Decode 识别BOM、UTF-16和GBK/GB18030编码并转码为UTF-8，二进制内容返回ErrBinary
+func Decode(data []byte) (string, string, error) {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xEF, 0xBB, 0xBF}):
+		return string(data[3:]), UTF8BOM, nil
+	case bytes.HasPrefix(data, []byte{0xFF, 0xFE}):
+		return decodeUTF16(data[2:], false), UTF16LE, nil
+	case bytes.HasPrefix(data, []byte{0xFE, 0xFF}):
+		return decodeUTF16(data[2:], true), UTF16BE, nil
+	}
+
+	if enc, ok := guessUTF16(data); ok {
+		return decodeUTF16(data, enc == UTF16BE), enc, nil
+	}
+	if isBinary(data) {
+		return "", "", ErrBinary
+	}
+	if utf8.Valid(data) {
+		return string(data), UTF8, nil
+	}
+
+	// GBK是GB18030的子集，解码结果中没有替换字符才认为识别成功
+	if decoded, err := simplifiedchinese.GB18030.NewDecoder().Bytes(data); err == nil && !bytes.ContainsRune(decoded, utf8.RuneError) {
+		return string(decoded), GB18030, nil
+	}
+	return strings.ToValidUTF8(string(data), string(utf8.RuneError)), Unknown, nil
+}
+
+func decodeUTF16(data []byte, bigEndian bool) string {
+	units := make([]uint16, len(data)/2)
+	for i := range units {
+		if bigEndian {
+			units[i] = uint16(data[2*i])<<8 | uint16(data[2*i+1])
+		} else {
+			units[i] = uint16(data[2*i+1])<<8 | uint16(data[2*i])
+		}
+	}
+	return string(utf16.Decode(units))
+}
+
+// guessUTF16 识别无BOM的UTF-16：源码以ASCII字符为主，每个字符的高字节为0
+func guessUTF16(data []byte) (string, bool) {
+	n := min(len(data), 4096) &^ 1
+	if n < 4 {
+		return "", false
+	}
+	var evenZero, oddZero int
+	for i := 0; i < n; i += 2 {
+		if data[i] == 0 {
+			evenZero++
+		}
+		if data[i+1] == 0 {
+			oddZero++
+		}
+	}
+	pairs := n / 2
+	switch {
+	case oddZero*10 > pairs*7 && evenZero*10 < pairs:
+		return UTF16LE, true
+	case evenZero*10 > pairs*7 && oddZero*10 < pairs:
+		return UTF16BE, true
+	}
+	return "", false
+}
+
+// isBinary 内容中出现NUL或控制字符占比超过10%时视为二进制
+func isBinary(data []byte) bool {
+	sample := data[:min(len(data), 8000)]
+	if bytes.IndexByte(sample, 0) >= 0 {
+		return true
+	}
+	control := 0
+	for _, b := range sample {
+		if b < 0x20 && b != '\n' && b != '\r' && b != '\t' && b != '\f' && b != '\b' && b != 0x1B {
+			control++
+		}
+	}
+	return control*10 > len(sample)
+}
@@ -0,0 +1,74 @@
+package source
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"unicode/utf16"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+const text = "package a;\n// 中文注释\nclass A {}\n"
+
+func utf16Bytes(s string, bigEndian bool) []byte {
+	var b []byte
+	for _, u := range utf16.Encode([]rune(s)) {
+		if bigEndian {
+			b = append(b, byte(u>>8), byte(u))
+		} else {
+			b = append(b, byte(u), byte(u>>8))
+		}
+	}
+	return b
+}
+
+func TestDecode(t *testing.T) {
+	gb, err := simplifiedchinese.GB18030.NewEncoder().Bytes([]byte(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		data     []byte
+		want     string
+		encoding string
+		err      error
+	}{
+		{"UTF-8", []byte(text), text, UTF8, nil},
+		{"UTF-8 BOM", append([]byte{0xEF, 0xBB, 0xBF}, text...), text, UTF8BOM, nil},
+		{"UTF-16LE BOM", append([]byte{0xFF, 0xFE}, utf16Bytes(text, false)...), text, UTF16LE, nil},
+		{"UTF-16BE BOM", append([]byte{0xFE, 0xFF}, utf16Bytes(text, true)...), text, UTF16BE, nil},
+		{"无BOM的UTF-16LE", utf16Bytes(text, false), text, UTF16LE, nil},
+		{"无BOM的UTF-16BE", utf16Bytes(text, true), text, UTF16BE, nil},
+		{"GB18030", gb, text, GB18030, nil},
+		{"空文件", nil, "", UTF8, nil},
+		{"二进制", []byte{0xCA, 0xFE, 0xBA, 0xBE, 0, 0, 0, 52}, "", "", ErrBinary},
+		{"无法识别", []byte("a\xff\xffb"), "a�b", Unknown, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, encoding, err := Decode(tt.data)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+			if got != tt.want || encoding != tt.encoding {
+				t.Errorf("Decode() = %q, %q, want %q, %q", got, encoding, tt.want, tt.encoding)
+			}
+		})
+	}
+}
+
+func TestReadFileMaxSize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "A.java")
+	if err := os.WriteFile(path, []byte(text), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := ReadFile(path, 10); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("err = %v, want ErrTooLarge", err)
+	}
+	if got, _, err := ReadFile(path, 0); err != nil || got != text {
+		t.Errorf("ReadFile() = %q, %v", got, err)
+	}
+}
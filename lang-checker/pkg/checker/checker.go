@@ -0,0 +1,152 @@
+// Package checker 提供可嵌入的代码质量分析API。
+//
+// 示例:
+//
+//	opts := checker.DefaultOptions()
+//	opts.Languages = []checker.Language{checker.Java}
+//	result, err := checker.AnalyzeDir(ctx, "./src", opts)
+package checker
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/liujinliang/lang-checker/internal/analyzer"
+	"github.com/liujinliang/lang-checker/internal/decompiler"
+	"github.com/liujinliang/lang-checker/internal/models"
+	"github.com/liujinliang/lang-checker/internal/rules"
+	"github.com/liujinliang/lang-checker/internal/source"
+)
+
+// ErrFileTimeout 单个文件分析超过Options.FileTimeout
+var ErrFileTimeout = analyzer.ErrFileTimeout
+
+// ErrGeneratedCode 文件为生成代码且Options.Generated为GeneratedExclude
+var ErrGeneratedCode = analyzer.ErrGeneratedCode
+
+// ErrLanguageDisabled AnalyzeFile或AnalyzeSource的文件语言不在Options.Languages中
+var ErrLanguageDisabled = analyzer.ErrLanguageDisabled
+
+// ErrBinaryFile 文件内容为二进制
+var ErrBinaryFile = source.ErrBinary
+
+// ErrFileTooLarge 文件超过Options.MaxFileSize
+var ErrFileTooLarge = source.ErrTooLarge
+
+// AnalyzeFile 分析单个文件，.class文件按字节码分析，JAR包使用AnalyzeJar
+func AnalyzeFile(ctx context.Context, path string, opts Options) (*FileResult, error) {
+	a, err := newAnalyzer(opts)
+	if err != nil {
+		return nil, err
+	}
+	m, err := a.AnalyzeFile(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	result := fromMetrics(m)
+	return &result, nil
+}
+
+// AnalyzeSource 分析内存中的源码，name用于识别语言（按扩展名）和标注问题位置
+func AnalyzeSource(ctx context.Context, name string, src []byte, opts Options) (*FileResult, error) {
+	a, err := newAnalyzer(opts)
+	if err != nil {
+		return nil, err
+	}
+	m, err := a.AnalyzeSource(ctx, name, src)
+	if err != nil {
+		return nil, err
+	}
+	result := fromMetrics(m)
+	return &result, nil
+}
+
+// AnalyzeDir 递归分析目录下所有支持的源文件，超时的文件记录在Result.Diagnostics中
+func AnalyzeDir(ctx context.Context, dir string, opts Options) (*Result, error) {
+	a, err := newAnalyzer(opts)
+	if err != nil {
+		return nil, err
+	}
+	report, err := a.AnalyzeDirectory(ctx, dir)
+	if report == nil {
+		return nil, err
+	}
+	return fromReport(report), err
+}
+
+// AnalyzeJar 不依赖JVM分析JAR包中的class文件，每个class文件对应Result中的一个文件，
+// 路径为 JAR路径!/包内路径，无法解析的class文件记录在Result.Diagnostics中。
+// 配置了Options.Decompilers时按顶层类反编译后分析源码，结果路径为顶层类的class文件
+func AnalyzeJar(ctx context.Context, path string, opts Options) (*Result, error) {
+	a, err := newAnalyzer(opts)
+	if err != nil {
+		return nil, err
+	}
+	report, err := a.Analyze(ctx, path)
+	if report == nil {
+		return nil, err
+	}
+	return fromReport(report), err
+}
+
+// Rules 返回所有内置规则的描述
+func Rules() []RuleInfo {
+	var infos []RuleInfo
+	for _, r := range rules.DefaultRules() {
+		infos = append(infos, fromMetadata(r.Meta()))
+	}
+	return infos
+}
+
+func newAnalyzer(opts Options) (*analyzer.CodeAnalyzer, error) {
+	internal := analyzer.Options{
+		Thresholds:        toThresholds(opts.Thresholds),
+		TestThresholds:    toThresholds(opts.TestThresholds),
+		EnabledRules:      opts.Rules,
+		DisabledRules:     opts.DisabledRules,
+		TestEnabledRules:  opts.TestRules,
+		TestDisabledRules: opts.TestDisabledRules,
+		FileTimeout:       opts.FileTimeout,
+		MaxFileSize:       opts.MaxFileSize,
+		SnippetContext:    opts.SnippetContext,
+		GoPackages:        opts.GoPackages,
+		Generated:         string(opts.Generated),
+		DecompileTimeout:  opts.DecompileTimeout,
+	}
+	for _, d := range opts.Decompilers {
+		internal.Decompilers = append(internal.Decompilers, decompiler.Decompiler{Name: d.Name, Command: d.Command})
+	}
+	if opts.Progress != nil {
+		internal.Progress = func(path string, done, total int) {
+			opts.Progress(Progress{Path: path, Done: done, Total: total})
+		}
+	}
+	for _, lang := range opts.Languages {
+		internal.Languages = append(internal.Languages, models.Language(lang))
+	}
+	for _, p := range opts.GeneratedPatterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("生成代码模式 %q 无效: %w", p, err)
+		}
+		internal.GeneratedPatterns = append(internal.GeneratedPatterns, re)
+	}
+	if internal.Generated == "" {
+		internal.Generated = analyzer.GeneratedSeparate
+	}
+	return analyzer.NewCodeAnalyzerWithOptions(internal), nil
+}
+
+func toThresholds(th Thresholds) rules.Thresholds {
+	return rules.Thresholds{
+		FunctionLength:       th.FunctionLength,
+		CyclomaticComplexity: th.CyclomaticComplexity,
+		NestingDepth:         th.NestingDepth,
+		CognitiveComplexity:  th.CognitiveComplexity,
+		DuplicateTokens:      th.DuplicateTokens,
+		GodClassWMC:          th.GodClassWMC,
+		GodClassCBO:          th.GodClassCBO,
+		MaxLCOM:              th.MaxLCOM,
+	}
+}
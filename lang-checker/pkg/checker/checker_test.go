@@ -0,0 +1,102 @@
+package checker
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const sample = `package com.example;
+
+public class Sample {
+    public void hello() {
+        int x = 1;
+        System.out.println("hello");
+        x++;
+    }
+}
+`
+
+// snippetLines 返回java/system-out问题代码片段的行数，没有该问题时返回-1
+func snippetLines(t *testing.T, opts Options) int {
+	t.Helper()
+	result, err := AnalyzeSource(context.Background(), "Sample.java", []byte(sample), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, issue := range result.Issues {
+		if issue.RuleID == "java/system-out" {
+			return strings.Count(issue.CodeSnippet, "\n")
+		}
+	}
+	return -1
+}
+
+func TestSnippetContext(t *testing.T) {
+	tests := []struct {
+		name    string
+		context int
+		want    int
+	}{
+		{"零值不附带上下文", 0, 1},
+		{"前后各1行", 1, 3},
+		{"默认值", DefaultSnippetContext, 5},
+		{"负数不生成片段", -1, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := snippetLines(t, Options{SnippetContext: tt.context}); got != tt.want {
+				t.Errorf("snippet lines = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMaxFileSize(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "Sample.java")
+	if err := os.WriteFile(path, []byte(sample), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		maxSize int64
+		wantErr error
+	}{
+		{"零值不限制", 0, nil},
+		{"负数不限制", -1, nil},
+		{"超过限制", 10, ErrFileTooLarge},
+		{"默认值", DefaultOptions().MaxFileSize, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := AnalyzeFile(context.Background(), path, Options{MaxFileSize: tt.maxSize})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAnalyzeSourceLanguages(t *testing.T) {
+	tests := []struct {
+		name      string
+		languages []Language
+		wantErr   error
+	}{
+		{"全部语言", nil, nil},
+		{"启用Java", []Language{Java}, nil},
+		{"只启用Go", []Language{Go}, ErrLanguageDisabled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := AnalyzeSource(context.Background(), "Sample.java", []byte(sample), Options{Languages: tt.languages})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
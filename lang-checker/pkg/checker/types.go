@@ -0,0 +1,437 @@
+package checker
+
+import (
+	"time"
+
+	"github.com/liujinliang/lang-checker/internal/models"
+	"github.com/liujinliang/lang-checker/internal/rules"
+	"github.com/liujinliang/lang-checker/internal/source"
+)
+
+// Language 代码语言类型
+type Language string
+
+const (
+	Go   Language = "Go"
+	Java Language = "Java"
+)
+
+// Thresholds 规则阈值，值<=0的项使用默认值
+type Thresholds struct {
+	FunctionLength       int `json:"functionLength"`
+	CyclomaticComplexity int `json:"cyclomaticComplexity"`
+	NestingDepth         int `json:"nestingDepth"`
+	CognitiveComplexity  int `json:"cognitiveComplexity"`
+	DuplicateTokens      int `json:"duplicateTokens"`
+	// GodClassWMC/GodClassCBO Java类的方法加权和与耦合度同时超过阈值时报告为上帝类
+	GodClassWMC int `json:"godClassWmc"`
+	GodClassCBO int `json:"godClassCbo"`
+	// MaxLCOM Java类的LCOM4上限
+	MaxLCOM int `json:"maxLcom"`
+}
+
+// Progress 目录分析进度
+type Progress struct {
+	// Path 刚完成分析的文件
+	Path string
+	// Done 已完成的文件数
+	Done int
+	// Total 待分析的文件总数
+	Total int
+}
+
+// Options 分析选项，零值表示使用全部规则和默认阈值，不限制分析时长和文件大小，代码片段不附带上下文行。
+// DefaultOptions返回与命令行工具一致的默认值
+type Options struct {
+	// Rules 启用的规则ID，为空表示启用全部内置规则
+	Rules []string
+	// DisabledRules 禁用的规则ID
+	DisabledRules []string
+	// Thresholds 规则阈值
+	Thresholds Thresholds
+	// Languages 需要分析的语言，为空表示全部支持的语言
+	Languages []Language
+	// FileTimeout 单个文件的分析时限，<=0表示不限制
+	FileTimeout time.Duration
+	// MaxFileSize 单个文件的最大字节数，<=0表示不限制
+	MaxFileSize int64
+	// SnippetContext Issue.CodeSnippet中问题范围前后附带的行数，<0表示不生成代码片段
+	SnippetContext int
+	// Progress 每个文件分析完成后回调，可为nil
+	Progress func(Progress)
+	// GoPackages AnalyzeDir时按包加载Go代码并进行类型检查，启用依赖类型信息的规则
+	GoPackages bool
+	// Generated 生成代码的处理方式，为空表示GeneratedSeparate
+	Generated GeneratedMode
+	// GeneratedPatterns 额外的生成代码识别正则，匹配文件内容
+	GeneratedPatterns []string
+	// Decompilers 分析JAR包时依次尝试的反编译器，为空表示只分析字节码
+	Decompilers []Decompiler
+	// DecompileTimeout 每个反编译器处理单个类的时限，<=0表示不限制
+	DecompileTimeout time.Duration
+
+	// TestThresholds 测试代码（_test.go、src/test/java、*Test.java）的阈值，值<=0的项使用测试默认值
+	TestThresholds Thresholds
+	// TestRules 测试代码启用的规则ID，为空时与Rules相同
+	TestRules []string
+	// TestDisabledRules 测试代码额外禁用的规则ID
+	TestDisabledRules []string
+}
+
+// 默认选项值
+const (
+	// DefaultFileTimeout 单个文件的默认分析时限
+	DefaultFileTimeout = 30 * time.Second
+	// DefaultMaxFileSize 默认的单个文件最大字节数
+	DefaultMaxFileSize = source.DefaultMaxSize
+	// DefaultSnippetContext 代码片段中问题范围前后默认附带的行数
+	DefaultSnippetContext = rules.DefaultSnippetContext
+)
+
+// DefaultOptions 返回默认分析选项：启用全部规则，文件分析时限30秒，最大5MB，代码片段附带前后2行
+func DefaultOptions() Options {
+	return Options{
+		FileTimeout:    DefaultFileTimeout,
+		MaxFileSize:    DefaultMaxFileSize,
+		SnippetContext: DefaultSnippetContext,
+	}
+}
+
+// Decompiler 通过命令模板调用的外部反编译器
+type Decompiler struct {
+	// Name 名称，成功反编译的文件在FileResult.Decompiler中标注
+	Name string
+	// Command 命令及参数，{input}替换为class文件路径，{output}替换为输出目录，
+	// {classpath}替换为class文件根目录，{class}替换为类的全限定名
+	Command []string
+}
+
+// GeneratedMode 生成代码的处理方式
+type GeneratedMode string
+
+const (
+	// GeneratedSeparate 计算指标但不检查规则、不做AI检测和评分，FileResult.Generated为true
+	GeneratedSeparate GeneratedMode = "separate"
+	// GeneratedExclude 跳过生成代码，记录在Result.Diagnostics中
+	GeneratedExclude GeneratedMode = "exclude"
+	// GeneratedInclude 与手写代码同等对待
+	GeneratedInclude GeneratedMode = "include"
+)
+
+// Issue 代码问题
+type Issue struct {
+	FilePath string `json:"filePath,omitempty"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+	// EndLine/EndColumn 问题范围的结束位置，指向范围后的第一个字符，Column均按字节计
+	EndLine    int    `json:"endLine,omitempty"`
+	EndColumn  int    `json:"endColumn,omitempty"`
+	Message    string `json:"message"`
+	Severity   string `json:"severity"`
+	RuleID     string `json:"ruleId"`
+	Category   string `json:"category,omitempty"`
+	Suggestion string `json:"suggestion,omitempty"`
+	// DocURL 规则对应的规范文档
+	DocURL string `json:"docUrl,omitempty"`
+	// CodeSnippet 问题范围及前后若干行源码，带行号，问题范围内的行以'>'标记
+	CodeSnippet string `json:"codeSnippet,omitempty"`
+}
+
+// FileResult 单个文件的分析结果
+type FileResult struct {
+	FilePath             string   `json:"filePath"`
+	Language             Language `json:"language"`
+	Issues               []Issue  `json:"issues"`
+	CyclomaticComplexity int      `json:"cyclomaticComplexity"`
+	CognitiveComplexity  int      `json:"cognitiveComplexity"`
+	CommentRatio         float64  `json:"commentRatio"`
+	LongFunctions        int      `json:"longFunctions"`
+	DeepNesting          int      `json:"deepNesting"`
+	DuplicateLines       int      `json:"duplicateLines"`
+	AIGeneratedScore     float64  `json:"aiGeneratedScore"`
+	AIIndicators         []string `json:"aiIndicators"`
+	Score                float64  `json:"score"`
+	FunctionCount        int      `json:"functionCount"`
+	TotalLines           int      `json:"totalLines"`
+	CodeLines            int      `json:"codeLines"`
+	CommentLines         int      `json:"commentLines"`
+	BlankLines           int      `json:"blankLines"`
+	PublicDecls          int      `json:"publicDecls"`
+	DocumentedDecls      int      `json:"documentedDecls"`
+	DocCoverage          float64  `json:"docCoverage"`
+	Halstead             Halstead `json:"halstead"`
+	MaintainabilityIndex float64  `json:"maintainabilityIndex"`
+	Generated            bool     `json:"generated,omitempty"`
+	GeneratedReason      string   `json:"generatedReason,omitempty"`
+	// Package Go为文件所在目录，Java为package声明的包名
+	Package string `json:"package,omitempty"`
+	// Test 是否为测试代码
+	Test bool `json:"test,omitempty"`
+	// Encoding 源文件的原始编码（如GB18030、UTF-16LE），UTF-8时为空
+	Encoding string `json:"encoding,omitempty"`
+	// SyntaxErrors Java源码的语法错误，出错的语句或成员已跳过，其余部分正常分析
+	SyntaxErrors []string `json:"syntaxErrors,omitempty"`
+	// Bytecode 是否为编译后的class文件（含JAR包中的），只有方法、类和依赖指标，不检查规则、不评分
+	Bytecode bool `json:"bytecode,omitempty"`
+	// Decompiler 由哪个反编译器还原出源码，问题的行号对应反编译结果
+	Decompiler string `json:"decompiler,omitempty"`
+	// Functions 每个函数/方法的指标
+	Functions []FunctionResult `json:"functions,omitempty"`
+	// Classes Java文件中每个具名类型的CK指标
+	Classes []ClassResult `json:"classes,omitempty"`
+}
+
+// Halstead Halstead度量
+type Halstead struct {
+	DistinctOperators int     `json:"distinctOperators"`
+	DistinctOperands  int     `json:"distinctOperands"`
+	TotalOperators    int     `json:"totalOperators"`
+	TotalOperands     int     `json:"totalOperands"`
+	Vocabulary        int     `json:"vocabulary"`
+	Length            int     `json:"length"`
+	Volume            float64 `json:"volume"`
+	Difficulty        float64 `json:"difficulty"`
+	Effort            float64 `json:"effort"`
+}
+
+// FunctionResult 单个函数或方法的指标
+type FunctionResult struct {
+	Name                 string   `json:"name"`
+	Receiver             string   `json:"receiver,omitempty"`
+	StartLine            int      `json:"startLine"`
+	EndLine              int      `json:"endLine"`
+	Lines                int      `json:"lines"`
+	Parameters           int      `json:"parameters"`
+	CyclomaticComplexity int      `json:"cyclomaticComplexity"`
+	CognitiveComplexity  int      `json:"cognitiveComplexity"`
+	MaxNesting           int      `json:"maxNesting"`
+	Exported             bool     `json:"exported,omitempty"`
+	CommentLines         int      `json:"commentLines"`
+	Halstead             Halstead `json:"halstead"`
+	MaintainabilityIndex float64  `json:"maintainabilityIndex"`
+	// BytecodeSize 字节码分析时方法体的字节数
+	BytecodeSize int `json:"bytecodeSize,omitempty"`
+}
+
+// ClassResult Java类型的CK指标
+type ClassResult struct {
+	// Name 类型名，内部类为 Outer.Inner
+	Name       string   `json:"name"`
+	Kind       string   `json:"kind"`
+	StartLine  int      `json:"startLine"`
+	EndLine    int      `json:"endLine"`
+	Methods    int      `json:"methods"`
+	Fields     int      `json:"fields"`
+	Superclass string   `json:"superclass,omitempty"`
+	Interfaces []string `json:"interfaces,omitempty"`
+	// WMC 各方法圈复杂度之和
+	WMC int `json:"wmc"`
+	// DIT 继承树深度，只统计分析范围内的祖先类
+	DIT int `json:"dit"`
+	// NOC 分析范围内的直接子类型数
+	NOC int `json:"noc"`
+	// CBO 引用的其他类型数
+	CBO int `json:"cbo"`
+	// Dependencies 引用的其他类型的全限定名
+	Dependencies []string `json:"dependencies,omitempty"`
+	// RFC 自身方法数 + 调用的外部方法数
+	RFC int `json:"rfc"`
+	// LCOM LCOM4，互不相关的实例方法组数
+	LCOM int `json:"lcom"`
+}
+
+// Diagnostic 分析过程中的诊断信息，如超时被跳过的文件
+type Diagnostic struct {
+	FilePath string `json:"filePath"`
+	Kind     string `json:"kind"`
+	Message  string `json:"message"`
+}
+
+// CloneLocation 重复代码片段的位置
+type CloneLocation struct {
+	FilePath  string `json:"filePath"`
+	StartLine int    `json:"startLine"`
+	EndLine   int    `json:"endLine"`
+}
+
+// CloneGroup 一组互为重复的代码片段
+type CloneGroup struct {
+	Tokens    int             `json:"tokens"`
+	Lines     int             `json:"lines"`
+	Locations []CloneLocation `json:"locations"`
+}
+
+// Result 一次分析的结果
+type Result struct {
+	Files       []FileResult `json:"files"`
+	Diagnostics []Diagnostic `json:"diagnostics,omitempty"`
+	Clones      []CloneGroup `json:"clones,omitempty"`
+	// Tests 按包统计的测试代码指标，分析范围内没有测试文件时为空
+	Tests []PackageTests `json:"tests,omitempty"`
+}
+
+// PackageTests 包的测试代码与生产代码对比
+type PackageTests struct {
+	Package         string   `json:"package"`
+	Language        Language `json:"language"`
+	ProductionLines int      `json:"productionLines"`
+	TestLines       int      `json:"testLines"`
+	// TestRatio 测试代码行数 / 生产代码行数
+	TestRatio float64 `json:"testRatio"`
+	PublicAPI int     `json:"publicApi"`
+	// Untested 没有被任何测试按名称引用或调用的公开API
+	Untested []UntestedAPI `json:"untested,omitempty"`
+}
+
+// UntestedAPI 未被测试引用的公开函数或方法
+type UntestedAPI struct {
+	FilePath string `json:"filePath"`
+	Line     int    `json:"line"`
+	Name     string `json:"name"`
+}
+
+// RuleInfo 规则描述信息
+type RuleInfo struct {
+	ID          string     `json:"id"`
+	Name        string     `json:"name"`
+	Category    string     `json:"category"`
+	Severity    string     `json:"severity"`
+	Languages   []Language `json:"languages"`
+	Tags        []string   `json:"tags,omitempty"`
+	Description string     `json:"description"`
+	DocURL      string     `json:"docUrl,omitempty"`
+}
+
+func fromMetrics(m *models.QualityMetrics) FileResult {
+	r := FileResult{
+		FilePath:             m.FilePath,
+		Language:             Language(m.Language),
+		CyclomaticComplexity: m.CyclomaticComplexity,
+		CognitiveComplexity:  m.CognitiveComplexity,
+		CommentRatio:         m.CommentRatio,
+		LongFunctions:        m.LongFunctions,
+		DeepNesting:          m.DeepNesting,
+		DuplicateLines:       m.DuplicateLines,
+		AIGeneratedScore:     m.AIGeneratedScore,
+		AIIndicators:         append([]string(nil), m.AIIndicators...),
+		Score:                m.Score,
+		FunctionCount:        m.FunctionCount,
+		TotalLines:           m.TotalLines,
+		CodeLines:            m.CodeLines,
+		CommentLines:         m.CommentLines,
+		BlankLines:           m.BlankLines,
+		PublicDecls:          m.PublicDecls,
+		DocumentedDecls:      m.DocumentedDecls,
+		DocCoverage:          m.DocCoverage,
+		Halstead:             Halstead(m.Halstead),
+		MaintainabilityIndex: m.MaintainabilityIndex,
+		Generated:            m.Generated,
+		GeneratedReason:      m.GeneratedReason,
+		Package:              m.Package,
+		Test:                 m.Test,
+		Encoding:             m.Encoding,
+		SyntaxErrors:         m.SyntaxErrors,
+		Bytecode:             m.Bytecode,
+		Decompiler:           m.Decompiler,
+	}
+	for _, issue := range m.Issues {
+		r.Issues = append(r.Issues, fromIssue(issue))
+	}
+	for _, fn := range m.Functions {
+		r.Functions = append(r.Functions, fromFunction(fn))
+	}
+	for _, c := range m.Classes {
+		c.Interfaces = append([]string(nil), c.Interfaces...)
+		c.Dependencies = append([]string(nil), c.Dependencies...)
+		r.Classes = append(r.Classes, ClassResult(c))
+	}
+	return r
+}
+
+func fromFunction(fn models.FunctionMetrics) FunctionResult {
+	return FunctionResult{
+		Name:                 fn.Name,
+		Receiver:             fn.Receiver,
+		StartLine:            fn.StartLine,
+		EndLine:              fn.EndLine,
+		Lines:                fn.Lines,
+		Parameters:           fn.Parameters,
+		CyclomaticComplexity: fn.CyclomaticComplexity,
+		CognitiveComplexity:  fn.CognitiveComplexity,
+		MaxNesting:           fn.MaxNesting,
+		Exported:             fn.Exported,
+		CommentLines:         fn.CommentLines,
+		Halstead:             Halstead(fn.Halstead),
+		MaintainabilityIndex: fn.MaintainabilityIndex,
+		BytecodeSize:         fn.BytecodeSize,
+	}
+}
+
+func fromReport(report *models.Report) *Result {
+	result := &Result{}
+	for _, m := range report.Files {
+		result.Files = append(result.Files, fromMetrics(m))
+	}
+	for _, d := range report.Diagnostics {
+		result.Diagnostics = append(result.Diagnostics, Diagnostic{
+			FilePath: d.FilePath,
+			Kind:     d.Kind,
+			Message:  d.Message,
+		})
+	}
+	for _, g := range report.Clones {
+		group := CloneGroup{Tokens: g.Tokens, Lines: g.Lines}
+		for _, loc := range g.Locations {
+			group.Locations = append(group.Locations, CloneLocation(loc))
+		}
+		result.Clones = append(result.Clones, group)
+	}
+	for _, t := range report.Tests {
+		pt := PackageTests{
+			Package:         t.Package,
+			Language:        Language(t.Language),
+			ProductionLines: t.ProductionLines,
+			TestLines:       t.TestLines,
+			TestRatio:       t.TestRatio,
+			PublicAPI:       t.PublicAPI,
+		}
+		for _, u := range t.Untested {
+			pt.Untested = append(pt.Untested, UntestedAPI(u))
+		}
+		result.Tests = append(result.Tests, pt)
+	}
+	return result
+}
+
+func fromIssue(i models.Issue) Issue {
+	return Issue{
+		FilePath:    i.FilePath,
+		Line:        i.Line,
+		Column:      i.Column,
+		EndLine:     i.EndLine,
+		EndColumn:   i.EndColumn,
+		Message:     i.Message,
+		Severity:    i.Severity,
+		RuleID:      i.RuleID,
+		Category:    i.Category,
+		Suggestion:  i.Suggestion,
+		DocURL:      i.DocURL,
+		CodeSnippet: i.CodeSnippet,
+	}
+}
+
+func fromMetadata(meta rules.Metadata) RuleInfo {
+	info := RuleInfo{
+		ID:          meta.ID,
+		Name:        meta.Name,
+		Category:    meta.Category,
+		Severity:    meta.Severity,
+		Tags:        append([]string(nil), meta.Tags...),
+		Description: meta.Description,
+		DocURL:      meta.DocURL,
+	}
+	for _, lang := range meta.Languages {
+		info.Languages = append(info.Languages, Language(lang))
+	}
+	return info
+}
@@ -2,22 +2,51 @@ package reporter
 
 import (
 	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
 
 	"github.com/liujinliang/lang-checker/internal/models"
 )
 
 // GenerateReport 生成分析报告
-func GenerateReport(metrics []*models.QualityMetrics) {
+func GenerateReport(report *models.Report) {
 	fmt.Println("代码质量分析报告")
 	fmt.Println("================")
 	fmt.Println()
 
-	for _, m := range metrics {
+	var generated []*models.QualityMetrics
+	for _, m := range report.Files {
+		if m.Generated {
+			generated = append(generated, m)
+			continue
+		}
+		if m.Bytecode {
+			printBytecode(m)
+			continue
+		}
 		fmt.Printf("文件: %s\n", m.FilePath)
 		fmt.Printf("语言: %s\n", m.Language)
+		if m.Test {
+			fmt.Println("类型: 测试代码")
+		}
+		if m.Encoding != "" {
+			fmt.Printf("编码: %s（已转为UTF-8分析）\n", m.Encoding)
+		}
+		if m.Decompiler != "" {
+			fmt.Printf("来源: %s反编译的源码（行号对应反编译结果）\n", m.Decompiler)
+		}
 		fmt.Printf("总体得分: %.2f\n", m.Score)
-		fmt.Printf("圈复杂度: %d\n", m.CyclomaticComplexity)
+		fmt.Printf("圈复杂度: %d, 认知复杂度: %d\n", m.CyclomaticComplexity, m.CognitiveComplexity)
+		fmt.Printf("函数数量: %d, 最大嵌套深度: %d\n", m.FunctionCount, m.DeepNesting)
+		fmt.Printf("可维护性指数: %.2f (%s)\n", m.MaintainabilityIndex, maintainabilityLevel(m.MaintainabilityIndex))
+		fmt.Printf("Halstead: 体积%.1f, 难度%.1f, 工作量%.0f\n", m.Halstead.Volume, m.Halstead.Difficulty, m.Halstead.Effort)
 		fmt.Printf("注释率: %.2f%%\n", m.CommentRatio)
+		fmt.Printf("行数: 共%d行, 代码%d行, 注释%d行, 空行%d行\n", m.TotalLines, m.CodeLines, m.CommentLines, m.BlankLines)
+		fmt.Printf("文档覆盖率: %.2f%% (%d/%d)\n", m.DocCoverage, m.DocumentedDecls, m.PublicDecls)
+		fmt.Printf("重复行数: %d\n", m.DuplicateLines)
 		fmt.Printf("AI生成概率: %.2f%%\n", m.AIGeneratedScore)
 
 		if len(m.AIIndicators) > 0 {
@@ -27,16 +56,243 @@ func GenerateReport(metrics []*models.QualityMetrics) {
 			}
 		}
 
+		if len(m.Functions) > 0 {
+			fmt.Println("\n函数指标:")
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "函数\t位置\t行数\t参数\t圈复杂度\t认知复杂度\t嵌套深度\tHalstead体积\t可维护性")
+			for _, fn := range m.Functions {
+				name := fn.Name
+				if fn.Receiver != "" {
+					name = fn.Receiver + "." + fn.Name
+				}
+				fmt.Fprintf(w, "%s\t%d-%d\t%d\t%d\t%d\t%d\t%d\t%.1f\t%.1f\n", name, fn.StartLine, fn.EndLine, fn.Lines, fn.Parameters,
+					fn.CyclomaticComplexity, fn.CognitiveComplexity, fn.MaxNesting, fn.Halstead.Volume, fn.MaintainabilityIndex)
+			}
+			w.Flush()
+		}
+
+		printClasses(m.Classes)
+
 		if len(m.Issues) > 0 {
 			fmt.Println("\n发现的问题:")
 			for _, issue := range m.Issues {
-				fmt.Printf("- 第%d行: %s (%s)\n", issue.Line, issue.Message, issue.Severity)
+				fmt.Printf("- %s: [%s] %s (%s)\n", issueRange(issue), issue.RuleID, issue.Message, issue.Severity)
 				if issue.Suggestion != "" {
 					fmt.Printf("  建议: %s\n", issue.Suggestion)
 				}
+				if issue.DocURL != "" {
+					fmt.Printf("  规范: %s\n", issue.DocURL)
+				}
+				for _, line := range strings.Split(strings.TrimSuffix(issue.CodeSnippet, "\n"), "\n") {
+					if line != "" {
+						fmt.Printf("    %s\n", line)
+					}
+				}
 			}
 		}
 
-		fmt.Println("\n-------------------\n")
+		fmt.Print("\n-------------------\n\n")
+	}
+
+	if len(generated) > 0 {
+		fmt.Printf("生成代码（不计入得分和AI统计）: 共%d个文件\n", len(generated))
+		for _, m := range generated {
+			fmt.Printf("- %s (%s, %d行)\n", m.FilePath, m.GeneratedReason, m.TotalLines)
+		}
+		fmt.Println()
+	}
+
+	if len(report.Tests) > 0 {
+		fmt.Println("测试统计:")
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "包\t语言\t生产代码行\t测试代码行\t测试/生产\t公开API\t未测试")
+		for _, t := range report.Tests {
+			fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%.2f\t%d\t%d\n", t.Package, t.Language, t.ProductionLines, t.TestLines, t.TestRatio, t.PublicAPI, len(t.Untested))
+		}
+		w.Flush()
+		for _, t := range report.Tests {
+			if len(t.Untested) == 0 {
+				continue
+			}
+			fmt.Printf("\n%s 中未被测试引用的公开API:\n", t.Package)
+			for _, u := range t.Untested {
+				fmt.Printf("   - %s (%s:%d)\n", u.Name, u.FilePath, u.Line)
+			}
+		}
+		fmt.Println()
+	}
+
+	if len(report.Clones) > 0 {
+		fmt.Printf("重复代码: 共%d组\n", len(report.Clones))
+		for i, g := range report.Clones {
+			fmt.Printf("%d. %d行, %d个词法单元, %d处:\n", i+1, g.Lines, g.Tokens, len(g.Locations))
+			for _, loc := range g.Locations {
+				fmt.Printf("   - %s:%d-%d\n", loc.FilePath, loc.StartLine, loc.EndLine)
+			}
+		}
+		fmt.Println()
+	}
+
+	if len(report.Diagnostics) > 0 {
+		fmt.Println("诊断信息:")
+		for _, d := range report.Diagnostics {
+			fmt.Printf("- [%s] %s: %s\n", d.Kind, d.FilePath, d.Message)
+		}
+		fmt.Println()
+	}
+}
+
+// printClasses 输出类的CK指标表
+func printClasses(classes []models.ClassMetrics) {
+	if len(classes) == 0 {
+		return
+	}
+	fmt.Println("\n类指标:")
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "类\t位置\t方法\t字段\tWMC\tDIT\tNOC\tCBO\tRFC\tLCOM")
+	for _, c := range classes {
+		fmt.Fprintf(w, "%s\t%d-%d\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t%d\n", c.Name, c.StartLine, c.EndLine, c.Methods, c.Fields,
+			c.WMC, c.DIT, c.NOC, c.CBO, c.RFC, c.LCOM)
+	}
+	w.Flush()
+}
+
+// printBytecode 输出class文件的方法、类和依赖指标，圈复杂度按字节码分支估算
+func printBytecode(m *models.QualityMetrics) {
+	fmt.Printf("文件: %s\n", m.FilePath)
+	fmt.Printf("语言: %s\n", m.Language)
+	fmt.Println("类型: 字节码（无源码，不检查规则、不评分）")
+	fmt.Printf("圈复杂度（估算）: %d, 方法数量: %d\n", m.CyclomaticComplexity, m.FunctionCount)
+
+	if len(m.Functions) > 0 {
+		fmt.Println("\n方法指标:")
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "方法\t行号\t参数\t字节码大小\t圈复杂度")
+		for _, fn := range m.Functions {
+			fmt.Fprintf(w, "%s.%s\t%d-%d\t%d\t%d\t%d\n", fn.Receiver, fn.Name, fn.StartLine, fn.EndLine, fn.Parameters,
+				fn.BytecodeSize, fn.CyclomaticComplexity)
+		}
+		w.Flush()
+	}
+
+	printClasses(m.Classes)
+	for _, c := range m.Classes {
+		if len(c.Dependencies) > 0 {
+			fmt.Printf("\n%s 的依赖（%d个）:\n", c.Name, len(c.Dependencies))
+			for _, d := range c.Dependencies {
+				fmt.Printf("- %s\n", d)
+			}
+		}
+	}
+
+	fmt.Print("\n-------------------\n\n")
+}
+
+// GenerateTrendReport 对比基线报告，输出可维护性指数的变化趋势
+func GenerateTrendReport(current, baseline *models.Report) {
+	fmt.Println("可维护性趋势")
+	fmt.Println("============")
+
+	before := make(map[string]float64, len(baseline.Files))
+	for _, m := range baseline.Files {
+		before[m.FilePath] = m.MaintainabilityIndex
+	}
+
+	type change struct {
+		path          string
+		before, after float64
+	}
+	var changes []change
+	var added []string
+	for _, m := range current.Files {
+		if m.Generated || m.Bytecode {
+			continue
+		}
+		b, ok := before[m.FilePath]
+		if !ok {
+			added = append(added, m.FilePath)
+			continue
+		}
+		if math.Abs(m.MaintainabilityIndex-b) >= 0.01 {
+			changes = append(changes, change{m.FilePath, b, m.MaintainabilityIndex})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].after-changes[i].before < changes[j].after-changes[j].before
+	})
+
+	fmt.Printf("平均可维护性指数: %.2f -> %.2f\n", averageMaintainability(baseline), averageMaintainability(current))
+	if len(changes) > 0 {
+		fmt.Println("\n变化的文件:")
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "文件\t基线\t当前\t变化")
+		for _, c := range changes {
+			fmt.Fprintf(w, "%s\t%.2f\t%.2f\t%+.2f\n", c.path, c.before, c.after, c.after-c.before)
+		}
+		w.Flush()
+	}
+	if len(added) > 0 {
+		fmt.Printf("\n新增文件: %d个\n", len(added))
+	}
+	fmt.Println()
+}
+
+func averageMaintainability(report *models.Report) float64 {
+	total, n := 0.0, 0
+	for _, m := range report.Files {
+		if m.Generated || m.Bytecode {
+			continue
+		}
+		total += m.MaintainabilityIndex
+		n++
+	}
+	if n == 0 {
+		return 0
+	}
+	return total / float64(n)
+}
+
+// maintainabilityLevel 按经典阈值对可维护性指数分级
+func maintainabilityLevel(mi float64) string {
+	switch {
+	case mi >= 85:
+		return "易维护"
+	case mi >= 65:
+		return "中等"
+	default:
+		return "难维护"
+	}
+}
+
+// issueRange 格式化问题位置，如 12:5-12:20，跨行时为 12:5-14:2
+func issueRange(issue models.Issue) string {
+	start := fmt.Sprintf("%d:%d", issue.Line, issue.Column)
+	if issue.EndLine == 0 || (issue.EndLine == issue.Line && issue.EndColumn == issue.Column) {
+		return start
+	}
+	return fmt.Sprintf("%s-%d:%d", start, issue.EndLine, issue.EndColumn)
+}
+
+// GenerateDependencyReport 生成Go包依赖报告
+func GenerateDependencyReport(report *models.DependencyReport) {
+	fmt.Println("Go包依赖分析报告")
+	fmt.Println("================")
+	fmt.Printf("模块: %s\n\n", report.Module)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "包\tCa\tCe\tI\tA\tD")
+	for _, p := range report.Packages {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%.2f\t%.2f\t%.2f\n", p.ImportPath, p.Afferent, p.Efferent, p.Instability, p.Abstractness, p.Distance)
+	}
+	w.Flush()
+
+	fmt.Println()
+	if len(report.Cycles) == 0 {
+		fmt.Println("未发现导入环")
+		return
+	}
+	fmt.Println("导入环:")
+	for _, cycle := range report.Cycles {
+		fmt.Printf("- %s\n", strings.Join(cycle, ", "))
 	}
 }